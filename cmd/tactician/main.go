@@ -7,12 +7,22 @@ import (
 	"github.com/go-go-golems/glazed/pkg/help"
 	help_cmd "github.com/go-go-golems/glazed/pkg/help/cmd"
 	"github.com/go-go-golems/tactician/pkg/commands/apply"
+	"github.com/go-go-golems/tactician/pkg/commands/edge"
 	"github.com/go-go-golems/tactician/pkg/commands/goals"
 	"github.com/go-go-golems/tactician/pkg/commands/graph"
 	"github.com/go-go-golems/tactician/pkg/commands/history"
+	"github.com/go-go-golems/tactician/pkg/commands/impact"
 	"github.com/go-go-golems/tactician/pkg/commands/initcmd"
+	"github.com/go-go-golems/tactician/pkg/commands/migrate"
+	"github.com/go-go-golems/tactician/pkg/commands/next"
 	"github.com/go-go-golems/tactician/pkg/commands/node"
+	"github.com/go-go-golems/tactician/pkg/commands/plan"
+	"github.com/go-go-golems/tactician/pkg/commands/schedule"
 	"github.com/go-go-golems/tactician/pkg/commands/search"
+	"github.com/go-go-golems/tactician/pkg/commands/tactic"
+	"github.com/go-go-golems/tactician/pkg/commands/unapply"
+	"github.com/go-go-golems/tactician/pkg/commands/undo"
+	"github.com/go-go-golems/tactician/pkg/commands/validate"
 	"github.com/go-go-golems/tactician/pkg/doc"
 	"github.com/spf13/cobra"
 )
@@ -45,6 +55,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error registering node commands: %v\n", err)
 		os.Exit(1)
 	}
+	if err := edge.RegisterEdgeCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering edge commands: %v\n", err)
+		os.Exit(1)
+	}
 	if err := graph.RegisterGraphCommands(root); err != nil {
 		fmt.Fprintf(os.Stderr, "Error registering graph commands: %v\n", err)
 		os.Exit(1)
@@ -65,6 +79,43 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error registering apply commands: %v\n", err)
 		os.Exit(1)
 	}
+	if err := next.RegisterNextCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering next commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := plan.RegisterPlanCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering plan commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := impact.RegisterImpactCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering impact commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := schedule.RegisterScheduleCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering schedule commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := tactic.RegisterTacticCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering tactic commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := unapply.RegisterUnapplyCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering unapply commands: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := undo.RegisterUndoCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering undo commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := validate.RegisterValidateCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering validate commands: %v\n", err)
+		os.Exit(1)
+	}
+	if err := migrate.RegisterMigrateCommands(root); err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering migrate commands: %v\n", err)
+		os.Exit(1)
+	}
 
 	if err := root.Execute(); err != nil {
 		os.Exit(1)
@@ -2,18 +2,18 @@ package apply
 
 import (
 	"context"
-	"encoding/json"
-	"sort"
-	"strings"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/schema"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
-	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
 	"github.com/pkg/errors"
 )
 
@@ -22,12 +22,22 @@ type ApplyCommand struct {
 }
 
 type ApplySettings struct {
-	TacticID string `glazed.parameter:"tactic-id"`
-	Yes      bool   `glazed.parameter:"yes"`
-	Force    bool   `glazed.parameter:"force"`
+	TacticID       string   `glazed.parameter:"tactic-id"`
+	Yes            bool     `glazed.parameter:"yes"`
+	Force          bool     `glazed.parameter:"force"`
+	Params         []string `glazed.parameter:"param"`
+	With           []string `glazed.parameter:"with"`
+	PremiseTactics []string `glazed.parameter:"premise-tactic"`
+	DryRun         bool     `glazed.parameter:"dry-run"`
+	Mermaid        bool     `glazed.parameter:"mermaid"`
 }
 
 func NewApplyCommand() (*ApplyCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
 	tacticianSection, err := sections.NewTacticianSection()
 	if err != nil {
 		return nil, err
@@ -54,26 +64,55 @@ func NewApplyCommand() (*ApplyCommand, error) {
 				fields.WithDefault(false),
 				fields.WithShortFlag("f"),
 			),
+			fields.New("param", fields.TypeStringList,
+				fields.WithHelp("Tactic parameter as name=value (repeatable)"),
+				fields.WithShortFlag("p"),
+			),
+			fields.New("with", fields.TypeStringList,
+				fields.WithHelp("Also create this optional subtask (repeatable)"),
+			),
+			fields.New("premise-tactic", fields.TypeStringList,
+				fields.WithHelp("Type a premise placeholder after this tactic when several produce it, as output=tactic_id (repeatable)"),
+			),
+			fields.New("dry-run", fields.TypeBool,
+				fields.WithHelp("Show the nodes and edges that would be created without saving"),
+				fields.WithDefault(false),
+			),
+			fields.New("mermaid", fields.TypeBool,
+				fields.WithHelp("Output the change as a Mermaid diagram (new nodes and edges highlighted)"),
+				fields.WithDefault(false),
+			),
 		),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
 
 	cmdDef := cmds.NewCommandDefinition(
 		"apply",
 		cmds.WithShort("Apply a tactic to create new nodes"),
+		cmds.WithLong("Apply a tactic to create new nodes. Parameterized tactics take --param name=value; each distinct set of parameters creates its own subgraph. "+
+			"Optional subtasks are only created with --with <subtask-id>, and subtasks whose when condition doesn't hold are skipped; "+
+			"their dependents are wired to the skipped subtask's own dependencies. "+
+			"Premise placeholders take the type, description and tags of the tactic that produces their output; when several do, "+
+			"apply asks which one (or pass --premise-tactic output=tactic_id).\n\n"+
+			"--dry-run lists the nodes and edges that would be created (including introduced premises and existing nodes wired in) without saving. "+
+			"Without --yes, apply shows the same preview and asks for confirmation when run in a terminal."),
 		cmds.WithSchema(s),
 	)
 
 	return &ApplyCommand{CommandDefinition: cmdDef}, nil
 }
 
-var _ cmds.BareCommand = &ApplyCommand{}
+var _ cmds.GlazeCommand = &ApplyCommand{}
 
-func (c *ApplyCommand) Run(ctx context.Context, vals *values.Values) error {
+func (c *ApplyCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
 	tSettings := &sections.TacticianSettings{}
 	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
 		return errors.Wrap(err, "decode tactician settings")
@@ -84,259 +123,124 @@ func (c *ApplyCommand) Run(ctx context.Context, vals *values.Values) error {
 		return errors.Wrap(err, "decode apply settings")
 	}
 
-	if !settings.Yes {
-		return errors.New("apply requires confirmation; re-run with --yes")
-	}
-
-	st, err := store.Load(ctx, tSettings.Dir)
+	args, err := tactics.ParseParamArgs(settings.Params)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = st.Close() }()
-
-	tactic, err := st.Tactics.GetTactic(ctx, settings.TacticID)
-	if err != nil {
-		return err
-	}
-	if tactic == nil {
-		return errors.Errorf("tactic not found: %s", settings.TacticID)
-	}
-
-	allNodes, err := st.Project.GetAllNodes(ctx)
+	choices, err := tactics.ParsePremiseTacticArgs(settings.PremiseTactics)
 	if err != nil {
 		return err
 	}
 
-	deps := checkDependencies(tactic, allNodes)
-	if len(deps.Missing) > 0 && !settings.Force {
-		return errors.Errorf("cannot apply tactic: missing required dependencies (%s); use --force", strings.Join(deps.Missing, ","))
-	}
-
-	now := time.Now().UTC()
-	createdBy := "tactic:" + settings.TacticID
-
-	// Determine nodes to create
-	var nodesToCreate []*db.Node
+	if settings.DryRun {
+		st, err := store.Load(ctx, tSettings.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = st.Close() }()
 
-	// Introduce premise nodes if needed
-	for _, output := range deps.CanIntroduce {
-		id := output
-		introducedAs := "premise"
-		node := &db.Node{
-			ID:           id,
-			Type:         "document",
-			Output:       output,
-			Status:       "pending",
-			CreatedBy:    &createdBy,
-			IntroducedAs: &introducedAs,
-			CreatedAt:    now,
+		plan, err := planApply(ctx, st, settings, args, choices)
+		if err != nil {
+			return err
 		}
-		nodesToCreate = append(nodesToCreate, node)
+		return emitPlan(ctx, gp, plan, settings.Mermaid)
 	}
 
-	// Subtask nodes or single output node
-	if len(tactic.Subtasks) > 0 {
-		for _, stt := range tactic.Subtasks {
-			var data json.RawMessage
-			if stt.Data != nil {
-				b, err := json.Marshal(stt.Data)
-				if err != nil {
-					return errors.Wrap(err, "marshal subtask data")
-				}
-				data = b
-			}
-			parent := settings.TacticID
-			node := &db.Node{
-				ID:           stt.ID,
-				Type:         stt.Type,
-				Output:       stt.Output,
-				Status:       "pending",
-				CreatedBy:    &createdBy,
-				ParentTactic: &parent,
-				Data:         data,
-				CreatedAt:    now,
-			}
-			nodesToCreate = append(nodesToCreate, node)
-		}
-	} else {
-		var data json.RawMessage
-		if tactic.Data != nil {
-			b, err := json.Marshal(tactic.Data)
-			if err != nil {
-				return errors.Wrap(err, "marshal tactic data")
-			}
-			data = b
+	// Without --yes, preview and ask. The plan is recomputed under the store lock
+	// and must still match what was confirmed.
+	confirmed := ""
+	if !settings.Yes {
+		if !isTerminal(os.Stdin) {
+			return errors.New("apply requires confirmation; re-run with --yes (or --dry-run to preview)")
 		}
-		node := &db.Node{
-			ID:        tactic.Output,
-			Type:      tactic.Type,
-			Output:    tactic.Output,
-			Status:    "pending",
-			CreatedBy: &createdBy,
-			Data:      data,
-			CreatedAt: now,
+		plan, err := previewPlan(ctx, tSettings.Dir, settings, args, choices)
+		if err != nil {
+			return err
 		}
-		nodesToCreate = append(nodesToCreate, node)
-	}
-
-	// Validate none exist
-	for _, n := range nodesToCreate {
-		existing, err := st.Project.GetNode(ctx, n.ID)
+		writePreview(os.Stderr, plan)
+		ok, err := confirm(os.Stdin, os.Stderr, "Apply? [y/N] ")
 		if err != nil {
 			return err
 		}
-		if existing != nil {
-			return errors.Errorf("node already exists: %s", n.ID)
+		if !ok {
+			_, _ = fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
 		}
+		confirmed = plan.Fingerprint()
 	}
 
-	// Create nodes
-	for _, n := range nodesToCreate {
-		if err := st.Project.AddNode(ctx, n); err != nil {
+	var applied *tactics.Plan
+	err = store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		plan, err := planApply(ctx, st, settings, args, choices)
+		if err != nil {
 			return err
 		}
-	}
-
-	// Subtask dependency edges
-	for _, stt := range tactic.Subtasks {
-		for _, depID := range stt.DependsOn {
-			if err := st.Project.AddEdge(ctx, depID, stt.ID); err != nil {
-				return err
-			}
+		if confirmed != "" && plan.Fingerprint() != confirmed {
+			return errors.New("the project changed while waiting for confirmation; re-run apply")
 		}
-	}
-
-	// Edges from match dependencies to created nodes.
-	//
-	// NOTE: "Satisfied" vs "missing" is about completion; the dependency edge should still exist
-	// when the dependency node exists but is not complete (so downstream nodes show as blocked).
-	for _, depOutput := range tactic.Match {
-		source := findNodeByOutput(allNodes, depOutput)
-		if source == nil {
-			continue
-		}
-		for _, newNode := range nodesToCreate {
-			if err := st.Project.AddEdge(ctx, source.ID, newNode.ID); err != nil {
-				return err
-			}
+		if err := plan.Execute(ctx, st.Project); err != nil {
+			return err
 		}
+		applied = plan
+		st.Dirty = true
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	return emitPlan(ctx, gp, applied, settings.Mermaid)
+}
 
-	// Edges from premise dependencies to created nodes.
-	//
-	// Premises that exist (whether complete or not) should create edges, just like match.
-	// Premises that are being introduced (canIntroduce) are new nodes themselves, so skip those.
-	canIntroduceSet := make(map[string]bool)
-	for _, output := range deps.CanIntroduce {
-		canIntroduceSet[output] = true
+// previewPlan plans the application for the confirmation prompt, asking which tactic to follow
+// for premises several tactics produce (the answers are added to choices).
+func previewPlan(ctx context.Context, dir string, settings *ApplySettings, args map[string]string, choices map[string]string) (*tactics.Plan, error) {
+	st, err := store.Load(ctx, dir)
+	if err != nil {
+		return nil, err
 	}
-	for _, depOutput := range tactic.Premises {
-		// Skip if already handled as match dependency
-		if contains(tactic.Match, depOutput) {
-			continue
-		}
-		// Skip if being introduced (it's a new node itself)
-		if canIntroduceSet[depOutput] {
-			continue
-		}
-		// Create edges from existing premise nodes to new nodes
-		source := findNodeByOutput(allNodes, depOutput)
-		if source == nil {
-			continue
+	defer func() { _ = st.Close() }()
+	for {
+		plan, err := planApply(ctx, st, settings, args, choices)
+		var ambiguous *tactics.AmbiguousPremiseError
+		if !errors.As(err, &ambiguous) {
+			return plan, err
 		}
-		for _, newNode := range nodesToCreate {
-			if err := st.Project.AddEdge(ctx, source.ID, newNode.ID); err != nil {
-				return err
-			}
+		choice, err := choose(os.Stdin, os.Stderr, "Premise "+ambiguous.Output+" is produced by several tactics:", ambiguous.Tactics)
+		if err != nil {
+			return nil, err
 		}
+		choices[ambiguous.Output] = choice
 	}
-
-	details := "Applied tactic: " + settings.TacticID
-	tid := settings.TacticID
-	if err := st.Project.LogAction(ctx, "tactic_applied", &details, nil, &tid); err != nil {
-		return err
-	}
-
-	st.Dirty = true
-	return st.Save(ctx)
-}
-
-type depCheck struct {
-	Satisfied    []string
-	Missing      []string
-	CanIntroduce []string
 }
 
-func checkDependencies(tactic *db.Tactic, allNodes []*db.Node) depCheck {
-	completeOutputs := map[string]bool{}
-	existingOutputs := map[string]bool{}
-	for _, n := range allNodes {
-		existingOutputs[n.Output] = true
-		if n.Status == "complete" {
-			completeOutputs[n.Output] = true
-		}
+func planApply(ctx context.Context, st *store.State, settings *ApplySettings, args map[string]string, choices map[string]string) (*tactics.Plan, error) {
+	tmpl, err := st.Tactics.GetTactic(ctx, settings.TacticID)
+	if err != nil {
+		return nil, err
 	}
-
-	satisfiedSet := map[string]bool{}
-	missingSet := map[string]bool{}
-	canIntroduceSet := map[string]bool{}
-
-	for _, dep := range tactic.Match {
-		if completeOutputs[dep] {
-			satisfiedSet[dep] = true
-		} else {
-			missingSet[dep] = true
-		}
+	if tmpl == nil {
+		return nil, errors.Errorf("tactic not found: %s", settings.TacticID)
 	}
-
-	for _, dep := range tactic.Premises {
-		if contains(tactic.Match, dep) {
-			continue
-		}
-		if completeOutputs[dep] {
-			satisfiedSet[dep] = true
-		} else if !existingOutputs[dep] {
-			canIntroduceSet[dep] = true
-		} else {
-			missingSet[dep] = true
-		}
-	}
-
-	return depCheck{
-		Satisfied:    keys(satisfiedSet),
-		Missing:      keys(missingSet),
-		CanIntroduce: keys(canIntroduceSet),
+	inst, err := tactics.Instantiate(tmpl, args)
+	if err != nil {
+		return nil, err
 	}
-}
+	inst.With = settings.With
 
-func keys(m map[string]bool) []string {
-	var out []string
-	for k := range m {
-		out = append(out, k)
+	allNodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return nil, err
 	}
-	sort.Strings(out)
-	return out
-}
-
-func contains(list []string, s string) bool {
-	for _, x := range list {
-		if x == s {
-			return true
-		}
+	plan, err := tactics.PlanApply(inst, allNodes, st.StatusPolicy(), settings.Force, time.Now().UTC())
+	if err != nil {
+		return nil, err
 	}
-	return false
-}
-
-func findNodeByOutput(nodes []*db.Node, output string) *db.Node {
-	for _, n := range nodes {
-		if n.Output == output && n.Status == "complete" {
-			return n
-		}
+	library, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return nil, err
 	}
-	for _, n := range nodes {
-		if n.Output == output {
-			return n
-		}
+	if err := plan.InferPremises(library, choices); err != nil {
+		return nil, err
 	}
-	return nil
+	return plan, nil
 }
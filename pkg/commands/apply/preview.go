@@ -0,0 +1,180 @@
+package apply
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// emitPlan writes one row per planned node and edge, or a single Mermaid row.
+func emitPlan(ctx context.Context, gp middlewares.Processor, plan *tactics.Plan, mermaid bool) error {
+	if mermaid {
+		return gp.AddRow(ctx, types.NewRow(
+			types.MRP("instance", plan.Instance.ID),
+			types.MRP("mermaid", buildMermaidPlan(plan)),
+		))
+	}
+
+	for _, pn := range plan.Nodes {
+		row := types.NewRow(
+			types.MRP("change", "add_node"),
+			types.MRP("instance", plan.Instance.ID),
+			types.MRP("id", pn.Node.ID),
+			types.MRP("type", pn.Node.Type),
+			types.MRP("output", pn.Node.Output),
+			types.MRP("role", pn.Role),
+			types.MRP("source", ""),
+			types.MRP("target", ""),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	for _, e := range plan.Edges {
+		role := e.Reason
+		if e.Existing {
+			role += " (existing node)"
+		}
+		row := types.NewRow(
+			types.MRP("change", "add_edge"),
+			types.MRP("instance", plan.Instance.ID),
+			types.MRP("id", ""),
+			types.MRP("type", ""),
+			types.MRP("output", ""),
+			types.MRP("role", role),
+			types.MRP("source", e.Source),
+			types.MRP("target", e.Target),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	for _, sk := range plan.Skipped {
+		row := types.NewRow(
+			types.MRP("change", "skip_subtask"),
+			types.MRP("instance", plan.Instance.ID),
+			types.MRP("id", sk.ID),
+			types.MRP("type", ""),
+			types.MRP("output", ""),
+			types.MRP("role", sk.Reason),
+			types.MRP("source", ""),
+			types.MRP("target", ""),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePreview prints the plan for the interactive confirmation.
+func writePreview(w io.Writer, plan *tactics.Plan) {
+	_, _ = fmt.Fprintf(w, "Applying %s would create:\n", plan.Instance.ID)
+	for _, pn := range plan.Nodes {
+		note := pn.Node.Type + ", " + pn.Node.Output
+		if pn.Role == tactics.RolePremise {
+			note = "premise placeholder"
+			if pn.Node.ProducerTactic != nil {
+				note += " for " + *pn.Node.ProducerTactic + ", " + pn.Node.Type
+			}
+		}
+		_, _ = fmt.Fprintf(w, "  + node %s (%s)\n", pn.Node.ID, note)
+	}
+	for _, e := range plan.Edges {
+		note := e.Reason
+		if e.Existing {
+			note += ", existing node"
+		}
+		_, _ = fmt.Fprintf(w, "  + edge %s -> %s (%s)\n", e.Source, e.Target, note)
+	}
+	for _, sk := range plan.Skipped {
+		_, _ = fmt.Fprintf(w, "  - skip subtask %s (%s)\n", sk.ID, sk.Reason)
+	}
+	if len(plan.Deps.Missing) > 0 {
+		_, _ = fmt.Fprintf(w, "  ! missing dependencies (forced): %s\n", strings.Join(plan.Deps.Missing, ", "))
+	}
+}
+
+func confirm(in io.Reader, out io.Writer, prompt string) (bool, error) {
+	_, _ = fmt.Fprint(out, prompt)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, errors.Wrap(err, "read confirmation")
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// choose asks for one of options by number.
+func choose(in io.Reader, out io.Writer, prompt string, options []string) (string, error) {
+	_, _ = fmt.Fprintln(out, prompt)
+	for i, o := range options {
+		_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, o)
+	}
+	_, _ = fmt.Fprintf(out, "Choose [1-%d]: ", len(options))
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", errors.Wrap(err, "read choice")
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || i < 1 || i > len(options) {
+		return "", errors.Errorf("invalid choice %q", strings.TrimSpace(line))
+	}
+	return options[i-1], nil
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+var mermaidSanitizeRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func mermaidID(id string) string {
+	return mermaidSanitizeRe.ReplaceAllString(id, "_")
+}
+
+// buildMermaidPlan draws the new nodes (highlighted), the existing nodes they
+// get wired to, and the new edges.
+func buildMermaidPlan(plan *tactics.Plan) string {
+	sb := strings.Builder{}
+	sb.WriteString("graph TD\n")
+
+	added := map[string]bool{}
+	for _, pn := range plan.Nodes {
+		added[pn.Node.ID] = true
+		label := pn.Node.ID
+		if pn.Node.Output != pn.Node.ID {
+			label += "<br/>" + pn.Node.Output
+		}
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]:::added\n", mermaidID(pn.Node.ID), strings.ReplaceAll(label, "\"", "\\\"")))
+	}
+	seen := map[string]bool{}
+	for _, e := range plan.Edges {
+		if added[e.Source] || seen[e.Source] {
+			continue
+		}
+		seen[e.Source] = true
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]:::existing\n", mermaidID(e.Source), strings.ReplaceAll(e.Source, "\"", "\\\"")))
+	}
+	sb.WriteString("  classDef added fill:#e8f5e9,stroke:#43a047,stroke-width:2px\n")
+	sb.WriteString("  classDef existing fill:#fafafa,stroke:#9e9e9e\n")
+
+	for _, e := range plan.Edges {
+		sb.WriteString(fmt.Sprintf("  %s ==> %s\n", mermaidID(e.Source), mermaidID(e.Target)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package edge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type EdgeAddCommand struct {
+	*cmds.CommandDefinition
+}
+
+type EdgeAddSettings struct {
+	Source string `glazed.parameter:"source"`
+	Target string `glazed.parameter:"target"`
+}
+
+func NewEdgeAddCommand() (*EdgeAddCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("source", fields.TypeString,
+				fields.WithHelp("Node that blocks the target"),
+				fields.WithRequired(true),
+			),
+			fields.New("target", fields.TypeString,
+				fields.WithHelp("Node that depends on the source"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"add",
+		cmds.WithShort("Add a dependency edge (target depends on source)"),
+		cmds.WithLong("Add the edge source → target. Both nodes must exist; self-loops and edges that would close a cycle are rejected."),
+		cmds.WithSchema(s),
+	)
+
+	return &EdgeAddCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &EdgeAddCommand{}
+
+func (c *EdgeAddCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &EdgeAddSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode edge add settings")
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		if err := requireNodes(ctx, st, settings.Source, settings.Target); err != nil {
+			return err
+		}
+
+		exists, err := st.Project.HasEdge(ctx, settings.Source, settings.Target)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.Errorf("edge already exists: %s -> %s", settings.Source, settings.Target)
+		}
+		// AddEdge rejects self-loops and cycles.
+		if err := st.Project.AddEdge(ctx, settings.Source, settings.Target); err != nil {
+			return errors.Wrapf(err, "cannot add edge %s -> %s", settings.Source, settings.Target)
+		}
+
+		details := fmt.Sprintf("Added edge: %s -> %s", settings.Source, settings.Target)
+		nodeID := settings.Target
+		if err := st.Project.LogAction(ctx, "edge_added", &details, &nodeID, nil); err != nil {
+			return err
+		}
+
+		st.Dirty = true
+		return nil
+	})
+}
+
+func requireNodes(ctx context.Context, st *store.State, ids ...string) error {
+	for _, id := range ids {
+		n, err := st.Project.GetNode(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			return errors.Errorf("node not found: %s", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package edge
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type EdgeListCommand struct {
+	*cmds.CommandDefinition
+}
+
+type EdgeListSettings struct {
+	Node string `glazed.parameter:"node"`
+}
+
+func NewEdgeListCommand() (*EdgeListCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("node", fields.TypeString,
+				fields.WithHelp("Only list edges into or out of this node"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"list",
+		cmds.WithShort("List dependency edges"),
+		cmds.WithSchema(s),
+	)
+
+	return &EdgeListCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &EdgeListCommand{}
+
+func (c *EdgeListCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &EdgeListSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode edge list settings")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	if settings.Node != "" {
+		if err := requireNodes(ctx, st, settings.Node); err != nil {
+			return err
+		}
+	}
+
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].SourceNodeID == edges[j].SourceNodeID {
+			return edges[i].TargetNodeID < edges[j].TargetNodeID
+		}
+		return edges[i].SourceNodeID < edges[j].SourceNodeID
+	})
+
+	for _, e := range edges {
+		if settings.Node != "" && e.SourceNodeID != settings.Node && e.TargetNodeID != settings.Node {
+			continue
+		}
+		row := types.NewRow(
+			types.MRP("source", e.SourceNodeID),
+			types.MRP("target", e.TargetNodeID),
+		)
+		if settings.Node != "" {
+			direction := "out"
+			if e.TargetNodeID == settings.Node {
+				direction = "in"
+			}
+			row.Set("direction", direction)
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
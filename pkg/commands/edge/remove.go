@@ -0,0 +1,100 @@
+package edge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type EdgeRemoveCommand struct {
+	*cmds.CommandDefinition
+}
+
+type EdgeRemoveSettings struct {
+	Source string `glazed.parameter:"source"`
+	Target string `glazed.parameter:"target"`
+}
+
+func NewEdgeRemoveCommand() (*EdgeRemoveCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("source", fields.TypeString,
+				fields.WithHelp("Source node of the edge"),
+				fields.WithRequired(true),
+			),
+			fields.New("target", fields.TypeString,
+				fields.WithHelp("Target node of the edge"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"remove",
+		cmds.WithShort("Remove a dependency edge"),
+		cmds.WithSchema(s),
+	)
+
+	return &EdgeRemoveCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &EdgeRemoveCommand{}
+
+func (c *EdgeRemoveCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &EdgeRemoveSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode edge remove settings")
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		if err := requireNodes(ctx, st, settings.Source, settings.Target); err != nil {
+			return err
+		}
+
+		exists, err := st.Project.HasEdge(ctx, settings.Source, settings.Target)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Errorf("edge not found: %s -> %s", settings.Source, settings.Target)
+		}
+
+		if err := st.Project.RemoveEdge(ctx, settings.Source, settings.Target); err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("Removed edge: %s -> %s", settings.Source, settings.Target)
+		nodeID := settings.Target
+		if err := st.Project.LogAction(ctx, "edge_removed", &details, &nodeID, nil); err != nil {
+			return err
+		}
+
+		st.Dirty = true
+		return nil
+	})
+}
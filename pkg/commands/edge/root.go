@@ -0,0 +1,56 @@
+package edge
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterEdgeCommands(root *cobra.Command) error {
+	edgeCmd := &cobra.Command{
+		Use:   "edge",
+		Short: "Manage dependency edges between nodes",
+	}
+
+	addCmd, err := NewEdgeAddCommand()
+	if err != nil {
+		return err
+	}
+	cobraAddCmd, err := cli.BuildCobraCommandFromCommand(
+		addCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	edgeCmd.AddCommand(cobraAddCmd)
+
+	removeCmd, err := NewEdgeRemoveCommand()
+	if err != nil {
+		return err
+	}
+	cobraRemoveCmd, err := cli.BuildCobraCommandFromCommand(
+		removeCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	edgeCmd.AddCommand(cobraRemoveCmd)
+
+	listCmd, err := NewEdgeListCommand()
+	if err != nil {
+		return err
+	}
+	cobraListCmd, err := cli.BuildCobraCommandFromCommand(
+		listCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	edgeCmd.AddCommand(cobraListCmd)
+
+	root.AddCommand(edgeCmd)
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
 	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
 	"github.com/pkg/errors"
@@ -52,11 +53,17 @@ func NewGoalsCommand() (*GoalsCommand, error) {
 		return nil, err
 	}
 
-	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+	filterSection, err := sections.NewNodeFilterSection()
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection, filterSection))
 
 	cmdDef := cmds.NewCommandDefinition(
 		"goals",
 		cmds.WithShort("List all open (incomplete) goals"),
+		cmds.WithLong("List open goals, actionable first. Filter by --assignee, --label and --priority, and reorder with --sort (priority, due, estimate, id)."),
 		cmds.WithSchema(s),
 	)
 
@@ -80,6 +87,11 @@ func (c *GoalsCommand) RunIntoGlazeProcessor(
 		return errors.Wrap(err, "decode goals settings")
 	}
 
+	filter := &sections.NodeFilterSettings{}
+	if err := values.DecodeSectionInto(vals, sections.NodeFilterSlug, filter); err != nil {
+		return errors.Wrap(err, "decode node filter settings")
+	}
+
 	st, err := store.Load(ctx, tSettings.Dir)
 	if err != nil {
 		return err
@@ -90,110 +102,114 @@ func (c *GoalsCommand) RunIntoGlazeProcessor(
 	if err != nil {
 		return err
 	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return err
+	}
+	ix := dag.NewIndex(allNodes, edges, st.StatusPolicy())
 
 	var pending []*db.Node
 	for _, n := range allNodes {
-		if n.Status == "pending" {
+		if st.StatusPolicy().IsOpen(n.Status) && filter.Matches(n) {
 			pending = append(pending, n)
 		}
 	}
 
 	if settings.Mermaid {
-		mermaid, err := buildMermaidGoals(ctx, st, pending)
-		if err != nil {
-			return err
-		}
-		return gp.AddRow(ctx, types.NewRow(types.MRP("mermaid", mermaid)))
+		return gp.AddRow(ctx, types.NewRow(types.MRP("mermaid", buildMermaidGoals(ix, pending))))
 	}
 
 	if len(pending) == 0 {
+		if filter.Active() {
+			return gp.AddRow(ctx, types.NewRow(types.MRP("message", "No open goals match the filters.")))
+		}
 		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "All goals complete!")))
 	}
 
-	type nodeInfo struct {
-		n      *db.Node
-		status string
-		deps   []string
-		blocks []string
-	}
-	var infos []nodeInfo
-	for _, n := range pending {
-		status, err := computeNodeStatus(ctx, st, n.ID)
-		if err != nil {
-			return err
-		}
-		depsNodes, err := st.Project.GetDependencies(ctx, n.ID)
-		if err != nil {
+	infos := goalInfos(ix, pending, filter)
+	for _, info := range infos {
+		row := types.NewRow(
+			types.MRP("id", info.n.ID),
+			types.MRP("output", info.n.Output),
+			types.MRP("status", info.status),
+			types.MRP("dependencies", strings.Join(info.deps, ",")),
+			types.MRP("blocks", strings.Join(info.blocks, ",")),
+			types.MRP("parent_tactic", db.StringValue(info.n.ParentTactic)),
+			types.MRP("assignee", db.StringValue(info.n.Assignee)),
+			types.MRP("priority", db.StringValue(info.n.Priority)),
+			types.MRP("estimate", db.StringValue(info.n.Estimate)),
+			types.MRP("due", info.n.DueDateString()),
+			types.MRP("labels", strings.Join(info.n.Labels, ",")),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+type goalInfo struct {
+	n      *db.Node
+	status string
+	deps   []string
+	blocks []string
+}
+
+// goalInfos sorts the open nodes by the --sort key if given, then actionable first: ready, then in
+// progress, then blocked, then on hold.
+func goalInfos(ix *dag.Index, pending []*db.Node, filter *sections.NodeFilterSettings) []goalInfo {
+	var infos []goalInfo
+	for _, n := range pending {
 		var deps []string
-		for _, d := range depsNodes {
+		for _, d := range ix.Dependencies(n.ID) {
 			deps = append(deps, d.ID)
 		}
-		blocksNodes, err := st.Project.GetBlockedBy(ctx, n.ID)
-		if err != nil {
-			return err
-		}
 		var blocks []string
-		for _, b := range blocksNodes {
+		for _, b := range ix.Dependents(n.ID) {
 			blocks = append(blocks, b.ID)
 		}
 
-		infos = append(infos, nodeInfo{n: n, status: status, deps: deps, blocks: blocks})
+		infos = append(infos, goalInfo{n: n, status: ix.Status(n.ID), deps: deps, blocks: blocks})
 	}
 
-	// Ready first.
 	sort.SliceStable(infos, func(i, j int) bool {
-		if infos[i].status == "ready" && infos[j].status != "ready" {
-			return true
+		if c := filter.Compare(infos[i].n, infos[j].n); c != 0 {
+			return c < 0
 		}
-		if infos[i].status != "ready" && infos[j].status == "ready" {
-			return false
+		ri, rj := goalStatusRank[infos[i].status], goalStatusRank[infos[j].status]
+		if ri != rj {
+			return ri < rj
 		}
 		return infos[i].n.ID < infos[j].n.ID
 	})
+	return infos
+}
 
-	for _, info := range infos {
-		row := types.NewRow(
-			types.MRP("id", info.n.ID),
-			types.MRP("output", info.n.Output),
-			types.MRP("status", info.status),
-			types.MRP("dependencies", strings.Join(info.deps, ",")),
-			types.MRP("blocks", strings.Join(info.blocks, ",")),
-			types.MRP("parent_tactic", info.n.ParentTactic),
-		)
-		if err := gp.AddRow(ctx, row); err != nil {
-			return err
-		}
-	}
+var goalStatusRank = map[string]int{
+	db.DerivedReady:     0,
+	db.StatusInProgress: 1,
+	db.DerivedBlocked:   2,
+	db.StatusOnHold:     3,
+}
 
-	return nil
+// mermaidClassDefs styles nodes by derived status (node definitions end in `:::<status>`).
+var mermaidClassDefs = []string{
+	"classDef ready fill:#e3f2fd,stroke:#1e88e5",
+	"classDef in_progress fill:#fff8e1,stroke:#f9a825",
+	"classDef blocked fill:#ffebee,stroke:#e53935",
+	"classDef on_hold fill:#eeeeee,stroke:#757575,stroke-dasharray: 5 5",
+	"classDef complete fill:#e8f5e9,stroke:#43a047",
+	"classDef cancelled fill:#fafafa,stroke:#bdbdbd,color:#9e9e9e",
+	"classDef wont_do fill:#fafafa,stroke:#bdbdbd,color:#9e9e9e",
 }
 
-func computeNodeStatus(ctx context.Context, st *store.State, nodeID string) (string, error) {
-	n, err := st.Project.GetNode(ctx, nodeID)
-	if err != nil {
-		return "", err
-	}
-	if n == nil {
-		return "", errors.Errorf("node not found: %s", nodeID)
-	}
-	if n.Status == "complete" {
-		return "complete", nil
-	}
-	deps, err := st.Project.GetDependencies(ctx, nodeID)
-	if err != nil {
-		return "", err
-	}
-	if len(deps) == 0 {
-		return "ready", nil
-	}
-	for _, d := range deps {
-		if d.Status != "complete" {
-			return "blocked", nil
-		}
+func writeMermaidClassDefs(sb *strings.Builder) {
+	for _, def := range mermaidClassDefs {
+		sb.WriteString("  ")
+		sb.WriteString(def)
+		sb.WriteString("\n")
 	}
-	return "ready", nil
 }
 
 var mermaidSanitizeRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
@@ -227,35 +243,31 @@ func mermaidLabel(id, output, typ, status string) string {
 	return strings.Join(parts, "<br/>")
 }
 
-func buildMermaidGoals(ctx context.Context, st *store.State, pending []*db.Node) (string, error) {
+func buildMermaidGoals(ix *dag.Index, pending []*db.Node) string {
 	sb := strings.Builder{}
 	sb.WriteString("graph TD\n")
 	if len(pending) == 0 {
 		sb.WriteString("  empty[\"All goals complete!\"]\n")
-		return sb.String(), nil
+		return sb.String()
 	}
 
 	// Nodes
 	for _, n := range pending {
-		status, err := computeNodeStatus(ctx, st, n.ID)
-		if err != nil {
-			return "", err
-		}
+		status := ix.Status(n.ID)
 		sb.WriteString("  ")
 		sb.WriteString(mermaidID(n.ID))
 		sb.WriteString("[\"")
 		label := mermaidLabel(n.ID, n.Output, n.Type, status)
 		sb.WriteString(strings.ReplaceAll(label, "\"", "\\\""))
-		sb.WriteString("\"]\n")
+		sb.WriteString("\"]:::")
+		sb.WriteString(status)
+		sb.WriteString("\n")
 	}
+	writeMermaidClassDefs(&sb)
 
 	// Edges from deps.
 	for _, n := range pending {
-		deps, err := st.Project.GetDependencies(ctx, n.ID)
-		if err != nil {
-			return "", err
-		}
-		for _, d := range deps {
+		for _, d := range ix.Dependencies(n.ID) {
 			sb.WriteString("  ")
 			sb.WriteString(mermaidID(d.ID))
 			sb.WriteString(" --> ")
@@ -264,5 +276,5 @@ func buildMermaidGoals(ctx context.Context, st *store.State, pending []*db.Node)
 		}
 	}
 
-	return sb.String(), nil
+	return sb.String()
 }
@@ -0,0 +1,30 @@
+package goals
+
+import (
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/db/dbtest"
+)
+
+func BenchmarkGoals10k(b *testing.B) {
+	nodes, edges := dbtest.SyntheticGraph(10000)
+	policy := db.DefaultStatusPolicy()
+	filter := &sections.NodeFilterSettings{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix := dag.NewIndex(nodes, edges, policy)
+		var pending []*db.Node
+		for _, n := range nodes {
+			if policy.IsOpen(n.Status) && filter.Matches(n) {
+				pending = append(pending, n)
+			}
+		}
+		if infos := goalInfos(ix, pending, filter); len(infos) != len(pending) {
+			b.Fatalf("expected %d goals, got %d", len(pending), len(infos))
+		}
+		_ = buildMermaidGoals(ix, pending)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
 	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
 	"github.com/pkg/errors"
@@ -58,11 +59,17 @@ func NewGraphCommand() (*GraphCommand, error) {
 		return nil, err
 	}
 
-	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+	filterSection, err := sections.NewNodeFilterSection()
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection, filterSection))
 
 	cmdDef := cmds.NewCommandDefinition(
 		"graph",
 		cmds.WithShort("Display the project dependency graph"),
+		cmds.WithLong("Walk the dependency graph from the root goal. --assignee, --label and --priority only emit matching nodes (the walk still passes through the others); --sort orders siblings."),
 		cmds.WithSchema(s),
 	)
 
@@ -86,6 +93,11 @@ func (c *GraphCommand) RunIntoGlazeProcessor(
 		return errors.Wrap(err, "decode graph settings")
 	}
 
+	filter := &sections.NodeFilterSettings{}
+	if err := values.DecodeSectionInto(vals, sections.NodeFilterSlug, filter); err != nil {
+		return errors.Wrap(err, "decode node filter settings")
+	}
+
 	st, err := store.Load(ctx, tSettings.Dir)
 	if err != nil {
 		return err
@@ -105,12 +117,11 @@ func (c *GraphCommand) RunIntoGlazeProcessor(
 		return err
 	}
 
+	ix := dag.NewIndex(allNodes, edges, st.StatusPolicy())
+
 	if settings.Mermaid {
-		mermaid, err := buildMermaidGraph(ctx, st, allNodes, edges)
-		if err != nil {
-			return err
-		}
-		row := types.NewRow(types.MRP("project", meta["name"]), types.MRP("mermaid", mermaid))
+		nodes, edges := filterGraph(allNodes, edges, filter)
+		row := types.NewRow(types.MRP("project", meta["name"]), types.MRP("mermaid", buildMermaidGraph(ix, nodes, edges)))
 		return gp.AddRow(ctx, row)
 	}
 
@@ -155,7 +166,30 @@ func (c *GraphCommand) RunIntoGlazeProcessor(
 		children[e.SourceNodeID] = append(children[e.SourceNodeID], e.TargetNodeID)
 	}
 	for k := range children {
-		sort.Strings(children[k])
+		kids := children[k]
+		sort.Slice(kids, func(i, j int) bool {
+			if c := filter.Compare(allNodes[byID[kids[i]]], allNodes[byID[kids[j]]]); c != 0 {
+				return c < 0
+			}
+			return kids[i] < kids[j]
+		})
+	}
+
+	addRow := func(n *db.Node, depth int) error {
+		row := types.NewRow(
+			types.MRP("project", meta["name"]),
+			types.MRP("root", rootID),
+			types.MRP("depth", depth),
+			types.MRP("id", n.ID),
+			types.MRP("type", n.Type),
+			types.MRP("output", n.Output),
+			types.MRP("status", ix.Status(n.ID)),
+			types.MRP("assignee", db.StringValue(n.Assignee)),
+			types.MRP("priority", db.StringValue(n.Priority)),
+			types.MRP("due", n.DueDateString()),
+			types.MRP("labels", strings.Join(n.Labels, ",")),
+		)
+		return gp.AddRow(ctx, row)
 	}
 
 	visited := map[string]bool{}
@@ -167,22 +201,10 @@ func (c *GraphCommand) RunIntoGlazeProcessor(
 		visited[id] = true
 
 		n := allNodes[byID[id]]
-		status, err := computeNodeStatus(ctx, st, n.ID)
-		if err != nil {
-			return err
-		}
-
-		row := types.NewRow(
-			types.MRP("project", meta["name"]),
-			types.MRP("root", rootID),
-			types.MRP("depth", depth),
-			types.MRP("id", n.ID),
-			types.MRP("type", n.Type),
-			types.MRP("output", n.Output),
-			types.MRP("status", status),
-		)
-		if err := gp.AddRow(ctx, row); err != nil {
-			return err
+		if filter.Matches(n) {
+			if err := addRow(n, depth); err != nil {
+				return err
+			}
 		}
 
 		for _, child := range children[id] {
@@ -196,30 +218,23 @@ func (c *GraphCommand) RunIntoGlazeProcessor(
 	return walk(rootID, 0)
 }
 
-func computeNodeStatus(ctx context.Context, st *store.State, nodeID string) (string, error) {
-	n, err := st.Project.GetNode(ctx, nodeID)
-	if err != nil {
-		return "", err
-	}
-	if n == nil {
-		return "", errors.Errorf("node not found: %s", nodeID)
-	}
-	if n.Status == "complete" {
-		return "complete", nil
-	}
-	deps, err := st.Project.GetDependencies(ctx, nodeID)
-	if err != nil {
-		return "", err
-	}
-	if len(deps) == 0 {
-		return "ready", nil
-	}
-	for _, d := range deps {
-		if d.Status != "complete" {
-			return "blocked", nil
-		}
+// mermaidClassDefs styles nodes by derived status (node definitions end in `:::<status>`).
+var mermaidClassDefs = []string{
+	"classDef ready fill:#e3f2fd,stroke:#1e88e5",
+	"classDef in_progress fill:#fff8e1,stroke:#f9a825",
+	"classDef blocked fill:#ffebee,stroke:#e53935",
+	"classDef on_hold fill:#eeeeee,stroke:#757575,stroke-dasharray: 5 5",
+	"classDef complete fill:#e8f5e9,stroke:#43a047",
+	"classDef cancelled fill:#fafafa,stroke:#bdbdbd,color:#9e9e9e",
+	"classDef wont_do fill:#fafafa,stroke:#bdbdbd,color:#9e9e9e",
+}
+
+func writeMermaidClassDefs(sb *strings.Builder) {
+	for _, def := range mermaidClassDefs {
+		sb.WriteString("  ")
+		sb.WriteString(def)
+		sb.WriteString("\n")
 	}
-	return "ready", nil
 }
 
 var mermaidSanitizeRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
@@ -254,21 +269,43 @@ func mermaidLabel(id, output, typ, status string) string {
 	return strings.Join(parts, "<br/>")
 }
 
-func buildMermaidGraph(ctx context.Context, st *store.State, nodes []*db.Node, edges []db.Edge) (string, error) {
+// filterGraph keeps the nodes matching the filter and the edges between them.
+func filterGraph(nodes []*db.Node, edges []db.Edge, filter *sections.NodeFilterSettings) ([]*db.Node, []db.Edge) {
+	if !filter.Active() {
+		return nodes, edges
+	}
+	keep := map[string]bool{}
+	var outNodes []*db.Node
+	for _, n := range nodes {
+		if filter.Matches(n) {
+			keep[n.ID] = true
+			outNodes = append(outNodes, n)
+		}
+	}
+	var outEdges []db.Edge
+	for _, e := range edges {
+		if keep[e.SourceNodeID] && keep[e.TargetNodeID] {
+			outEdges = append(outEdges, e)
+		}
+	}
+	return outNodes, outEdges
+}
+
+func buildMermaidGraph(ix *dag.Index, nodes []*db.Node, edges []db.Edge) string {
 	sb := strings.Builder{}
 	sb.WriteString("graph TD\n")
 	for _, n := range nodes {
-		status, err := computeNodeStatus(ctx, st, n.ID)
-		if err != nil {
-			return "", err
-		}
+		status := ix.Status(n.ID)
 		sb.WriteString("  ")
 		sb.WriteString(mermaidID(n.ID))
 		sb.WriteString("[\"")
 		label := mermaidLabel(n.ID, n.Output, n.Type, status)
 		sb.WriteString(strings.ReplaceAll(label, "\"", "\\\""))
-		sb.WriteString("\"]\n")
+		sb.WriteString("\"]:::")
+		sb.WriteString(status)
+		sb.WriteString("\n")
 	}
+	writeMermaidClassDefs(&sb)
 	for _, e := range edges {
 		sb.WriteString("  ")
 		sb.WriteString(mermaidID(e.SourceNodeID))
@@ -276,5 +313,5 @@ func buildMermaidGraph(ctx context.Context, st *store.State, nodes []*db.Node, e
 		sb.WriteString(mermaidID(e.TargetNodeID))
 		sb.WriteString("\n")
 	}
-	return sb.String(), nil
+	return sb.String()
 }
@@ -147,6 +147,7 @@ func (c *HistoryCommand) RunIntoGlazeProcessor(
 			types.MRP("details", details),
 			types.MRP("node_id", nodeID),
 			types.MRP("tactic_id", tacticID),
+			types.MRP("undone", l.Undone),
 		)
 		if err := gp.AddRow(ctx, row); err != nil {
 			return err
@@ -0,0 +1,288 @@
+package impact
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+)
+
+type ImpactCommand struct {
+	*cmds.CommandDefinition
+}
+
+type ImpactSettings struct {
+	NodeIDs         []string `glazed.parameter:"node-ids"`
+	Requires        string   `glazed.parameter:"requires"`
+	HoursPerPoint   float64  `glazed.parameter:"hours-per-point"`
+	DefaultEstimate string   `glazed.parameter:"default-estimate"`
+}
+
+func NewImpactCommand() (*ImpactCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("node-ids", fields.TypeStringList,
+				fields.WithHelp("Node ID(s) to pretend are complete"),
+			),
+		),
+		schema.WithFields(
+			fields.New("requires", fields.TypeString,
+				fields.WithHelp("Instead, list every incomplete node this node still needs, directly or transitively"),
+			),
+			fields.New("hours-per-point", fields.TypeFloat,
+				fields.WithHelp("Hours per story point, for the critical path"),
+				fields.WithDefault(8.0),
+			),
+			fields.New("default-estimate", fields.TypeString,
+				fields.WithHelp("Estimate of nodes that have none, for the critical path"),
+				fields.WithDefault("1d"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"impact",
+		cmds.WithShort("Show what completing nodes would unlock"),
+		cmds.WithLong("Pretend the given nodes are complete (nothing is saved) and report what changes:\n"+
+			"- unblocks: nodes that go from blocked to ready (or in progress)\n"+
+			"- downstream: every open node that depends on them, directly or transitively, with its status afterwards\n"+
+			"- tactic: tactics that become ready in search\n"+
+			"- critical_path: the remaining critical path length before and after (see schedule)\n\n"+
+			"With --requires <node>, list instead the incomplete nodes that node still needs, directly or transitively, "+
+			"in the order to do them."),
+		cmds.WithSchema(s),
+	)
+
+	return &ImpactCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &ImpactCommand{}
+
+func (c *ImpactCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &ImpactSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode impact settings")
+	}
+	requires := strings.TrimSpace(settings.Requires)
+	switch {
+	case requires != "" && len(settings.NodeIDs) > 0:
+		return errors.New("give either node IDs or --requires, not both")
+	case requires == "" && len(settings.NodeIDs) == 0:
+		return errors.New("give the node IDs to complete, or --requires <node>")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return err
+	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return err
+	}
+	byID := map[string]*db.Node{}
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	policy := st.StatusPolicy()
+
+	if requires != "" {
+		if byID[requires] == nil {
+			return errors.Errorf("node not found: %s", requires)
+		}
+		return emitRequires(ctx, gp, requires, nodes, edges, byID, policy)
+	}
+
+	for _, id := range settings.NodeIDs {
+		n := byID[id]
+		if n == nil {
+			return errors.Errorf("node not found: %s", id)
+		}
+		if !policy.IsOpen(n.Status) {
+			return errors.Errorf("node %s is already %s", id, n.Status)
+		}
+	}
+
+	defaultHours, _, err := dag.NodeHours(&db.Node{ID: "--default-estimate", Estimate: &settings.DefaultEstimate}, settings.HoursPerPoint)
+	if err != nil {
+		return err
+	}
+	opts := dag.ScheduleOptions{HoursPerPoint: settings.HoursPerPoint, DefaultHours: defaultHours, Policy: policy}
+
+	after := dag.WithStatus(nodes, settings.NodeIDs, db.StatusComplete)
+	statusBefore := dag.DeriveStatuses(nodes, edges, policy)
+	statusAfter := dag.DeriveStatuses(after, edges, policy)
+
+	completing := map[string]bool{}
+	for _, id := range settings.NodeIDs {
+		completing[id] = true
+	}
+	var downstream []string
+	for _, id := range dag.Descendants(settings.NodeIDs, edges) {
+		if !completing[id] && policy.IsOpen(byID[id].Status) {
+			downstream = append(downstream, id)
+		}
+	}
+	downstream = dag.DependencyOrder(downstream, edges)
+
+	for _, id := range downstream {
+		before, now := statusBefore[id], statusAfter[id]
+		if before != db.DerivedBlocked || now == db.DerivedBlocked {
+			continue
+		}
+		if err := gp.AddRow(ctx, nodeRow("unblocks", byID[id], before, now)); err != nil {
+			return err
+		}
+	}
+	for _, id := range downstream {
+		if err := gp.AddRow(ctx, nodeRow("downstream", byID[id], statusBefore[id], statusAfter[id])); err != nil {
+			return err
+		}
+	}
+
+	library, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return err
+	}
+	checkBefore, checkAfter := tactics.NewDepChecker(nodes, policy), tactics.NewDepChecker(after, policy)
+	for _, t := range library {
+		was := checkBefore.Check(t)
+		if was.Ready || !checkAfter.Check(t).Ready {
+			continue
+		}
+		row := types.NewRow(
+			types.MRP("effect", "tactic"),
+			types.MRP("id", t.ID),
+			types.MRP("type", t.Type),
+			types.MRP("output", t.Output),
+			types.MRP("before", "missing "+strings.Join(was.Missing, ", ")),
+			types.MRP("after", "ready"),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	schedBefore, err := dag.ComputeSchedule(nodes, edges, opts)
+	if err != nil {
+		return err
+	}
+	for _, w := range schedBefore.Warnings {
+		_, _ = fmt.Fprintf(store.WarningOutput, "warning: %s\n", w)
+	}
+	schedAfter, err := dag.ComputeSchedule(after, edges, opts)
+	if err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("effect", "critical_path"),
+		types.MRP("id", ""),
+		types.MRP("type", ""),
+		types.MRP("output", ""),
+		types.MRP("before", hours(schedBefore.Duration)),
+		types.MRP("after", fmt.Sprintf("%s (%s)", hours(schedAfter.Duration), signedHours(schedAfter.Duration-schedBefore.Duration))),
+	))
+}
+
+// emitRequires lists the open prerequisites of id in dependency order.
+func emitRequires(
+	ctx context.Context,
+	gp middlewares.Processor,
+	id string,
+	nodes []*db.Node,
+	edges []db.Edge,
+	byID map[string]*db.Node,
+	policy *db.StatusPolicy,
+) error {
+	statuses := dag.DeriveStatuses(nodes, edges, policy)
+	var open []string
+	for _, a := range dag.Ancestors([]string{id}, edges) {
+		if a != id && policy.IsOpen(byID[a].Status) {
+			open = append(open, a)
+		}
+	}
+	if len(open) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", fmt.Sprintf("%s has no incomplete prerequisites.", id))))
+	}
+	for _, a := range dag.DependencyOrder(open, edges) {
+		n := byID[a]
+		row := types.NewRow(
+			types.MRP("id", n.ID),
+			types.MRP("type", n.Type),
+			types.MRP("output", n.Output),
+			types.MRP("status", statuses[a]),
+			types.MRP("assignee", db.StringValue(n.Assignee)),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nodeRow(effect string, n *db.Node, before, after string) types.Row {
+	return types.NewRow(
+		types.MRP("effect", effect),
+		types.MRP("id", n.ID),
+		types.MRP("type", n.Type),
+		types.MRP("output", n.Output),
+		types.MRP("before", before),
+		types.MRP("after", after),
+	)
+}
+
+func hours(h float64) string {
+	return fmt.Sprintf("%gh", math.Round(h*100)/100)
+}
+
+func signedHours(h float64) string {
+	if h > 0 {
+		return "+" + hours(h)
+	}
+	return hours(h)
+}
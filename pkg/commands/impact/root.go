@@ -0,0 +1,25 @@
+package impact
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterImpactCommands(root *cobra.Command) error {
+	impactCmd, err := NewImpactCommand()
+	if err != nil {
+		return err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		impactCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+
+	root.AddCommand(cobraCmd)
+	return nil
+}
@@ -4,34 +4,53 @@ import (
 	"context"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
 	"github.com/go-go-golems/glazed/pkg/cmds/schema"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
-	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/defaults"
 	"github.com/go-go-golems/tactician/pkg/store"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 )
 
 type InitCommand struct {
 	*cmds.CommandDefinition
 }
 
+type InitSettings struct {
+	Seed bool `glazed.parameter:"seed"`
+}
+
 func NewInitCommand() (*InitCommand, error) {
 	tacticianSection, err := sections.NewTacticianSection()
 	if err != nil {
 		return nil, err
 	}
 
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("seed", fields.TypeBool,
+				fields.WithHelp("Copy the builtin tactics into .tactician/tactics (to edit or pin them per project)"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	s := schema.NewSchema(
-		schema.WithSections(tacticianSection),
+		schema.WithSections(tacticianSection, defaultSection),
 	)
 
 	cmdDef := cmds.NewCommandDefinition(
 		"init",
 		cmds.WithShort("Initialize a new Tactician project"),
-		cmds.WithLong("Creates .tactician/ directory and initializes project and tactics databases"),
+		cmds.WithLong("Creates .tactician/ directory and initializes project and tactics databases. "+
+			"The builtin tactics are available without copying them; --seed copies them into .tactician/tactics."),
 		cmds.WithSchema(s),
 	)
 
@@ -50,12 +69,22 @@ func (c *InitCommand) Run(ctx context.Context, vals *values.Values) error {
 		return err
 	}
 
-	var tactics []*db.Tactic
-	if err := yaml.Unmarshal(defaults.DefaultTacticsYAML, &tactics); err != nil {
-		return errors.Wrap(err, "parse embedded default tactics")
+	initSettings := &InitSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, initSettings); err != nil {
+		return errors.Wrap(err, "decode init settings")
 	}
-	if err := store.SeedTacticsIfMissing(settings.Dir, tactics); err != nil {
-		return err
+	if initSettings.Seed {
+		tactics, err := defaults.Tactics()
+		if err != nil {
+			return err
+		}
+		err = store.Update(ctx, settings.Dir, func(ctx context.Context, st *store.State) error {
+			_, err := st.SeedTactics(ctx, tactics)
+			return err
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	// TODO(manuel): Optionally set project metadata (name/root_goal).
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type MigrateCommand struct {
+	*cmds.CommandDefinition
+}
+
+type MigrateSettings struct {
+	DryRun bool `glazed.parameter:"dry-run"`
+}
+
+func NewMigrateCommand() (*MigrateCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("dry-run", fields.TypeBool,
+				fields.WithHelp("Show the rewritten files without writing them"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"migrate",
+		cmds.WithShort("Upgrade .tactician/ files to the current schema version"),
+		cmds.WithLong("Rewrites project.yaml, action-log.yaml and tactic files that use an older layout (including the JS-era project.yaml). Use --dry-run to preview."),
+		cmds.WithSchema(s),
+	)
+
+	return &MigrateCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &MigrateCommand{}
+
+func (c *MigrateCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &MigrateSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode migrate settings")
+	}
+
+	results, err := store.Migrate(ctx, tSettings.Dir, settings.DryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		status := "up-to-date"
+		if len(r.Steps) > 0 {
+			status = "migrated"
+			if settings.DryRun {
+				status = "would-migrate"
+			}
+		}
+		row := types.NewRow(
+			types.MRP("file", r.File),
+			types.MRP("status", status),
+			types.MRP("from_version", r.FromVersion),
+			types.MRP("to_version", r.ToVersion),
+			types.MRP("migrations", strings.Join(r.Steps, "; ")),
+		)
+		if settings.DryRun {
+			row.Set("content", r.Content)
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
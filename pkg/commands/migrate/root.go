@@ -0,0 +1,25 @@
+package migrate
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterMigrateCommands(root *cobra.Command) error {
+	migrateCmd, err := NewMigrateCommand()
+	if err != nil {
+		return err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		migrateCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+
+	root.AddCommand(cobraCmd)
+	return nil
+}
@@ -0,0 +1,159 @@
+package next
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type NextCommand struct {
+	*cmds.CommandDefinition
+}
+
+type NextSettings struct {
+	Limit int    `glazed.parameter:"limit"`
+	Goal  string `glazed.parameter:"goal"`
+}
+
+func NewNextCommand() (*NextCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("limit", fields.TypeInteger,
+				fields.WithHelp("Maximum number of recommendations (0: all)"),
+				fields.WithDefault(5),
+			),
+			fields.New("goal", fields.TypeString,
+				fields.WithHelp("Goal node to align with (default: the project's root_goal)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"next",
+		cmds.WithShort("Recommend what to do next, among ready nodes and ready tactics"),
+		cmds.WithLong("Rank the nodes that can be worked on now (ready or in progress) together with the tactics that are ready "+
+			"to apply (and whose output isn't in the project yet), best first. The score adds up:\n"+
+			"- how many nodes (or tactics) it unblocks right away, and how many open nodes depend on it\n"+
+			"- being on the critical path (see schedule)\n"+
+			"- priority, and a due date that is past or close\n"+
+			"- being the root goal or needed by it (--goal overrides root_goal)\n"+
+			"- having become ready recently, and being in progress already\n\n"+
+			"`why` explains each score and `command` is the command to run. --json is short for --output json."),
+		cmds.WithSchema(s),
+	)
+
+	return &NextCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &NextCommand{}
+
+func (c *NextCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &NextSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode next settings")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return err
+	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return err
+	}
+	library, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return err
+	}
+
+	goal := strings.TrimSpace(settings.Goal)
+	if goal == "" {
+		meta, err := st.Project.GetProjectMeta(ctx)
+		if err != nil {
+			return err
+		}
+		goal = strings.TrimSpace(meta["root_goal"])
+	} else if n, err := st.Project.GetNode(ctx, goal); err != nil {
+		return err
+	} else if n == nil {
+		return errors.Errorf("node not found: %s", goal)
+	}
+
+	p, err := newProject(nodes, edges, st.StatusPolicy(), goal, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, w := range p.schedule.Warnings {
+		_, _ = fmt.Fprintf(store.WarningOutput, "warning: %s\n", w)
+	}
+	candidates := append(p.rankNodes(), p.rankTactics(library)...)
+	sortCandidates(candidates)
+	if settings.Limit > 0 && len(candidates) > settings.Limit {
+		candidates = candidates[:settings.Limit]
+	}
+
+	if len(candidates) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "Nothing is ready: no ready nodes and no ready tactics with a new output.")))
+	}
+	for i, c := range candidates {
+		row := types.NewRow(
+			types.MRP("rank", i+1),
+			types.MRP("kind", c.Kind),
+			types.MRP("id", c.ID),
+			types.MRP("type", c.Type),
+			types.MRP("output", c.Output),
+			types.MRP("status", c.Status),
+			types.MRP("score", c.Score()),
+			types.MRP("why", c.Explain()),
+			types.MRP("command", c.Command),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
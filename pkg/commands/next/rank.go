@@ -0,0 +1,298 @@
+package next
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+)
+
+// Score weights. A node or tactic's score is the sum of its factors; each factor is reported
+// with its points so the ranking can be explained.
+const (
+	pointsPerUnblocked  = 10 // per node it makes ready right away
+	pointsPerEnabled    = 5  // per tactic it makes ready (tactics are options, not planned work)
+	pointsPerDownstream = 2  // per open node depending on it, transitively
+	pointsCritical      = 25 // on the critical path
+	pointsOverdue       = 30
+	pointsDueSoon       = 20 // due within 3 days
+	pointsDueThisWeek   = 10 // due within 7 days
+	pointsRootGoal      = 20 // is the root goal
+	pointsGoalAncestor  = 10 // is needed by the root goal
+	pointsRecentDay     = 5  // created or unblocked in the last day
+	pointsRecentWeek    = 2  // ... in the last week
+	pointsInProgress    = 15 // already started: finish before starting more
+)
+
+// priorityPoints rewards higher priorities; unset is between medium and low.
+var priorityPoints = map[string]int{
+	db.PriorityCritical: 30,
+	db.PriorityHigh:     20,
+	db.PriorityMedium:   10,
+	db.PriorityLow:      0,
+}
+
+const pointsNoPriority = 5
+
+type factor struct {
+	Points int
+	Why    string
+}
+
+type candidate struct {
+	Kind    string // "node" or "tactic"
+	ID      string
+	Type    string
+	Output  string
+	Status  string
+	Command string
+	Factors []factor
+}
+
+func (c *candidate) add(points int, format string, args ...interface{}) {
+	if points == 0 {
+		return
+	}
+	c.Factors = append(c.Factors, factor{Points: points, Why: fmt.Sprintf(format, args...)})
+}
+
+func (c *candidate) Score() int {
+	total := 0
+	for _, f := range c.Factors {
+		total += f.Points
+	}
+	return total
+}
+
+// Explain lists the factors, largest first, e.g. "unblocks 2 nodes (+20), priority high (+20)".
+func (c *candidate) Explain() string {
+	factors := append([]factor{}, c.Factors...)
+	sort.SliceStable(factors, func(i, j int) bool { return factors[i].Points > factors[j].Points })
+	var parts []string
+	for _, f := range factors {
+		parts = append(parts, fmt.Sprintf("%s (%+d)", f.Why, f.Points))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// project is what ranking needs to know about the project.
+type project struct {
+	nodes    []*db.Node
+	ix       *dag.Index
+	policy   *db.StatusPolicy
+	schedule *dag.Schedule
+	rootGoal string
+	// goalAncestors are the open nodes the root goal needs.
+	goalAncestors map[string]bool
+	now           time.Time
+}
+
+func newProject(nodes []*db.Node, edges []db.Edge, policy *db.StatusPolicy, rootGoal string, now time.Time) (*project, error) {
+	p := &project{
+		nodes:         nodes,
+		ix:            dag.NewIndex(nodes, edges, policy),
+		policy:        policy,
+		goalAncestors: map[string]bool{},
+		now:           now,
+	}
+	if p.ix.Node(rootGoal) != nil {
+		p.rootGoal = rootGoal
+		for _, id := range dag.Ancestors([]string{rootGoal}, edges) {
+			p.goalAncestors[id] = true
+		}
+	}
+	var err error
+	p.schedule, err = dag.ComputeSchedule(nodes, edges, dag.ScheduleOptions{HoursPerPoint: 8, DefaultHours: 8, Policy: policy})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// rankNodes scores the nodes that can be worked on now: ready and in progress ones.
+func (p *project) rankNodes() []*candidate {
+	critical := map[string]bool{}
+	for _, sn := range p.schedule.Nodes {
+		if sn.Critical {
+			critical[sn.Node.ID] = true
+		}
+	}
+
+	var ret []*candidate
+	for _, n := range p.nodes {
+		status := p.ix.Status(n.ID)
+		if status != db.DerivedReady && status != db.StatusInProgress {
+			continue
+		}
+		c := &candidate{Kind: "node", ID: n.ID, Type: n.Type, Output: n.Output, Status: status}
+		if status == db.StatusInProgress {
+			c.Command = fmt.Sprintf("tactician node edit %s --status complete", n.ID)
+			c.add(pointsInProgress, "in progress")
+		} else {
+			c.Command = fmt.Sprintf("tactician node edit %s --status in_progress", n.ID)
+		}
+
+		// A blocked dependent is unblocked when n is its only blocker.
+		unblocked, downstream := 0, 0
+		for _, d := range p.ix.Dependents(n.ID) {
+			if blockers := p.ix.Blockers(d.ID); p.ix.Status(d.ID) == db.DerivedBlocked && len(blockers) == 1 && blockers[0].ID == n.ID {
+				unblocked++
+			}
+		}
+		for _, id := range p.ix.Descendants(n.ID) {
+			if p.policy.IsOpen(p.ix.Node(id).Status) {
+				downstream++
+			}
+		}
+		c.add(unblocked*pointsPerUnblocked, "unblocks %s", plural(unblocked, "node"))
+		c.add(downstream*pointsPerDownstream, "%s downstream", plural(downstream, "node"))
+		if critical[n.ID] && len(p.schedule.Nodes) > 1 {
+			c.add(pointsCritical, "on the critical path")
+		}
+
+		if n.Priority != nil {
+			c.add(priorityPoints[*n.Priority], "priority %s", *n.Priority)
+		} else {
+			c.add(pointsNoPriority, "no priority")
+		}
+		if n.DueDate != nil {
+			days := n.DueDate.Sub(p.now).Hours() / 24
+			switch {
+			case days < 0:
+				c.add(pointsOverdue, "overdue since %s", n.DueDateString())
+			case days <= 3:
+				c.add(pointsDueSoon, "due %s", n.DueDateString())
+			case days <= 7:
+				c.add(pointsDueThisWeek, "due %s", n.DueDateString())
+			}
+		}
+
+		switch {
+		case n.ID == p.rootGoal:
+			c.add(pointsRootGoal, "is the root goal")
+		case p.goalAncestors[n.ID]:
+			c.add(pointsGoalAncestor, "needed by root goal %s", p.rootGoal)
+		}
+
+		p.addRecency(c, p.readySince(n))
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// rankTactics scores the tactics that are ready to apply and whose output isn't in the project
+// yet (those were most likely applied already). Since their output is new, they are not part of
+// the root goal's graph yet and get no goal points.
+func (p *project) rankTactics(library []*db.Tactic) []*candidate {
+	existing := map[string]bool{}
+	for _, n := range p.nodes {
+		existing[n.Output] = true
+	}
+	checker := tactics.NewDepChecker(p.nodes, p.policy)
+	var checks []tactics.DepCheck
+	for _, t := range library {
+		checks = append(checks, checker.Check(t))
+	}
+
+	var ret []*candidate
+	for i, t := range library {
+		if !checks[i].Ready || existing[t.Output] || strings.Contains(t.Output, "{{") {
+			continue
+		}
+		c := &candidate{Kind: "tactic", ID: t.ID, Type: t.Type, Output: t.Output, Status: db.DerivedReady,
+			Command: fmt.Sprintf("tactician apply %s", t.ID)}
+
+		// Tactics that would become ready once the output exists and is complete. A new node can
+		// only help if every missing dependency selects it, which rules most tactics out cheaply.
+		node := &db.Node{ID: t.Output, Type: t.Type, Output: t.Output, Status: db.StatusComplete}
+		var produced *tactics.DepChecker
+		unblocked := 0
+		for j, other := range library {
+			if j == i || checks[j].Ready || existing[other.Output] || !selectsAll(checks[j].Missing, node) {
+				continue
+			}
+			if produced == nil {
+				produced = tactics.NewDepChecker(append(append([]*db.Node{}, p.nodes...), node), p.policy)
+			}
+			if produced.Check(other).Ready {
+				unblocked++
+			}
+		}
+		c.add(unblocked*pointsPerEnabled, "enables %s", plural(unblocked, "tactic"))
+
+		var since time.Time
+		for _, dep := range t.Match {
+			pred, err := db.ParsePredicate(dep)
+			if err != nil {
+				continue
+			}
+			for _, n := range pred.Select(p.nodes) {
+				if n.CompletedAt != nil && n.CompletedAt.After(since) {
+					since = *n.CompletedAt
+				}
+			}
+		}
+		p.addRecency(c, since)
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// selectsAll reports whether every dependency in deps is a predicate selecting n.
+func selectsAll(deps []string, n *db.Node) bool {
+	for _, dep := range deps {
+		pred, err := db.ParsePredicate(dep)
+		if err != nil || !pred.Matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// readySince is when n became workable: created, or its last dependency completed.
+func (p *project) readySince(n *db.Node) time.Time {
+	since := n.CreatedAt
+	for _, d := range p.ix.Dependencies(n.ID) {
+		if d.CompletedAt != nil && d.CompletedAt.After(since) {
+			since = *d.CompletedAt
+		}
+	}
+	return since
+}
+
+func (p *project) addRecency(c *candidate, since time.Time) {
+	if since.IsZero() {
+		return
+	}
+	switch age := p.now.Sub(since); {
+	case age <= 24*time.Hour:
+		c.add(pointsRecentDay, "ready for less than a day")
+	case age <= 7*24*time.Hour:
+		c.add(pointsRecentWeek, "ready for less than a week")
+	}
+}
+
+// sortCandidates orders by score, then nodes before tactics, then ID.
+func sortCandidates(cs []*candidate) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		si, sj := cs[i].Score(), cs[j].Score()
+		if si != sj {
+			return si > sj
+		}
+		if cs[i].Kind != cs[j].Kind {
+			return cs[i].Kind == "node"
+		}
+		return cs[i].ID < cs[j].ID
+	})
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,206 @@
+package next
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+var now = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+type nodeOpt func(*db.Node)
+
+func node(id, status string, opts ...nodeOpt) *db.Node {
+	n := &db.Node{ID: id, Type: "code", Output: id, Status: status, CreatedAt: now.Add(-30 * 24 * time.Hour)}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+func priority(p string) nodeOpt { return func(n *db.Node) { n.Priority = &p } }
+
+func due(day string) nodeOpt {
+	return func(n *db.Node) {
+		d, _ := db.ParseDueDate(day)
+		n.DueDate = &d
+	}
+}
+
+func created(ago time.Duration) nodeOpt {
+	return func(n *db.Node) { n.CreatedAt = now.Add(-ago) }
+}
+
+func completed(ago time.Duration) nodeOpt {
+	return func(n *db.Node) {
+		at := now.Add(-ago)
+		n.CompletedAt = &at
+	}
+}
+
+func edges(pairs ...[2]string) []db.Edge {
+	var ret []db.Edge
+	for _, p := range pairs {
+		ret = append(ret, db.Edge{SourceNodeID: p[0], TargetNodeID: p[1]})
+	}
+	return ret
+}
+
+// ranked renders candidates in order as "id: why (+points), ...", factors in the order they were added.
+func ranked(cs []*candidate) []string {
+	var ret []string
+	for _, c := range cs {
+		var parts []string
+		for _, f := range c.Factors {
+			parts = append(parts, fmt.Sprintf("%s (%+d)", f.Why, f.Points))
+		}
+		ret = append(ret, c.ID+": "+strings.Join(parts, ", "))
+	}
+	return ret
+}
+
+func TestRankNodes(t *testing.T) {
+	// spec -> impl -> test is the critical chain; review waits on spec and docs, so finishing spec
+	// alone doesn't unblock it. lint only became ready when done completed an hour ago.
+	nodes := []*db.Node{
+		node("spec", db.StatusPending, priority(db.PriorityHigh), due("2026-03-04"), created(2*time.Hour)),
+		node("impl", db.StatusPending),
+		node("test", db.StatusPending),
+		node("docs", db.StatusInProgress, priority(db.PriorityLow), due("2026-03-01"), created(3*24*time.Hour)),
+		node("review", db.StatusPending),
+		node("done", db.StatusComplete, completed(time.Hour)),
+		node("lint", db.StatusPending, due("2026-03-08")),
+	}
+	graph := edges([2]string{"spec", "impl"}, [2]string{"impl", "test"},
+		[2]string{"spec", "review"}, [2]string{"docs", "review"}, [2]string{"done", "lint"})
+
+	for _, tc := range []struct {
+		name     string
+		rootGoal string
+		want     []string
+	}{
+		{
+			name:     "goal ancestor",
+			rootGoal: "test",
+			want: []string{
+				"spec: unblocks 1 node (+10), 3 nodes downstream (+6), on the critical path (+25), priority high (+20), due 2026-03-04 (+20), needed by root goal test (+10), ready for less than a day (+5)",
+				"docs: in progress (+15), 1 node downstream (+2), overdue since 2026-03-01 (+30), ready for less than a week (+2)",
+				"lint: no priority (+5), due 2026-03-08 (+10), ready for less than a day (+5)",
+			},
+		},
+		{
+			name:     "root goal",
+			rootGoal: "spec",
+			want: []string{
+				"spec: unblocks 1 node (+10), 3 nodes downstream (+6), on the critical path (+25), priority high (+20), due 2026-03-04 (+20), is the root goal (+20), ready for less than a day (+5)",
+				"docs: in progress (+15), 1 node downstream (+2), overdue since 2026-03-01 (+30), ready for less than a week (+2)",
+				"lint: no priority (+5), due 2026-03-08 (+10), ready for less than a day (+5)",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := newProject(nodes, graph, db.DefaultStatusPolicy(), tc.rootGoal, now)
+			if err != nil {
+				t.Fatalf("newProject: %v", err)
+			}
+			cs := p.rankNodes()
+			sortCandidates(cs)
+			if got := ranked(cs); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ranked:\n  %s\nwant:\n  %s", strings.Join(got, "\n  "), strings.Join(tc.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestRankTactics(t *testing.T) {
+	nodes := []*db.Node{
+		node("reqs", db.StatusComplete, completed(2*time.Hour)),
+		node("impl", db.StatusPending),
+	}
+	library := []*db.Tactic{
+		{ID: "design", Output: "design", Match: []string{"reqs"}},
+		// Enabled by design: its only missing dependency is design.
+		{ID: "build", Output: "build", Match: []string{"design"}},
+		{ID: "ship", Output: "ship", Match: []string{"design", "reqs"}},
+		// Also waits on audit, so design alone doesn't enable it.
+		{ID: "review", Output: "review", Match: []string{"design", "audit"}},
+		{ID: "audit_tool", Output: "audit"},
+		{ID: "plan_b", Output: "plan_b", Match: []string{"reqs"}},
+		// Ready, but its output exists (applied already) or is a template.
+		{ID: "implement", Output: "impl", Match: []string{"reqs"}},
+		{ID: "templated", Output: "{{.name}}"},
+	}
+
+	p, err := newProject(nodes, nil, db.DefaultStatusPolicy(), "", now)
+	if err != nil {
+		t.Fatalf("newProject: %v", err)
+	}
+	cs := p.rankTactics(library)
+	sortCandidates(cs)
+	want := []string{
+		"design: enables 2 tactics (+10), ready for less than a day (+5)",
+		"plan_b: ready for less than a day (+5)",
+		"audit_tool: ",
+	}
+	if got := ranked(cs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ranked:\n  %s\nwant:\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestSelectsAll(t *testing.T) {
+	n := &db.Node{ID: "design", Type: "document", Output: "design"}
+	for _, tc := range []struct {
+		deps []string
+		want bool
+	}{
+		{deps: []string{"design"}, want: true},
+		{deps: []string{"des*", "type:document"}, want: true},
+		{deps: []string{"design", "audit"}, want: false},
+		{deps: []string{"type:code"}, want: false},
+	} {
+		if got := selectsAll(tc.deps, n); got != tc.want {
+			t.Fatalf("selectsAll(%v) = %v, want %v", tc.deps, got, tc.want)
+		}
+	}
+}
+
+func TestSortCandidates(t *testing.T) {
+	cs := []*candidate{
+		{Kind: "tactic", ID: "a", Factors: []factor{{Points: 10}}},
+		{Kind: "node", ID: "c", Factors: []factor{{Points: 10}}},
+		{Kind: "node", ID: "b", Factors: []factor{{Points: 4}, {Points: 6}}},
+		{Kind: "node", ID: "z", Factors: []factor{{Points: 20}}},
+		{Kind: "tactic", ID: "d"},
+	}
+	sortCandidates(cs)
+	// By score, then nodes before tactics, then ID.
+	var got []string
+	for _, c := range cs {
+		got = append(got, c.ID)
+	}
+	if want := []string{"z", "b", "c", "a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+}
+
+func TestNewProject_BadDataEstimate(t *testing.T) {
+	nodes := []*db.Node{
+		{ID: "spec", Output: "spec", Status: db.StatusPending, Data: []byte(`{"estimate":"lots"}`)},
+		{ID: "impl", Output: "impl", Status: db.StatusPending},
+	}
+
+	p, err := newProject(nodes, edges([2]string{"spec", "impl"}), db.DefaultStatusPolicy(), "impl", now)
+	if err != nil {
+		t.Fatalf("newProject: %v", err)
+	}
+	if len(p.schedule.Warnings) != 1 {
+		t.Fatalf("expected a warning about spec's estimate, got %v", p.schedule.Warnings)
+	}
+	if cs := p.rankNodes(); len(cs) != 1 || cs[0].ID != "spec" {
+		t.Fatalf("expected spec to be ranked, got %v", cs)
+	}
+}
@@ -0,0 +1,34 @@
+package next
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterNextCommands(root *cobra.Command) error {
+	nextCmd, err := NewNextCommand()
+	if err != nil {
+		return err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		nextCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+
+	// --json is a shortcut for --output json, for agents calling next in a loop.
+	cobraCmd.Flags().Bool("json", false, "Output JSON (same as --output json)")
+	cobraCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return cmd.Flags().Set("output", "json")
+		}
+		return nil
+	}
+
+	root.AddCommand(cobraCmd)
+	return nil
+}
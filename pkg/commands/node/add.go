@@ -52,10 +52,11 @@ func NewNodeAddCommand() (*NodeAddCommand, error) {
 			),
 			fields.New("status", fields.TypeChoice,
 				fields.WithHelp("Initial status"),
-				fields.WithChoices("pending", "complete"),
-				fields.WithDefault("pending"),
+				fields.WithChoices(db.Statuses...),
+				fields.WithDefault(db.StatusPending),
 			),
 		),
+		schema.WithFields(attributeFields()...),
 	)
 	if err != nil {
 		return nil, err
@@ -84,42 +85,45 @@ func (c *NodeAddCommand) Run(ctx context.Context, vals *values.Values) error {
 	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
 		return errors.Wrap(err, "decode node add settings")
 	}
-
-	st, err := store.Load(ctx, tSettings.Dir)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = st.Close() }()
-
-	existing, err := st.Project.GetNode(ctx, settings.NodeID)
-	if err != nil {
-		return err
-	}
-	if existing != nil {
-		return errors.Errorf("node already exists: %s", settings.NodeID)
-	}
-
-	node := &db.Node{
-		ID:     settings.NodeID,
-		Type:   settings.Type,
-		Output: settings.Output,
-		Status: settings.Status,
-	}
-	if node.Status == "complete" {
-		now := time.Now().UTC()
-		node.CompletedAt = &now
-	}
-
-	if err := st.Project.AddNode(ctx, node); err != nil {
-		return err
-	}
-
-	details := "Created node: " + settings.NodeID
-	nodeID := settings.NodeID
-	if err := st.Project.LogAction(ctx, "node_created", &details, &nodeID, nil); err != nil {
-		return err
+	attrs := &NodeAttributeSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, attrs); err != nil {
+		return errors.Wrap(err, "decode node attributes")
 	}
 
-	st.Dirty = true
-	return st.Save(ctx)
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		existing, err := st.Project.GetNode(ctx, settings.NodeID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return errors.Errorf("node already exists: %s", settings.NodeID)
+		}
+
+		node := &db.Node{
+			ID:     settings.NodeID,
+			Type:   settings.Type,
+			Output: settings.Output,
+			Status: settings.Status,
+		}
+		if node.Status == db.StatusComplete {
+			now := time.Now().UTC()
+			node.CompletedAt = &now
+		}
+		if _, err := attrs.apply(node); err != nil {
+			return err
+		}
+
+		if err := st.Project.AddNode(ctx, node); err != nil {
+			return err
+		}
+
+		details := "Created node: " + settings.NodeID
+		nodeID := settings.NodeID
+		if err := st.Project.LogAction(ctx, "node_created", &details, &nodeID, nil); err != nil {
+			return err
+		}
+
+		st.Dirty = true
+		return nil
+	})
 }
@@ -0,0 +1,112 @@
+package node
+
+import (
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Attribute names accepted by `node edit --clear`.
+const (
+	attrAssignee = "assignee"
+	attrPriority = "priority"
+	attrEstimate = "estimate"
+	attrDue      = "due"
+	attrLabels   = "labels"
+)
+
+var attributeNames = []string{attrAssignee, attrPriority, attrEstimate, attrDue, attrLabels}
+
+// NodeAttributeSettings holds the planning attribute flags shared by `node add` and `node edit`.
+type NodeAttributeSettings struct {
+	Assignee string   `glazed.parameter:"assignee"`
+	Priority string   `glazed.parameter:"priority"`
+	Estimate string   `glazed.parameter:"estimate"`
+	Due      string   `glazed.parameter:"due"`
+	Labels   []string `glazed.parameter:"labels"`
+}
+
+func attributeFields() []*fields.Definition {
+	return []*fields.Definition{
+		fields.New(attrAssignee, fields.TypeString,
+			fields.WithHelp("Assignee ('me' = $TACTICIAN_USER or $USER)"),
+		),
+		fields.New(attrPriority, fields.TypeChoice,
+			fields.WithHelp("Priority"),
+			fields.WithChoices(db.Priorities...),
+		),
+		fields.New(attrEstimate, fields.TypeString,
+			fields.WithHelp("Estimate: story points (3) or a duration (90m, 4h, 2d, 1w)"),
+		),
+		fields.New(attrDue, fields.TypeString,
+			fields.WithHelp("Due date (YYYY-MM-DD)"),
+		),
+		fields.New(attrLabels, fields.TypeStringList,
+			fields.WithHelp("Labels (replaces existing labels)"),
+		),
+	}
+}
+
+// apply sets every attribute given on the command line and returns a
+// "name=value" description of each change.
+func (s *NodeAttributeSettings) apply(n *db.Node) ([]string, error) {
+	var changes []string
+
+	if a := sections.ResolveAssignee(s.Assignee); a != "" {
+		n.Assignee = &a
+		changes = append(changes, attrAssignee+"="+a)
+	}
+	if p := strings.TrimSpace(s.Priority); p != "" {
+		if !db.IsKnownPriority(p) {
+			return nil, errors.Errorf("unknown priority %q (want one of %s)", p, strings.Join(db.Priorities, ", "))
+		}
+		n.Priority = &p
+		changes = append(changes, attrPriority+"="+p)
+	}
+	if e := strings.TrimSpace(s.Estimate); e != "" {
+		if _, _, err := db.ParseEstimate(e); err != nil {
+			return nil, err
+		}
+		n.Estimate = &e
+		changes = append(changes, attrEstimate+"="+e)
+	}
+	if d := strings.TrimSpace(s.Due); d != "" {
+		t, err := db.ParseDueDate(d)
+		if err != nil {
+			return nil, err
+		}
+		n.DueDate = &t
+		changes = append(changes, attrDue+"="+d)
+	}
+	if len(s.Labels) > 0 {
+		n.Labels = db.NormalizeLabels(s.Labels)
+		changes = append(changes, attrLabels+"="+strings.Join(n.Labels, ","))
+	}
+
+	return changes, nil
+}
+
+func (s *NodeAttributeSettings) isSet() bool {
+	return s.Assignee != "" || s.Priority != "" || s.Estimate != "" || s.Due != "" || len(s.Labels) > 0
+}
+
+func clearAttribute(n *db.Node, name string) error {
+	switch name {
+	case attrAssignee:
+		n.Assignee = nil
+	case attrPriority:
+		n.Priority = nil
+	case attrEstimate:
+		n.Estimate = nil
+	case attrDue:
+		n.DueDate = nil
+	case attrLabels:
+		n.Labels = nil
+	default:
+		return errors.Errorf("unknown attribute %q (want one of %s)", name, strings.Join(attributeNames, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package node
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// parseDataAssignment splits a `--set key.path=value` flag. The value is read as
+// a YAML scalar or flow collection (3, true, [a, b], {k: v}); dates and
+// anything that doesn't parse stay plain strings.
+func parseDataAssignment(s string) ([]string, interface{}, error) {
+	key, raw, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, nil, errors.Errorf("invalid --set %q (want key.path=value)", s)
+	}
+	path, err := splitDataPath(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if raw == "" {
+		return path, "", nil
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		return path, raw, nil
+	}
+	if _, isTime := value.(time.Time); isTime {
+		return path, raw, nil
+	}
+	return path, value, nil
+}
+
+func splitDataPath(key string) ([]string, error) {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for _, p := range parts {
+		if p == "" {
+			return nil, errors.Errorf("invalid data path %q", key)
+		}
+	}
+	return parts, nil
+}
+
+func decodeData(raw json.RawMessage) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if len(raw) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, errors.Wrap(err, "unmarshal node data")
+	}
+	return data, nil
+}
+
+func encodeData(data map[string]interface{}) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal node data")
+	}
+	return b, nil
+}
+
+// setDataPath sets data[a][b]...=value, creating intermediate maps as needed.
+func setDataPath(data map[string]interface{}, path []string, value interface{}) error {
+	m := data
+	for i, key := range path[:len(path)-1] {
+		next, ok := m[key]
+		if !ok {
+			child := map[string]interface{}{}
+			m[key] = child
+			m = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("data.%s is not a map", strings.Join(path[:i+1], "."))
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+	return nil
+}
+
+// unsetDataPath removes data[a][b]..., dropping maps left empty by the removal.
+func unsetDataPath(data map[string]interface{}, path []string) error {
+	key := path[0]
+	if len(path) == 1 {
+		if _, ok := data[key]; !ok {
+			return errors.Errorf("data has no key %q", key)
+		}
+		delete(data, key)
+		return nil
+	}
+	child, ok := data[key].(map[string]interface{})
+	if !ok {
+		return errors.Errorf("data has no key %q", strings.Join(path, "."))
+	}
+	if err := unsetDataPath(child, path[1:]); err != nil {
+		return errors.Errorf("data has no key %q", strings.Join(path, "."))
+	}
+	if len(child) == 0 {
+		delete(data, key)
+	}
+	return nil
+}
@@ -77,43 +77,39 @@ func (c *NodeDeleteCommand) Run(ctx context.Context, vals *values.Values) error
 		return errors.New("at least one node id is required")
 	}
 
-	st, err := store.Load(ctx, tSettings.Dir)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = st.Close() }()
-
-	// Validate
-	for _, id := range settings.NodeIDs {
-		n, err := st.Project.GetNode(ctx, id)
-		if err != nil {
-			return err
-		}
-		if n == nil {
-			return errors.Errorf("node not found: %s", id)
-		}
-		if !settings.Force {
-			blocks, err := st.Project.GetBlockedBy(ctx, id)
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		// Validate
+		for _, id := range settings.NodeIDs {
+			n, err := st.Project.GetNode(ctx, id)
 			if err != nil {
 				return err
 			}
-			if len(blocks) > 0 {
-				return errors.Errorf("cannot delete %s: it blocks %d node(s) (use --force)", id, len(blocks))
+			if n == nil {
+				return errors.Errorf("node not found: %s", id)
+			}
+			if !settings.Force {
+				blocks, err := st.Project.GetBlockedBy(ctx, id)
+				if err != nil {
+					return err
+				}
+				if len(blocks) > 0 {
+					return errors.Errorf("cannot delete %s: it blocks %d node(s) (use --force)", id, len(blocks))
+				}
 			}
 		}
-	}
 
-	for _, id := range settings.NodeIDs {
-		if err := st.Project.DeleteNode(ctx, id); err != nil {
-			return err
-		}
-		details := "Deleted node: " + id
-		nodeID := id
-		if err := st.Project.LogAction(ctx, "node_deleted", &details, &nodeID, nil); err != nil {
-			return err
+		for _, id := range settings.NodeIDs {
+			if err := st.Project.DeleteNode(ctx, id); err != nil {
+				return err
+			}
+			details := "Deleted node: " + id
+			nodeID := id
+			if err := st.Project.LogAction(ctx, "node_deleted", &details, &nodeID, nil); err != nil {
+				return err
+			}
 		}
-	}
 
-	st.Dirty = true
-	return st.Save(ctx)
+		st.Dirty = true
+		return nil
+	})
 }
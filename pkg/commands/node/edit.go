@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds/schema"
 	"github.com/go-go-golems/glazed/pkg/cmds/values"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
 	"github.com/pkg/errors"
 )
@@ -18,8 +20,15 @@ type NodeEditCommand struct {
 }
 
 type NodeEditSettings struct {
-	NodeIDs []string `glazed.parameter:"node-ids"`
-	Status  string   `glazed.parameter:"status"`
+	NodeIDs      []string `glazed.parameter:"node-ids"`
+	Status       string   `glazed.parameter:"status"`
+	Output       string   `glazed.parameter:"output"`
+	Type         string   `glazed.parameter:"type"`
+	Set          []string `glazed.parameter:"set"`
+	Unset        []string `glazed.parameter:"unset"`
+	AddLabels    []string `glazed.parameter:"add-label"`
+	RemoveLabels []string `glazed.parameter:"remove-label"`
+	Clear        []string `glazed.parameter:"clear"`
 }
 
 func NewNodeEditCommand() (*NodeEditCommand, error) {
@@ -41,10 +50,32 @@ func NewNodeEditCommand() (*NodeEditCommand, error) {
 		schema.WithFields(
 			fields.New("status", fields.TypeChoice,
 				fields.WithHelp("Update status (applied to all specified nodes)"),
-				fields.WithChoices("pending", "complete"),
-				fields.WithRequired(true),
+				fields.WithChoices(db.Statuses...),
+			),
+			fields.New("output", fields.TypeString,
+				fields.WithHelp("Change the output artifact"),
+			),
+			fields.New("type", fields.TypeString,
+				fields.WithHelp("Change the node type"),
+			),
+			fields.New("set", fields.TypeStringList,
+				fields.WithHelp("Set a data key: key.path=value (value is parsed as YAML, e.g. 3, true, [a, b])"),
+			),
+			fields.New("unset", fields.TypeStringList,
+				fields.WithHelp("Remove a data key by dotted path"),
+			),
+			fields.New("add-label", fields.TypeStringList,
+				fields.WithHelp("Add labels, keeping existing ones"),
+			),
+			fields.New("remove-label", fields.TypeStringList,
+				fields.WithHelp("Remove labels"),
+			),
+			fields.New("clear", fields.TypeChoiceList,
+				fields.WithHelp("Unset attributes"),
+				fields.WithChoices(attributeNames...),
 			),
 		),
+		schema.WithFields(attributeFields()...),
 	)
 	if err != nil {
 		return nil, err
@@ -55,7 +86,7 @@ func NewNodeEditCommand() (*NodeEditCommand, error) {
 	cmdDef := cmds.NewCommandDefinition(
 		"edit",
 		cmds.WithShort("Edit one or more nodes"),
-		cmds.WithLong("Update status for nodes. Supports batch operations: 'node edit id1 id2 --status complete'"),
+		cmds.WithLong("Update status, output, type, data keys and planning attributes (assignee, priority, estimate, due date, labels) for nodes. Use 'node rename' to change an ID. Supports batch operations: 'node edit id1 id2 --status complete'. Transitions are checked against the status state machine (see statuses in .tactician/config.yaml)."),
 		cmds.WithSchema(s),
 	)
 
@@ -77,46 +108,162 @@ func (c *NodeEditCommand) Run(ctx context.Context, vals *values.Values) error {
 	if len(settings.NodeIDs) == 0 {
 		return errors.New("at least one node id is required")
 	}
-	if settings.Status == "" {
-		return errors.New("--status is required")
+	attrs := &NodeAttributeSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, attrs); err != nil {
+		return errors.Wrap(err, "decode node attributes")
 	}
-
-	st, err := store.Load(ctx, tSettings.Dir)
-	if err != nil {
-		return err
+	if settings.Status == "" && settings.Output == "" && settings.Type == "" && !attrs.isSet() &&
+		len(settings.Set) == 0 && len(settings.Unset) == 0 &&
+		len(settings.AddLabels) == 0 && len(settings.RemoveLabels) == 0 && len(settings.Clear) == 0 {
+		return errors.New("nothing to edit: pass --status, --output, --type, --set/--unset or an attribute flag")
 	}
-	defer func() { _ = st.Close() }()
 
-	var completedAt *time.Time
-	if settings.Status == "complete" {
-		now := time.Now().UTC()
-		completedAt = &now
+	type assignment struct {
+		path  []string
+		value interface{}
 	}
-
-	for _, id := range settings.NodeIDs {
-		n, err := st.Project.GetNode(ctx, id)
+	var sets []assignment
+	for _, a := range settings.Set {
+		path, value, err := parseDataAssignment(a)
 		if err != nil {
 			return err
 		}
-		if n == nil {
-			return errors.Errorf("node not found: %s", id)
+		sets = append(sets, assignment{path: path, value: value})
+	}
+	var unsets [][]string
+	for _, key := range settings.Unset {
+		path, err := splitDataPath(key)
+		if err != nil {
+			return err
 		}
+		unsets = append(unsets, path)
+	}
 
-		if err := st.Project.UpdateNodeStatus(ctx, id, settings.Status, completedAt); err != nil {
-			return err
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		policy := st.StatusPolicy()
+		now := time.Now().UTC()
+
+		// Validate everything before changing anything.
+		type edit struct {
+			before, after *db.Node
+			changes       []string
 		}
+		edits := make([]edit, 0, len(settings.NodeIDs))
+		for _, id := range settings.NodeIDs {
+			n, err := st.Project.GetNode(ctx, id)
+			if err != nil {
+				return err
+			}
+			if n == nil {
+				return errors.Errorf("node not found: %s", id)
+			}
+			before := *n
+			e := edit{before: &before, after: n}
+
+			if settings.Status != "" && settings.Status != n.Status {
+				if err := policy.CheckTransition(n.Status, settings.Status); err != nil {
+					return errors.Wrapf(err, "node %s", id)
+				}
+				n.Status = settings.Status
+				n.CompletedAt = nil
+				if settings.Status == db.StatusComplete {
+					n.CompletedAt = &now
+				}
+			}
 
-		action := "node_updated"
-		details := "Updated " + id + " status to " + settings.Status
-		if settings.Status == "complete" {
-			action = "node_completed"
+			if settings.Output != "" && settings.Output != n.Output {
+				n.Output = settings.Output
+				e.changes = append(e.changes, "output="+n.Output)
+			}
+			if settings.Type != "" && settings.Type != n.Type {
+				n.Type = settings.Type
+				e.changes = append(e.changes, "type="+n.Type)
+			}
+			if len(sets) > 0 || len(unsets) > 0 {
+				data, err := decodeData(n.Data)
+				if err != nil {
+					return errors.Wrapf(err, "node %s", id)
+				}
+				for _, path := range unsets {
+					if err := unsetDataPath(data, path); err != nil {
+						return errors.Wrapf(err, "node %s", id)
+					}
+					e.changes = append(e.changes, "unset data."+strings.Join(path, "."))
+				}
+				for _, a := range sets {
+					if err := setDataPath(data, a.path, a.value); err != nil {
+						return errors.Wrapf(err, "node %s", id)
+					}
+					e.changes = append(e.changes, "data."+strings.Join(a.path, ".")+" set")
+				}
+				if n.Data, err = encodeData(data); err != nil {
+					return err
+				}
+			}
+
+			for _, name := range settings.Clear {
+				if err := clearAttribute(n, name); err != nil {
+					return err
+				}
+				e.changes = append(e.changes, "cleared "+name)
+			}
+			changes, err := attrs.apply(n)
+			if err != nil {
+				return errors.Wrapf(err, "node %s", id)
+			}
+			e.changes = append(e.changes, changes...)
+			if len(settings.AddLabels) > 0 || len(settings.RemoveLabels) > 0 {
+				n.Labels = editLabels(n.Labels, settings.AddLabels, settings.RemoveLabels)
+				e.changes = append(e.changes, attrLabels+"="+strings.Join(n.Labels, ","))
+			}
+
+			edits = append(edits, e)
 		}
-		nodeID := id
-		if err := st.Project.LogAction(ctx, action, &details, &nodeID, nil); err != nil {
-			return err
+
+		for _, e := range edits {
+			n := e.after
+			statusChanged := n.Status != e.before.Status
+			if !statusChanged && len(e.changes) == 0 {
+				continue
+			}
+			if err := st.Project.ReplaceNode(ctx, n); err != nil {
+				return err
+			}
+
+			action := "node_updated"
+			var parts []string
+			if statusChanged {
+				parts = append(parts, "Updated "+n.ID+" status: "+e.before.Status+" -> "+n.Status)
+				if n.Status == db.StatusComplete {
+					action = "node_completed"
+				}
+			}
+			if len(e.changes) > 0 {
+				parts = append(parts, "Updated "+n.ID+": "+strings.Join(e.changes, ", "))
+			}
+			details := strings.Join(parts, "; ")
+			nodeID := n.ID
+			if err := st.Project.LogAction(ctx, action, &details, &nodeID, nil); err != nil {
+				return err
+			}
 		}
-	}
 
-	st.Dirty = true
-	return st.Save(ctx)
+		st.Dirty = true
+		return nil
+	})
+}
+
+// editLabels adds then removes labels, returning the normalized result.
+func editLabels(labels, add, remove []string) []string {
+	drop := map[string]bool{}
+	for _, l := range remove {
+		drop[strings.TrimSpace(l)] = true
+	}
+	var out []string
+	for _, l := range append(append([]string{}, labels...), add...) {
+		if !drop[strings.TrimSpace(l)] {
+			out = append(out, l)
+		}
+	}
+	return db.NormalizeLabels(out)
 }
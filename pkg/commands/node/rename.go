@@ -0,0 +1,92 @@
+package node
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type NodeRenameCommand struct {
+	*cmds.CommandDefinition
+}
+
+type NodeRenameSettings struct {
+	OldID string `glazed.parameter:"old-id"`
+	NewID string `glazed.parameter:"new-id"`
+}
+
+func NewNodeRenameCommand() (*NodeRenameCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("old-id", fields.TypeString,
+				fields.WithHelp("Current node ID"),
+				fields.WithRequired(true),
+			),
+			fields.New("new-id", fields.TypeString,
+				fields.WithHelp("New node ID"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"rename",
+		cmds.WithShort("Rename a node, keeping its edges and history"),
+		cmds.WithLong("Change a node ID. Edges, parent_tactic references, the project root_goal and action-log entries are rewritten to the new ID. Refuses if the new ID is already taken."),
+		cmds.WithSchema(s),
+	)
+
+	return &NodeRenameCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &NodeRenameCommand{}
+
+func (c *NodeRenameCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &NodeRenameSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode node rename settings")
+	}
+	newID := strings.TrimSpace(settings.NewID)
+	if newID == "" {
+		return errors.New("new node id must not be empty")
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		if err := st.Project.RenameNode(ctx, settings.OldID, newID); err != nil {
+			return err
+		}
+
+		details := "Renamed node: " + settings.OldID + " -> " + newID
+		if err := st.Project.LogAction(ctx, "node_renamed", &details, &newID, nil); err != nil {
+			return err
+		}
+
+		st.Dirty = true
+		return nil
+	})
+}
@@ -64,6 +64,19 @@ func RegisterNodeCommands(root *cobra.Command) error {
 	}
 	nodeCmd.AddCommand(cobraDeleteCmd)
 
+	renameCmd, err := NewNodeRenameCommand()
+	if err != nil {
+		return err
+	}
+	cobraRenameCmd, err := cli.BuildCobraCommandFromCommand(
+		renameCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	nodeCmd.AddCommand(cobraRenameCmd)
+
 	root.AddCommand(nodeCmd)
 	return nil
 }
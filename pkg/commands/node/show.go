@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"strings"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
 	"github.com/pkg/errors"
 )
@@ -107,7 +109,19 @@ func (c *NodeShowCommand) RunIntoGlazeProcessor(
 			types.MRP("introduced_as", n.IntroducedAs),
 			types.MRP("created_at", n.CreatedAt),
 			types.MRP("completed_at", n.CompletedAt),
+			types.MRP("assignee", db.StringValue(n.Assignee)),
+			types.MRP("priority", db.StringValue(n.Priority)),
+			types.MRP("estimate", db.StringValue(n.Estimate)),
+			types.MRP("due", n.DueDateString()),
+			types.MRP("labels", strings.Join(n.Labels, ",")),
+			types.MRP("tactic_instance", db.StringValue(n.TacticInstance)),
 		)
+		if len(n.TacticParams) > 0 {
+			row.Set("tactic_params", n.TacticParams)
+		}
+		if n.ProducerTactic != nil {
+			row.Set("producer_tactic", *n.ProducerTactic)
+		}
 		if err := gp.AddRow(ctx, row); err != nil {
 			return err
 		}
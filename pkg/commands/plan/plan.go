@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+)
+
+type PlanCommand struct {
+	*cmds.CommandDefinition
+}
+
+type PlanSettings struct {
+	Target   string `glazed.parameter:"target"`
+	Premises bool   `glazed.parameter:"premises"`
+	Apply    bool   `glazed.parameter:"apply"`
+}
+
+func NewPlanCommand() (*PlanCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("target", fields.TypeString,
+				fields.WithHelp("Output to reach (e.g. api_code)"),
+				fields.WithRequired(true),
+			),
+		),
+		schema.WithFields(
+			fields.New("premises", fields.TypeBool,
+				fields.WithHelp("Also plan tactics for missing premises instead of leaving them as placeholders"),
+				fields.WithDefault(false),
+			),
+			fields.New("apply", fields.TypeBool,
+				fields.WithHelp("Apply the whole chain in dependency order"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"plan",
+		cmds.WithShort("Find the tactics that lead to an output"),
+		cmds.WithLong("Chain backwards from a target output over the tactics library (output, match, premises) and list the smallest set of tactics "+
+			"that produces it from what the project already has, in the order to apply them, with the other tactics that produce the same outputs. "+
+			"Outputs of complete project nodes count as available. Outputs of open nodes (pending, in progress, on hold) aren't produced again: "+
+			"the steps waiting for them list them in waits_on. Cancelled and won't do nodes are ignored. --apply applies the chain in one save."),
+		cmds.WithSchema(s),
+	)
+
+	return &PlanCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &PlanCommand{}
+
+func (c *PlanCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &PlanSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode plan settings")
+	}
+
+	if !settings.Apply {
+		st, err := store.Load(ctx, tSettings.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = st.Close() }()
+
+		chain, err := planChain(ctx, st, settings)
+		if err != nil {
+			return err
+		}
+		return emitChain(ctx, gp, chain, false)
+	}
+
+	var applied *tactics.Chain
+	err := store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		chain, err := planChain(ctx, st, settings)
+		if err != nil {
+			return err
+		}
+		if _, err := chain.Execute(ctx, st.Project, st.StatusPolicy(), time.Now().UTC()); err != nil {
+			return err
+		}
+		applied = chain
+		st.Dirty = len(chain.Steps) > 0
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return emitChain(ctx, gp, applied, true)
+}
+
+func planChain(ctx context.Context, st *store.State, settings *PlanSettings) (*tactics.Chain, error) {
+	library, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tactics.PlanChain(settings.Target, library, nodes, st.StatusPolicy(), tactics.ChainOptions{Premises: settings.Premises})
+}
+
+func emitChain(ctx context.Context, gp middlewares.Processor, chain *tactics.Chain, applied bool) error {
+	if chain.Existing != nil {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message",
+			fmt.Sprintf("%s is already in the project: node %s (%s)", chain.Target, chain.Existing.ID, chain.Existing.Status))))
+	}
+	if len(chain.Steps) == 0 && len(chain.Open) > 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message",
+			fmt.Sprintf("%s is already planned: node %s is still %s", chain.Target, chain.Open[0].ID, chain.Open[0].Status))))
+	}
+
+	open := map[string]*db.Node{}
+	for _, n := range chain.Open {
+		open[n.Output] = n
+	}
+
+	for i, step := range chain.Steps {
+		t := step.Instance.Tactic
+		row := types.NewRow(
+			types.MRP("step", i+1),
+			types.MRP("tactic", step.Instance.ID),
+			types.MRP("produces", strings.Join(step.Produces, ",")),
+			types.MRP("match", strings.Join(t.Match, ",")),
+			types.MRP("premises", strings.Join(t.Premises, ",")),
+			types.MRP("waits_on", formatWaits(t, open)),
+			types.MRP("alternatives", formatAlternatives(step.Alternatives)),
+		)
+		if applied {
+			row.Set("applied", true)
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatWaits lists the open project nodes a step's match dependencies wait for.
+func formatWaits(t *db.Tactic, open map[string]*db.Node) string {
+	var parts []string
+	for _, m := range t.Match {
+		if n, ok := open[m]; ok {
+			parts = append(parts, fmt.Sprintf("%s (%s)", n.ID, n.Status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatAlternatives(alts []tactics.Alternative) string {
+	var parts []string
+	for _, a := range alts {
+		if len(a.Missing) > 0 {
+			parts = append(parts, fmt.Sprintf("%s (unreachable: needs %s)", a.TacticID, strings.Join(a.Missing, ",")))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d tactics)", a.TacticID, a.Steps))
+	}
+	return strings.Join(parts, "; ")
+}
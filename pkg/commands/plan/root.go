@@ -0,0 +1,25 @@
+package plan
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterPlanCommands(root *cobra.Command) error {
+	planCmd, err := NewPlanCommand()
+	if err != nil {
+		return err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		planCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+
+	root.AddCommand(cobraCmd)
+	return nil
+}
@@ -0,0 +1,25 @@
+package schedule
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterScheduleCommands(root *cobra.Command) error {
+	scheduleCmd, err := NewScheduleCommand()
+	if err != nil {
+		return err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		scheduleCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+
+	root.AddCommand(cobraCmd)
+	return nil
+}
@@ -0,0 +1,319 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// Working day used to lay the schedule out on a calendar.
+const (
+	workdayStartHour = 9
+	hoursPerWorkday  = 8
+)
+
+type ScheduleCommand struct {
+	*cmds.CommandDefinition
+}
+
+type ScheduleSettings struct {
+	Workers         int     `glazed.parameter:"workers"`
+	HoursPerPoint   float64 `glazed.parameter:"hours-per-point"`
+	DefaultEstimate string  `glazed.parameter:"default-estimate"`
+	Summary         bool    `glazed.parameter:"summary"`
+	Mermaid         bool    `glazed.parameter:"mermaid"`
+	Start           string  `glazed.parameter:"start"`
+}
+
+func NewScheduleCommand() (*ScheduleCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("workers", fields.TypeInteger,
+				fields.WithHelp("Number of people (or agents) working in parallel (0: unlimited)"),
+				fields.WithDefault(0),
+				fields.WithShortFlag("w"),
+			),
+			fields.New("hours-per-point", fields.TypeFloat,
+				fields.WithHelp("Hours per story point, for point estimates"),
+				fields.WithDefault(8.0),
+			),
+			fields.New("default-estimate", fields.TypeString,
+				fields.WithHelp("Estimate of nodes that have none (points like 3 or a duration like 4h, 2d)"),
+				fields.WithDefault("1d"),
+			),
+			fields.New("summary", fields.TypeBool,
+				fields.WithHelp("Output one row with the project duration, makespan and critical path"),
+				fields.WithDefault(false),
+			),
+			fields.New("mermaid", fields.TypeBool,
+				fields.WithHelp("Output the schedule as a Mermaid gantt chart"),
+				fields.WithDefault(false),
+			),
+			fields.New("start", fields.TypeString,
+				fields.WithHelp("First working day of the gantt chart (YYYY-MM-DD, default: today)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"schedule",
+		cmds.WithShort("Compute the critical path and a schedule of the open nodes"),
+		cmds.WithLong("Schedule the open nodes from their estimates (the estimate field, or data.estimate; points are converted with "+
+			"--hours-per-point). Each row has the earliest and latest start and finish, the slack and whether the node is on the "+
+			"critical path, in working hours from now.\n\n"+
+			"start, finish, worker and wave come from a schedule leveled over --workers: whenever someone is free, the ready node with "+
+			"the least slack (then the highest priority) goes first, and the nodes started together form a wave. "+
+			"--mermaid draws that schedule as a gantt chart over 8-hour working days (weekends skipped)."),
+		cmds.WithSchema(s),
+	)
+
+	return &ScheduleCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &ScheduleCommand{}
+
+func (c *ScheduleCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &ScheduleSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode schedule settings")
+	}
+	if settings.Workers < 0 {
+		return errors.New("--workers must be 0 (unlimited) or more")
+	}
+	defaultHours, _, err := dag.NodeHours(&db.Node{ID: "--default-estimate", Estimate: &settings.DefaultEstimate}, settings.HoursPerPoint)
+	if err != nil {
+		return err
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return err
+	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return err
+	}
+
+	sched, err := dag.ComputeSchedule(nodes, edges, dag.ScheduleOptions{
+		Workers:       settings.Workers,
+		HoursPerPoint: settings.HoursPerPoint,
+		DefaultHours:  defaultHours,
+		Policy:        st.StatusPolicy(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, w := range sched.Warnings {
+		_, _ = fmt.Fprintf(store.WarningOutput, "warning: %s\n", w)
+	}
+
+	if settings.Mermaid {
+		meta, err := st.Project.GetProjectMeta(ctx)
+		if err != nil {
+			return err
+		}
+		start := time.Now().UTC()
+		if settings.Start != "" {
+			if start, err = db.ParseDueDate(settings.Start); err != nil {
+				return errors.Wrap(err, "--start")
+			}
+		}
+		return gp.AddRow(ctx, types.NewRow(
+			types.MRP("project", meta["name"]),
+			types.MRP("mermaid", buildMermaidGantt(meta["name"], sched, start)),
+		))
+	}
+
+	if settings.Summary {
+		return gp.AddRow(ctx, types.NewRow(
+			types.MRP("open_nodes", len(sched.Nodes)),
+			types.MRP("duration_hours", round(sched.Duration)),
+			types.MRP("makespan_hours", round(sched.Makespan)),
+			types.MRP("workers", workersLabel(sched.Workers)),
+			types.MRP("waves", waves(sched)),
+			types.MRP("critical_path", strings.Join(sched.CriticalPath, " -> ")),
+		))
+	}
+
+	if len(sched.Nodes) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "No open nodes to schedule.")))
+	}
+	for _, sn := range sched.Nodes {
+		row := types.NewRow(
+			types.MRP("id", sn.Node.ID),
+			types.MRP("type", sn.Node.Type),
+			types.MRP("status", sn.Node.Status),
+			types.MRP("assignee", db.StringValue(sn.Node.Assignee)),
+			types.MRP("hours", round(sn.Duration)),
+			types.MRP("estimated", sn.Estimated),
+			types.MRP("earliest_start", round(sn.EarliestStart)),
+			types.MRP("earliest_finish", round(sn.EarliestFinish)),
+			types.MRP("latest_start", round(sn.LatestStart)),
+			types.MRP("latest_finish", round(sn.LatestFinish)),
+			types.MRP("slack", round(sn.Slack)),
+			types.MRP("critical", sn.Critical),
+			types.MRP("wave", sn.Wave),
+			types.MRP("worker", sn.Worker),
+			types.MRP("start", round(sn.Start)),
+			types.MRP("finish", round(sn.Finish)),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func round(h float64) float64 {
+	return math.Round(h*100) / 100
+}
+
+func workersLabel(n int) string {
+	if n == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(n)
+}
+
+func waves(s *dag.Schedule) int {
+	ret := 0
+	for _, sn := range s.Nodes {
+		if sn.Wave > ret {
+			ret = sn.Wave
+		}
+	}
+	return ret
+}
+
+var mermaidSanitizeRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// workingTime maps hours of work from start onto the calendar: 8-hour days from 09:00, skipping
+// weekends. A finish on a day boundary is that evening; a start on one is the next morning.
+func workingTime(start time.Time, hours float64, finish bool) time.Time {
+	day := time.Date(start.Year(), start.Month(), start.Day(), workdayStartHour, 0, 0, 0, time.UTC)
+	for isWeekend(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	days := int(math.Floor(hours / hoursPerWorkday))
+	rest := hours - float64(days*hoursPerWorkday)
+	if finish && days > 0 && rest < scheduleEpsilon {
+		days--
+		rest = hoursPerWorkday
+	}
+	for days > 0 {
+		day = day.AddDate(0, 0, 1)
+		if !isWeekend(day) {
+			days--
+		}
+	}
+	return day.Add(time.Duration(rest * float64(time.Hour)))
+}
+
+const scheduleEpsilon = 1e-9
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// buildMermaidGantt draws the leveled schedule, one section per worker (or a single section when
+// workers are unlimited), with critical nodes marked crit.
+func buildMermaidGantt(project string, s *dag.Schedule, start time.Time) string {
+	const layout = "2006-01-02 15:04"
+	sb := strings.Builder{}
+	sb.WriteString("gantt\n")
+	title := "Schedule"
+	if project != "" {
+		title = project + " schedule"
+	}
+	sb.WriteString("  title " + title + "\n")
+	sb.WriteString("  dateFormat YYYY-MM-DD HH:mm\n")
+	sb.WriteString("  axisFormat %m-%d\n")
+
+	sections := map[int][]*dag.ScheduledNode{}
+	maxWorker := 0
+	for _, sn := range s.Nodes {
+		w := sn.Worker
+		if s.Workers == 0 {
+			w = 0
+		}
+		sections[w] = append(sections[w], sn)
+		if w > maxWorker {
+			maxWorker = w
+		}
+	}
+	for w := 0; w <= maxWorker; w++ {
+		if len(sections[w]) == 0 {
+			continue
+		}
+		if w == 0 {
+			sb.WriteString("  section Open work\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  section Worker %d\n", w))
+		}
+		for _, sn := range sections[w] {
+			var tags []string
+			if sn.Critical {
+				tags = append(tags, "crit")
+			}
+			if sn.Node.Status == db.StatusInProgress {
+				tags = append(tags, "active")
+			}
+			if sn.Duration == 0 {
+				tags = append(tags, "milestone")
+			}
+			tags = append(tags, mermaidSanitizeRe.ReplaceAllString(sn.Node.ID, "_"),
+				workingTime(start, sn.Start, false).Format(layout), workingTime(start, sn.Finish, sn.Duration > 0).Format(layout))
+			name := strings.NewReplacer(":", " ", "#", " ", ";", " ").Replace(sn.Node.ID)
+			sb.WriteString(fmt.Sprintf("  %s :%s\n", name, strings.Join(tags, ", ")))
+		}
+	}
+	return sb.String()
+}
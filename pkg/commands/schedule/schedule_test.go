@@ -0,0 +1,44 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestBuildMermaidGantt_BackToBackDays(t *testing.T) {
+	// Three 8h tasks in a row from Friday: the second starts Monday morning, not Friday evening.
+	day := "8"
+	nodes := []*db.Node{
+		{ID: "a", Status: db.StatusPending, Estimate: &day},
+		{ID: "b", Status: db.StatusPending, Estimate: &day},
+		{ID: "c", Status: db.StatusPending, Estimate: &day},
+	}
+	edges := []db.Edge{{SourceNodeID: "a", TargetNodeID: "b"}, {SourceNodeID: "b", TargetNodeID: "c"}}
+	s, err := dag.ComputeSchedule(nodes, edges, dag.ScheduleOptions{HoursPerPoint: 1, DefaultHours: 8})
+	if err != nil {
+		t.Fatalf("ComputeSchedule: %v", err)
+	}
+
+	friday := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	gantt := buildMermaidGantt("", s, friday)
+	for _, want := range []string{
+		"a :crit, a, 2026-01-09 09:00, 2026-01-09 17:00",
+		"b :crit, b, 2026-01-12 09:00, 2026-01-12 17:00",
+		"c :crit, c, 2026-01-13 09:00, 2026-01-13 17:00",
+	} {
+		if !strings.Contains(gantt, want) {
+			t.Fatalf("expected %q in:\n%s", want, gantt)
+		}
+	}
+
+	if got := workingTime(friday, 8, true); !got.Equal(time.Date(2026, 1, 9, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a finish after 8h on Friday evening, got %s", got)
+	}
+	if got := workingTime(friday, 8, false); !got.Equal(time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a start after 8h on Monday morning, got %s", got)
+	}
+}
@@ -12,8 +12,10 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/dag"
 	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
 	"github.com/pkg/errors"
 )
 
@@ -128,20 +130,31 @@ func (c *SearchCommand) RunIntoGlazeProcessor(
 	goalIDs := splitCSV(settings.Goals)
 	tagFilters := splitCSV(settings.Tags)
 
-	tactics, err := st.Tactics.SearchTactics(ctx, strings.TrimSpace(settings.Type), tagFilters, keywords)
+	allNodes, err := st.Project.GetAllNodes(ctx)
 	if err != nil {
 		return err
 	}
 
-	allNodes, err := st.Project.GetAllNodes(ctx)
+	// With --ready, tactics waiting on a plain output are dropped in SQL already.
+	var available []string
+	if settings.Ready {
+		available = []string{}
+		for _, n := range allNodes {
+			if st.StatusPolicy().Satisfies(n.Status) {
+				available = append(available, n.Output)
+			}
+		}
+	}
+	candidates, err := st.Tactics.SearchTactics(ctx, strings.TrimSpace(settings.Type), tagFilters, keywords, available)
 	if err != nil {
 		return err
 	}
 
-	ranked, err := rankTactics(ctx, st, tactics, allNodes, keywords, goalIDs)
+	edges, err := st.Project.GetEdges(ctx)
 	if err != nil {
 		return err
 	}
+	ranked := rankTactics(dag.NewIndex(allNodes, edges, st.StatusPolicy()), st.StatusPolicy(), candidates, keywords, goalIDs)
 
 	if settings.Ready {
 		tmp := ranked[:0]
@@ -187,13 +200,6 @@ func (c *SearchCommand) RunIntoGlazeProcessor(
 	return nil
 }
 
-type depStatus struct {
-	Ready        bool
-	Satisfied    []string
-	Missing      []string
-	CanIntroduce []string
-}
-
 type scores struct {
 	Total        int
 	CriticalPath int
@@ -203,7 +209,7 @@ type scores struct {
 
 type rankedTactic struct {
 	Tactic    *db.Tactic
-	DepStatus depStatus
+	DepStatus tactics.DepCheck
 	Scores    scores
 }
 
@@ -231,100 +237,31 @@ func splitWords(s string) []string {
 	return strings.Fields(s)
 }
 
-func computeTacticDependencyStatus(tactic *db.Tactic, allNodes []*db.Node) depStatus {
-	nodeOutputs := map[string]bool{}
-	completeOutputs := map[string]bool{}
-	for _, n := range allNodes {
-		nodeOutputs[n.Output] = true
-		if n.Status == "complete" {
-			completeOutputs[n.Output] = true
-		}
-	}
-
-	var satisfied, missing, canIntroduce []string
-
-	for _, dep := range tactic.Match {
-		if completeOutputs[dep] {
-			satisfied = append(satisfied, dep)
-		} else {
-			missing = append(missing, dep)
-		}
-	}
-
-	for _, dep := range tactic.Premises {
-		// Skip if also in match
-		inMatch := false
-		for _, m := range tactic.Match {
-			if m == dep {
-				inMatch = true
-				break
-			}
-		}
-		if inMatch {
-			continue
-		}
-
-		if completeOutputs[dep] {
-			satisfied = append(satisfied, dep)
-		} else if nodeOutputs[dep] {
-			missing = append(missing, dep)
-		} else {
-			canIntroduce = append(canIntroduce, dep)
-		}
-	}
-
-	allMatchSatisfied := true
-	for _, dep := range tactic.Match {
-		if !completeOutputs[dep] {
-			allMatchSatisfied = false
-			break
-		}
-	}
-
-	return depStatus{
-		Ready:        allMatchSatisfied,
-		Satisfied:    satisfied,
-		Missing:      missing,
-		CanIntroduce: canIntroduce,
-	}
-}
-
-func computeCriticalPathScore(ctx context.Context, st *store.State, tactic *db.Tactic, allNodes []*db.Node) (int, error) {
-	score := 0
-	for _, n := range allNodes {
-		if n.Status != "pending" {
+// criticalPathScores scores outputs by the open nodes they block: 2 per node they alone block, 1
+// per node they block along with other outputs.
+func criticalPathScores(ix *dag.Index, policy *db.StatusPolicy) map[string]int {
+	scores := map[string]int{}
+	for _, n := range ix.Nodes {
+		if !policy.IsOpen(n.Status) {
 			continue
 		}
-		deps, err := st.Project.GetDependencies(ctx, n.ID)
-		if err != nil {
-			return 0, err
-		}
-		var blockers []*db.Node
-		for _, d := range deps {
-			if d.Status != "complete" {
-				blockers = append(blockers, d)
-			}
-		}
+		blockers := ix.Blockers(n.ID)
 		if len(blockers) == 0 {
 			continue
 		}
-		blocksByThis := false
+		points := 1
+		if len(blockers) == 1 {
+			points = 2
+		}
+		seen := map[string]bool{}
 		for _, b := range blockers {
-			if b.Output == tactic.Output {
-				blocksByThis = true
-				break
+			if !seen[b.Output] {
+				seen[b.Output] = true
+				scores[b.Output] += points
 			}
 		}
-		if !blocksByThis {
-			continue
-		}
-		if len(blockers) == 1 {
-			score += 2
-		} else {
-			score += 1
-		}
 	}
-	return score, nil
+	return scores
 }
 
 func computeKeywordScore(tactic *db.Tactic, keywords []string) int {
@@ -356,56 +293,41 @@ func computeKeywordScore(tactic *db.Tactic, keywords []string) int {
 	return score
 }
 
-func computeGoalAlignmentScore(ctx context.Context, st *store.State, tactic *db.Tactic, goalIDs []string) (int, error) {
-	if len(goalIDs) == 0 {
-		return 0, nil
-	}
+func computeGoalAlignmentScore(ix *dag.Index, tactic *db.Tactic, goalIDs []string) int {
 	score := 0
 	for _, goalID := range goalIDs {
-		goal, err := st.Project.GetNode(ctx, goalID)
-		if err != nil {
-			return 0, err
-		}
+		goal := ix.Node(goalID)
 		if goal == nil {
 			continue
 		}
 		if tactic.Output == goal.Output {
 			score += 20
 		}
-		deps, err := st.Project.GetDependencies(ctx, goalID)
-		if err != nil {
-			return 0, err
-		}
-		for _, d := range deps {
+		for _, d := range ix.Dependencies(goalID) {
 			if d.Output == tactic.Output {
 				score += 10
 				break
 			}
 		}
 	}
-	return score, nil
+	return score
 }
 
 func rankTactics(
-	ctx context.Context,
-	st *store.State,
-	tactics []*db.Tactic,
-	allNodes []*db.Node,
+	ix *dag.Index,
+	policy *db.StatusPolicy,
+	candidates []*db.Tactic,
 	keywords []string,
 	goalIDs []string,
-) ([]rankedTactic, error) {
+) []rankedTactic {
+	cpScores := criticalPathScores(ix, policy)
+	checker := tactics.NewDepChecker(ix.Nodes, policy)
 	var ranked []rankedTactic
-	for _, t := range tactics {
-		deps := computeTacticDependencyStatus(t, allNodes)
-		cp, err := computeCriticalPathScore(ctx, st, t, allNodes)
-		if err != nil {
-			return nil, err
-		}
+	for _, t := range candidates {
+		deps := checker.Check(t)
+		cp := cpScores[t.Output]
 		kw := computeKeywordScore(t, keywords)
-		gs, err := computeGoalAlignmentScore(ctx, st, t, goalIDs)
-		if err != nil {
-			return nil, err
-		}
+		gs := computeGoalAlignmentScore(ix, t, goalIDs)
 
 		total := 0
 		if deps.Ready {
@@ -436,5 +358,5 @@ func rankTactics(
 		return ranked[i].Scores.Total > ranked[j].Scores.Total
 	})
 
-	return ranked, nil
+	return ranked
 }
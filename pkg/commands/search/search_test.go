@@ -0,0 +1,57 @@
+package search
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/db/dbtest"
+	"github.com/go-go-golems/tactician/pkg/defaults"
+)
+
+// syntheticLibrary is the builtin library plus n project tactics, each matching one to three outputs
+// of dbtest.SyntheticGraph(nodes) (complete and open ones alike), some by predicate, some with premises.
+func syntheticLibrary(b *testing.B, n, nodes int) []*db.Tactic {
+	b.Helper()
+	library, err := defaults.Tactics()
+	if err != nil {
+		b.Fatalf("defaults.Tactics: %v", err)
+	}
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < n; i++ {
+		t := &db.Tactic{
+			ID:          fmt.Sprintf("t%04d", i),
+			Type:        []string{"document", "code", "test"}[i%3],
+			Output:      fmt.Sprintf("out_%04d", i),
+			Description: "Follow-up work on the api and its tests",
+			Tags:        []string{"api", "tests"},
+		}
+		for k := 0; k < 1+r.Intn(3); k++ {
+			t.Match = append(t.Match, dbtest.SyntheticNodeID(r.Intn(nodes)))
+		}
+		switch i % 10 {
+		case 0:
+			t.Match = append(t.Match, "type:review")
+		case 1:
+			t.Premises = []string{fmt.Sprintf("out_%04d", r.Intn(n)), dbtest.SyntheticNodeID(r.Intn(nodes))}
+		}
+		library = append(library, t)
+	}
+	return library
+}
+
+func BenchmarkRankTactics10k(b *testing.B) {
+	nodes, edges := dbtest.SyntheticGraph(10000)
+	library := syntheticLibrary(b, 500, len(nodes))
+	policy := db.DefaultStatusPolicy()
+	goals := []string{dbtest.SyntheticNodeID(9999), dbtest.SyntheticNodeID(8000)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix := dag.NewIndex(nodes, edges, policy)
+		if ranked := rankTactics(ix, policy, library, []string{"api", "tests"}, goals); len(ranked) != len(library) {
+			b.Fatalf("expected %d ranked tactics, got %d", len(library), len(ranked))
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package sections
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+const NodeFilterSlug = "node-filter"
+
+// Sort keys accepted by --sort. SortStatus leaves the order to the command
+// (goals ranks by derived status, graph keeps ID order).
+const (
+	SortStatus   = "status"
+	SortPriority = "priority"
+	SortDue      = "due"
+	SortEstimate = "estimate"
+	SortID       = "id"
+)
+
+type NodeFilterSettings struct {
+	Assignee   string   `glazed.parameter:"assignee"`
+	Labels     []string `glazed.parameter:"label"`
+	Priorities []string `glazed.parameter:"priority"`
+	Sort       string   `glazed.parameter:"sort"`
+}
+
+func NewNodeFilterSection() (*schema.SectionImpl, error) {
+	return schema.NewSection(
+		NodeFilterSlug,
+		"Node filters",
+		schema.WithDescription("Filter and sort nodes by planning attributes"),
+		schema.WithFields(
+			fields.New("assignee", fields.TypeString,
+				fields.WithHelp("Only nodes assigned to this person ('me' = $TACTICIAN_USER or $USER)"),
+			),
+			fields.New("label", fields.TypeStringList,
+				fields.WithHelp("Only nodes carrying all of these labels"),
+			),
+			fields.New("priority", fields.TypeChoiceList,
+				fields.WithHelp("Only nodes with one of these priorities"),
+				fields.WithChoices(db.Priorities...),
+			),
+			fields.New("sort", fields.TypeChoice,
+				fields.WithHelp("Sort order"),
+				fields.WithChoices(SortStatus, SortPriority, SortDue, SortEstimate, SortID),
+				fields.WithDefault(SortStatus),
+			),
+		),
+	)
+}
+
+// ResolveAssignee maps "me" to the current user ($TACTICIAN_USER, falling back to $USER).
+func ResolveAssignee(assignee string) string {
+	assignee = strings.TrimSpace(assignee)
+	if assignee != "me" {
+		return assignee
+	}
+	if u := os.Getenv("TACTICIAN_USER"); u != "" {
+		return u
+	}
+	if u := os.Getenv("USER"); u != "" {
+		return u
+	}
+	return assignee
+}
+
+// Active reports whether any filter (not just a sort order) is set.
+func (s *NodeFilterSettings) Active() bool {
+	return strings.TrimSpace(s.Assignee) != "" || len(s.Labels) > 0 || len(s.Priorities) > 0
+}
+
+func (s *NodeFilterSettings) Matches(n *db.Node) bool {
+	if a := ResolveAssignee(s.Assignee); a != "" {
+		if n.Assignee == nil || *n.Assignee != a {
+			return false
+		}
+	}
+	for _, l := range s.Labels {
+		if !n.HasLabel(l) {
+			return false
+		}
+	}
+	if len(s.Priorities) > 0 {
+		if n.Priority == nil {
+			return false
+		}
+		found := false
+		for _, p := range s.Priorities {
+			if *n.Priority == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare orders two nodes by the selected sort key (negative if a sorts first).
+// Nodes missing the attribute sort last; ties (and SortStatus) return 0 so callers
+// can fall back to their own ordering.
+func (s *NodeFilterSettings) Compare(a, b *db.Node) int {
+	switch s.Sort {
+	case SortPriority:
+		return db.PriorityRank(a.Priority) - db.PriorityRank(b.Priority)
+	case SortDue:
+		switch {
+		case a.DueDate == nil && b.DueDate == nil:
+			return 0
+		case a.DueDate == nil:
+			return 1
+		case b.DueDate == nil:
+			return -1
+		}
+		return a.DueDate.Compare(*b.DueDate)
+	case SortEstimate:
+		av, aok := estimateValue(a)
+		bv, bok := estimateValue(b)
+		switch {
+		case !aok && !bok:
+			return 0
+		case !aok:
+			return 1
+		case !bok:
+			return -1
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case SortID:
+		return strings.Compare(a.ID, b.ID)
+	}
+	return 0
+}
+
+func estimateValue(n *db.Node) (float64, bool) {
+	if n.Estimate == nil {
+		return 0, false
+	}
+	v, _, err := db.ParseEstimate(*n.Estimate)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
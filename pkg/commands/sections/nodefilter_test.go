@@ -0,0 +1,61 @@
+package sections
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestNodeFilter_MatchesAndCompare(t *testing.T) {
+	t.Setenv("TACTICIAN_USER", "alice")
+
+	str := func(s string) *string { return &s }
+	due := func(s string) *db.Node {
+		d, err := db.ParseDueDate(s)
+		if err != nil {
+			t.Fatalf("ParseDueDate: %v", err)
+		}
+		return &db.Node{DueDate: &d}
+	}
+
+	a := &db.Node{ID: "a", Assignee: str("alice"), Priority: str(db.PriorityLow), Labels: []string{"api", "backend"}, Estimate: str("1d")}
+	b := &db.Node{ID: "b", Assignee: str("bob"), Priority: str(db.PriorityCritical), Labels: []string{"backend"}, Estimate: str("3")}
+	c := &db.Node{ID: "c"}
+
+	f := &NodeFilterSettings{Assignee: "me", Labels: []string{"backend"}}
+	if !f.Matches(a) || f.Matches(b) || f.Matches(c) {
+		t.Fatalf("--assignee me --label backend should only match a")
+	}
+	f = &NodeFilterSettings{Labels: []string{"backend", "api"}}
+	if !f.Matches(a) || f.Matches(b) {
+		t.Fatalf("--label should require every label")
+	}
+	f = &NodeFilterSettings{Priorities: []string{db.PriorityCritical, db.PriorityHigh}}
+	if f.Matches(a) || !f.Matches(b) || f.Matches(c) {
+		t.Fatalf("--priority should match any of the given priorities")
+	}
+
+	order := func(key string, nodes ...*db.Node) []string {
+		f := &NodeFilterSettings{Sort: key}
+		sort.SliceStable(nodes, func(i, j int) bool { return f.Compare(nodes[i], nodes[j]) < 0 })
+		var ids []string
+		for _, n := range nodes {
+			ids = append(ids, n.ID)
+		}
+		return ids
+	}
+	if got := order(SortPriority, c, a, b); got[0] != "b" || got[1] != "a" || got[2] != "c" {
+		t.Fatalf("priority sort: expected b,a,c, got %v", got)
+	}
+	// 3 points sorts before 1d (8h); unset sorts last.
+	if got := order(SortEstimate, c, a, b); got[0] != "b" || got[1] != "a" || got[2] != "c" {
+		t.Fatalf("estimate sort: expected b,a,c, got %v", got)
+	}
+
+	early, late := due("2026-01-01"), due("2026-06-01")
+	early.ID, late.ID = "early", "late"
+	if got := order(SortDue, c, late, early); got[0] != "early" || got[1] != "late" || got[2] != "c" {
+		t.Fatalf("due sort: expected early,late,c, got %v", got)
+	}
+}
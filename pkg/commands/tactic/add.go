@@ -0,0 +1,138 @@
+package tactic
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type TacticAddCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticAddSettings struct {
+	ID       string `glazed.parameter:"id"`
+	FromFile string `glazed.parameter:"from-file"`
+}
+
+func NewTacticAddCommand() (*TacticAddCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("id", fields.TypeString,
+				fields.WithHelp("Tactic ID (omit with --from-file)"),
+			),
+		),
+		schema.WithFields(
+			fields.New("from-file", fields.TypeString,
+				fields.WithHelp("Read the tactic from a YAML file in the .tactician/tactics/ format"),
+			),
+		),
+		schema.WithFields(definitionFields()...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"add",
+		cmds.WithShort("Add a tactic"),
+		cmds.WithLong("Add a tactic from flags (tactic add <id> --type ... --output ... --subtask id:type:output[:dep1+dep2]) or from a YAML file (--from-file). "+
+			"Tactics with lint errors (unknown depends_on, subtask cycles, missing fields) are refused."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticAddCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &TacticAddCommand{}
+
+func (c *TacticAddCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticAddSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic add settings")
+	}
+	def := &DefinitionSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, def); err != nil {
+		return errors.Wrap(err, "decode tactic add settings")
+	}
+
+	var t *db.Tactic
+	if settings.FromFile != "" {
+		if settings.ID != "" || def.isSet() {
+			return errors.New("--from-file cannot be combined with an id or other tactic flags")
+		}
+		var err error
+		t, err = store.ReadTacticFile(settings.FromFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		if settings.ID == "" {
+			return errors.New("a tactic id (or --from-file) is required")
+		}
+		subtasks, err := parseSubtasks(def.Subtasks)
+		if err != nil {
+			return err
+		}
+		t = &db.Tactic{
+			ID:          settings.ID,
+			Type:        def.Type,
+			Output:      def.Output,
+			Description: def.Description,
+			Tags:        def.Tags,
+			Match:       def.Match,
+			Premises:    def.Premises,
+			Subtasks:    subtasks,
+		}
+	}
+	if !t.IsComposed() {
+		if err := checkDefinition(t); err != nil {
+			return err
+		}
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		existing, err := st.Tactics.GetTactic(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.IsProjectLocal() {
+			return errors.Errorf("tactic already exists: %s (use tactic edit)", t.ID)
+		}
+		if existing != nil {
+			return errors.Errorf("tactic already exists in the %s tactics: %s (use tactic edit to override it in the project)", existing.Source, t.ID)
+		}
+		if err := storeDefinition(ctx, st, t); err != nil {
+			return err
+		}
+		details := "Added tactic: " + t.ID
+		tacticID := t.ID
+		if err := st.Project.LogAction(ctx, "tactic_added", &details, nil, &tacticID); err != nil {
+			return err
+		}
+		st.Dirty = true
+		return nil
+	})
+}
@@ -0,0 +1,227 @@
+package tactic
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// Fields accepted by `tactic edit --clear`.
+var clearableFields = []string{"description", "tags", "match", "premises", "subtasks", "data"}
+
+type TacticEditCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticEditSettings struct {
+	ID             string   `glazed.parameter:"id"`
+	FromFile       string   `glazed.parameter:"from-file"`
+	RemoveSubtasks []string `glazed.parameter:"remove-subtask"`
+	Clear          []string `glazed.parameter:"clear"`
+}
+
+func NewTacticEditCommand() (*TacticEditCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("id", fields.TypeString,
+				fields.WithHelp("Tactic ID to edit"),
+				fields.WithRequired(true),
+			),
+		),
+		schema.WithFields(
+			fields.New("from-file", fields.TypeString,
+				fields.WithHelp("Replace the whole definition with a YAML file (its id must match)"),
+			),
+			fields.New("remove-subtask", fields.TypeStringList,
+				fields.WithHelp("Remove subtasks by id"),
+			),
+			fields.New("clear", fields.TypeChoiceList,
+				fields.WithHelp("Unset fields"),
+				fields.WithChoices(clearableFields...),
+			),
+		),
+		schema.WithFields(definitionFields()...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"edit",
+		cmds.WithShort("Edit a tactic"),
+		cmds.WithLong("Change a tactic's fields. --type, --output and --description replace the value; --tags, --match and --premises replace the list; "+
+			"--subtask adds (or replaces, by id) subtasks; --remove-subtask and --clear remove. --from-file replaces the whole definition. "+
+			"Edits that leave lint errors are refused. Editing a builtin, user or shared tactic copies it into the project's tactics, which then override it."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticEditCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &TacticEditCommand{}
+
+func (c *TacticEditCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticEditSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic edit settings")
+	}
+	def := &DefinitionSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, def); err != nil {
+		return errors.Wrap(err, "decode tactic edit settings")
+	}
+
+	editing := def.isSet() || len(settings.RemoveSubtasks) > 0 || len(settings.Clear) > 0
+	if settings.FromFile != "" && editing {
+		return errors.New("--from-file cannot be combined with other tactic flags")
+	}
+	if settings.FromFile == "" && !editing {
+		return errors.New("nothing to edit (pass --type, --output, --subtask, --clear, --from-file, ...)")
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		t, err := getTacticDefinition(ctx, st, settings.ID)
+		if err != nil {
+			return err
+		}
+
+		var changes []string
+		if !t.IsProjectLocal() {
+			changes = append(changes, "copied from the "+t.Source+" tactics into the project")
+		}
+		if settings.FromFile != "" {
+			replacement, err := store.ReadTacticFile(settings.FromFile)
+			if err != nil {
+				return err
+			}
+			if replacement.ID != t.ID {
+				return errors.Errorf("%s declares tactic %q, not %q", settings.FromFile, replacement.ID, t.ID)
+			}
+			t = replacement
+			changes = append(changes, "replaced from "+settings.FromFile)
+		} else {
+			edits, err := editTactic(t, def, settings)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, edits...)
+		}
+
+		if err := storeDefinition(ctx, st, t); err != nil {
+			return err
+		}
+		details := "Updated tactic " + t.ID + ": " + strings.Join(changes, ", ")
+		tacticID := t.ID
+		if err := st.Project.LogAction(ctx, "tactic_updated", &details, nil, &tacticID); err != nil {
+			return err
+		}
+		st.Dirty = true
+		return nil
+	})
+}
+
+// editTactic applies the flag edits to t and describes them for the log.
+func editTactic(t *db.Tactic, def *DefinitionSettings, settings *TacticEditSettings) ([]string, error) {
+	var changes []string
+
+	for _, name := range settings.Clear {
+		switch name {
+		case "description":
+			t.Description = ""
+		case "tags":
+			t.Tags = nil
+		case "match":
+			t.Match = nil
+		case "premises":
+			t.Premises = nil
+		case "subtasks":
+			t.Subtasks = nil
+		case "data":
+			t.Data = nil
+		}
+		changes = append(changes, "cleared "+name)
+	}
+
+	if def.Type != "" {
+		changes = append(changes, "type: "+t.Type+" -> "+def.Type)
+		t.Type = def.Type
+	}
+	if def.Output != "" {
+		changes = append(changes, "output: "+t.Output+" -> "+def.Output)
+		t.Output = def.Output
+	}
+	if def.Description != "" {
+		t.Description = def.Description
+		changes = append(changes, "description")
+	}
+	if len(def.Tags) > 0 {
+		t.Tags = def.Tags
+		changes = append(changes, "tags="+strings.Join(def.Tags, ","))
+	}
+	if len(def.Match) > 0 {
+		t.Match = def.Match
+		changes = append(changes, "match="+strings.Join(def.Match, ","))
+	}
+	if len(def.Premises) > 0 {
+		t.Premises = def.Premises
+		changes = append(changes, "premises="+strings.Join(def.Premises, ","))
+	}
+
+	for _, id := range settings.RemoveSubtasks {
+		idx := subtaskIndex(t.Subtasks, id)
+		if idx < 0 {
+			return nil, errors.Errorf("tactic %s has no subtask %s", t.ID, id)
+		}
+		t.Subtasks = append(t.Subtasks[:idx], t.Subtasks[idx+1:]...)
+		changes = append(changes, "removed subtask "+id)
+	}
+
+	subtasks, err := parseSubtasks(def.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range subtasks {
+		if idx := subtaskIndex(t.Subtasks, st.ID); idx >= 0 {
+			// Keep the subtask's data; the flag only covers type, output and depends_on.
+			st.Data = t.Subtasks[idx].Data
+			t.Subtasks[idx] = st
+			changes = append(changes, "replaced subtask "+st.ID)
+			continue
+		}
+		t.Subtasks = append(t.Subtasks, st)
+		changes = append(changes, "added subtask "+st.ID)
+	}
+
+	return changes, nil
+}
+
+func subtaskIndex(subtasks []db.TacticSubtask, id string) int {
+	for i, st := range subtasks {
+		if st.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,126 @@
+package tactic
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+)
+
+type TacticLintCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticLintSettings struct {
+	TacticIDs []string `glazed.parameter:"tactic-ids"`
+}
+
+func NewTacticLintCommand() (*TacticLintCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("tactic-ids", fields.TypeStringList,
+				fields.WithHelp("Tactic ID(s) to lint (default: all)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"lint",
+		cmds.WithShort("Check tactic definitions"),
+		cmds.WithLong("Check tactics for missing fields, duplicate subtask ids, depends_on entries naming unknown subtasks, "+
+			"subtask cycles, invalid parameters, and outputs no other tactic matches or requires (info: fine for a final deliverable)."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticLintCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &TacticLintCommand{}
+
+func (c *TacticLintCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticLintSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic lint settings")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	all, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	selected := all
+	if len(settings.TacticIDs) > 0 {
+		selected = nil
+		for _, id := range settings.TacticIDs {
+			t, err := getTactic(ctx, st, id)
+			if err != nil {
+				return err
+			}
+			selected = append(selected, t)
+		}
+	}
+
+	var issues []tactics.LintIssue
+	for _, t := range selected {
+		issues = append(issues, tactics.Lint(t, all)...)
+	}
+	if len(issues) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "No problems found")))
+	}
+
+	for _, issue := range issues {
+		row := types.NewRow(
+			types.MRP("severity", issue.Severity),
+			types.MRP("check", issue.Check),
+			types.MRP("tactic", issue.Tactic),
+			types.MRP("message", issue.Message),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package tactic
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type TacticListCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticListSettings struct {
+	Source string `glazed.parameter:"source"`
+}
+
+func NewTacticListCommand() (*TacticListCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("source", fields.TypeString,
+				fields.WithHelp("Only list tactics from this source (builtin, user, project or a tactics dir)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"list",
+		cmds.WithShort("List all tactics"),
+		cmds.WithLong("List every tactic with its dependencies, subtasks and parameters, and the source it was loaded from: "+
+			"builtin, user (~/.config/tactician/tactics), a dir from tactics.paths or TACTICIAN_TACTICS_PATH, or project (.tactician/tactics). "+
+			"Use `search` to filter and rank by readiness."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticListCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &TacticListCommand{}
+
+func (c *TacticListCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticListSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic list settings")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	all, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	for _, t := range all {
+		if settings.Source != "" && t.Source != settings.Source {
+			continue
+		}
+		row := types.NewRow(
+			types.MRP("id", t.ID),
+			types.MRP("type", t.Type),
+			types.MRP("output", t.Output),
+			types.MRP("match", strings.Join(t.Match, ",")),
+			types.MRP("premises", strings.Join(t.Premises, ",")),
+			types.MRP("subtasks", len(t.Subtasks)),
+			types.MRP("params", paramNames(t.Params)),
+			types.MRP("tags", strings.Join(t.Tags, ",")),
+			types.MRP("description", t.Description),
+			types.MRP("source", t.Source),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
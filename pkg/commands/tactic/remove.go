@@ -0,0 +1,125 @@
+package tactic
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type TacticRemoveCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticRemoveSettings struct {
+	TacticIDs []string `glazed.parameter:"tactic-ids"`
+	Force     bool     `glazed.parameter:"force"`
+}
+
+func NewTacticRemoveCommand() (*TacticRemoveCommand, error) {
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("tactic-ids", fields.TypeStringList,
+				fields.WithHelp("Tactic ID(s) to remove"),
+				fields.WithRequired(true),
+			),
+		),
+		schema.WithFields(
+			fields.New("force", fields.TypeBool,
+				fields.WithHelp("Remove even if nodes were created by the tactic"),
+				fields.WithDefault(false),
+				fields.WithShortFlag("f"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"remove",
+		cmds.WithShort("Remove tactics"),
+		cmds.WithLong("Remove project tactics and their files. Refuses if the project has nodes created by the tactic (unapply them first, or use --force). "+
+			"Builtin, user and shared tactics can't be removed; removing a project tactic that overrides one of them brings it back."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticRemoveCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.BareCommand = &TacticRemoveCommand{}
+
+func (c *TacticRemoveCommand) Run(ctx context.Context, vals *values.Values) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticRemoveSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic remove settings")
+	}
+	if len(settings.TacticIDs) == 0 {
+		return errors.New("at least one tactic id is required")
+	}
+
+	return store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		nodes, err := st.Project.GetAllNodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Validate
+		for _, id := range settings.TacticIDs {
+			t, err := getTactic(ctx, st, id)
+			if err != nil {
+				return err
+			}
+			if !t.IsProjectLocal() {
+				return errors.Errorf("cannot remove %s: it comes from the %s tactics, not the project's", id, t.Source)
+			}
+			if settings.Force {
+				continue
+			}
+			var used []string
+			for _, n := range nodes {
+				if (n.CreatedBy != nil && *n.CreatedBy == "tactic:"+id) || (n.ParentTactic != nil && *n.ParentTactic == id) {
+					used = append(used, n.ID)
+				}
+			}
+			if len(used) > 0 {
+				return errors.Errorf("cannot remove %s: nodes were created by it (%s); unapply it first or use --force", id, strings.Join(used, ", "))
+			}
+		}
+
+		for _, id := range settings.TacticIDs {
+			if err := st.Tactics.DeleteTactic(ctx, id); err != nil {
+				return err
+			}
+			details := "Removed tactic: " + id
+			tacticID := id
+			if err := st.Project.LogAction(ctx, "tactic_removed", &details, nil, &tacticID); err != nil {
+				return err
+			}
+		}
+
+		st.Dirty = true
+		return nil
+	})
+}
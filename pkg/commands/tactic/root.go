@@ -0,0 +1,59 @@
+package tactic
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterTacticCommands(root *cobra.Command) error {
+	tacticCmd := &cobra.Command{
+		Use:     "tactic",
+		Aliases: []string{"tactics"},
+		Short:   "Manage the tactics library",
+	}
+
+	listCmd, err := NewTacticListCommand()
+	if err != nil {
+		return err
+	}
+	showCmd, err := NewTacticShowCommand()
+	if err != nil {
+		return err
+	}
+	addCmd, err := NewTacticAddCommand()
+	if err != nil {
+		return err
+	}
+	editCmd, err := NewTacticEditCommand()
+	if err != nil {
+		return err
+	}
+	removeCmd, err := NewTacticRemoveCommand()
+	if err != nil {
+		return err
+	}
+	lintCmd, err := NewTacticLintCommand()
+	if err != nil {
+		return err
+	}
+	syncCmd, err := NewTacticSyncCommand()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range []cmds.Command{listCmd, showCmd, addCmd, editCmd, removeCmd, lintCmd, syncCmd} {
+		cobraCmd, err := cli.BuildCobraCommandFromCommand(
+			c,
+			cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+		)
+		if err != nil {
+			return err
+		}
+		tacticCmd.AddCommand(cobraCmd)
+	}
+
+	root.AddCommand(tacticCmd)
+	return nil
+}
@@ -0,0 +1,139 @@
+package tactic
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type TacticShowCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticShowSettings struct {
+	TacticIDs []string `glazed.parameter:"tactic-ids"`
+	Mermaid   bool     `glazed.parameter:"mermaid"`
+	Resolved  bool     `glazed.parameter:"resolved"`
+}
+
+func NewTacticShowCommand() (*TacticShowCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("tactic-ids", fields.TypeStringList,
+				fields.WithHelp("Tactic ID(s) to show"),
+				fields.WithRequired(true),
+			),
+		),
+		schema.WithFields(
+			fields.New("mermaid", fields.TypeBool,
+				fields.WithHelp("Output the subtask DAG as a Mermaid diagram"),
+				fields.WithDefault(false),
+			),
+			fields.New("resolved", fields.TypeBool,
+				fields.WithHelp("Show the tactic with extends/include flattened, as apply sees it"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"show",
+		cmds.WithShort("Show one or more tactics"),
+		cmds.WithLong("Show a tactic's dependencies, parameters and subtasks (\"review <- draft\" means review depends on draft). "+
+			"Composed tactics are shown as written (extends, include); --resolved shows what they flatten to. "+
+			"--mermaid draws the resolved subtask DAG with the match and premise outputs feeding it."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticShowCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &TacticShowCommand{}
+
+func (c *TacticShowCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticShowSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic show settings")
+	}
+	if len(settings.TacticIDs) == 0 {
+		return errors.New("at least one tactic id is required")
+	}
+
+	st, err := store.Load(ctx, tSettings.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	for _, id := range settings.TacticIDs {
+		t, err := lookupTactic(ctx, st, id, !settings.Resolved && !settings.Mermaid)
+		if err != nil {
+			return err
+		}
+
+		var row types.Row
+		if settings.Mermaid {
+			row = types.NewRow(
+				types.MRP("id", t.ID),
+				types.MRP("mermaid", buildMermaidTactic(t)),
+			)
+		} else {
+			row = types.NewRow(
+				types.MRP("id", t.ID),
+				types.MRP("type", t.Type),
+				types.MRP("output", t.Output),
+				types.MRP("description", t.Description),
+				types.MRP("tags", strings.Join(t.Tags, ",")),
+				types.MRP("match", strings.Join(t.Match, ",")),
+				types.MRP("premises", strings.Join(t.Premises, ",")),
+				types.MRP("params", paramNames(t.Params)),
+				types.MRP("subtasks", formatSubtasks(t.Subtasks)),
+				types.MRP("source", t.Source),
+			)
+			if t.IsComposed() {
+				row.Set("extends", t.Extends)
+				row.Set("include", formatIncludes(t.Include))
+			}
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
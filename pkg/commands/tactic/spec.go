@@ -0,0 +1,256 @@
+package tactic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+)
+
+// DefinitionSettings are the tactic fields `tactic add` and `tactic edit` take as flags.
+type DefinitionSettings struct {
+	Type        string   `glazed.parameter:"type"`
+	Output      string   `glazed.parameter:"output"`
+	Description string   `glazed.parameter:"description"`
+	Tags        []string `glazed.parameter:"tags"`
+	Match       []string `glazed.parameter:"match"`
+	Premises    []string `glazed.parameter:"premises"`
+	Subtasks    []string `glazed.parameter:"subtask"`
+}
+
+func definitionFields() []*fields.Definition {
+	return []*fields.Definition{
+		fields.New("type", fields.TypeString,
+			fields.WithHelp("Tactic type (e.g. document, code, team_activity)"),
+		),
+		fields.New("output", fields.TypeString,
+			fields.WithHelp("Output artifact the tactic produces"),
+		),
+		fields.New("description", fields.TypeString,
+			fields.WithHelp("Description"),
+		),
+		fields.New("tags", fields.TypeStringList,
+			fields.WithHelp("Tags"),
+		),
+		fields.New("match", fields.TypeStringList,
+			fields.WithHelp("Outputs that must be complete before the tactic is ready"),
+		),
+		fields.New("premises", fields.TypeStringList,
+			fields.WithHelp("Outputs the tactic needs; apply introduces missing ones as placeholders"),
+		),
+		fields.New("subtask", fields.TypeStringList,
+			fields.WithHelp("Subtask as id:type:output[:dep1+dep2] (repeatable)"),
+		),
+	}
+}
+
+// isSet reports whether any definition flag was passed.
+func (s *DefinitionSettings) isSet() bool {
+	return s.Type != "" || s.Output != "" || s.Description != "" ||
+		len(s.Tags) > 0 || len(s.Match) > 0 || len(s.Premises) > 0 || len(s.Subtasks) > 0
+}
+
+// parseSubtask parses `id:type:output[:dep1+dep2]`.
+func parseSubtask(spec string) (db.TacticSubtask, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return db.TacticSubtask{}, errors.Errorf("invalid --subtask %q (want id:type:output[:dep1+dep2])", spec)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	st := db.TacticSubtask{ID: parts[0], Type: parts[1], Output: parts[2]}
+	if st.ID == "" || st.Type == "" || st.Output == "" {
+		return db.TacticSubtask{}, errors.Errorf("invalid --subtask %q (id, type and output are required)", spec)
+	}
+	if len(parts) == 4 && parts[3] != "" {
+		for _, dep := range strings.Split(parts[3], "+") {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				st.DependsOn = append(st.DependsOn, dep)
+			}
+		}
+	}
+	return st, nil
+}
+
+func parseSubtasks(specs []string) ([]db.TacticSubtask, error) {
+	var ret []db.TacticSubtask
+	for _, spec := range specs {
+		st, err := parseSubtask(spec)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, st)
+	}
+	return ret, nil
+}
+
+// checkDefinition refuses a tactic with lint errors; warnings and infos are left to `tactic lint`.
+func checkDefinition(t *db.Tactic) error {
+	var msgs []string
+	for _, issue := range tactics.Lint(t, nil) {
+		if issue.Severity == tactics.SeverityError {
+			msgs = append(msgs, issue.Message)
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.Errorf("invalid tactic %s: %s", t.ID, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// storeDefinition adds or replaces t in the project's tactics and checks the tactic it resolves to (composed tactics only
+// get their type, output, ... from the tactics they extend and include).
+func storeDefinition(ctx context.Context, st *store.State, t *db.Tactic) error {
+	t.Source = db.TacticSourceProject
+	if err := st.Tactics.AddTactic(ctx, t); err != nil {
+		return err
+	}
+	resolved, err := st.Tactics.GetTactic(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	return checkDefinition(resolved)
+}
+
+// getTactic loads a tactic, resolved, or fails with "tactic not found".
+func getTactic(ctx context.Context, st *store.State, id string) (*db.Tactic, error) {
+	return lookupTactic(ctx, st, id, false)
+}
+
+// getTacticDefinition loads a tactic as written, with extends/include, or fails with "tactic not found".
+func getTacticDefinition(ctx context.Context, st *store.State, id string) (*db.Tactic, error) {
+	return lookupTactic(ctx, st, id, true)
+}
+
+func lookupTactic(ctx context.Context, st *store.State, id string, definition bool) (*db.Tactic, error) {
+	get := st.Tactics.GetTactic
+	if definition {
+		get = st.Tactics.GetTacticDefinition
+	}
+	t, err := get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.Errorf("tactic not found: %s", id)
+	}
+	return t, nil
+}
+
+// formatIncludes renders includes compactly, e.g. "review as qa <- draft".
+func formatIncludes(includes []db.TacticInclude) string {
+	var parts []string
+	for _, inc := range includes {
+		s := inc.Tactic
+		if inc.Prefix != "" {
+			s += " as " + inc.Prefix
+		}
+		if len(inc.DependsOn) > 0 {
+			s += " <- " + strings.Join(inc.DependsOn, ",")
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatSubtasks renders subtasks compactly, e.g. "draft; review? <- draft; migrate [when data_model]"
+// (? marks optional subtasks).
+func formatSubtasks(subtasks []db.TacticSubtask) string {
+	var parts []string
+	for _, st := range subtasks {
+		s := st.ID
+		if st.Optional {
+			s += "?"
+		}
+		if len(st.DependsOn) > 0 {
+			s += " <- " + strings.Join(st.DependsOn, ",")
+		}
+		if st.When != "" {
+			s += " [when " + st.When + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func paramNames(params []db.TacticParam) string {
+	var names []string
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+var mermaidSanitizeRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func mermaidID(id string) string {
+	return mermaidSanitizeRe.ReplaceAllString(id, "_")
+}
+
+func mermaidText(s string) string {
+	return strings.ReplaceAll(s, "\"", "\\\"")
+}
+
+// buildMermaidTactic draws the subtask DAG (or the single output node) with the
+// match and premise outputs it consumes feeding the subtasks that start the work.
+func buildMermaidTactic(t *db.Tactic) string {
+	sb := strings.Builder{}
+	sb.WriteString("graph TD\n")
+
+	subtasks := t.Subtasks
+	if len(subtasks) == 0 {
+		subtasks = []db.TacticSubtask{{ID: t.Output, Type: t.Type, Output: t.Output}}
+	}
+	for _, st := range subtasks {
+		label := st.ID
+		if st.Output != st.ID {
+			label += "<br/>" + st.Output
+		}
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", mermaidID(st.ID), mermaidText(label)))
+	}
+
+	var inputs []string
+	for _, m := range t.Match {
+		inputs = append(inputs, m)
+		sb.WriteString(fmt.Sprintf("  in_%s([\"%s\"]):::match\n", mermaidID(m), mermaidText(m)))
+	}
+	for _, p := range t.Premises {
+		if contains(t.Match, p) {
+			continue
+		}
+		inputs = append(inputs, p)
+		sb.WriteString(fmt.Sprintf("  in_%s([\"%s\"]):::premise\n", mermaidID(p), mermaidText(p)))
+	}
+	sb.WriteString("  classDef match fill:#e3f2fd,stroke:#1e88e5\n")
+	sb.WriteString("  classDef premise fill:#fff8e1,stroke:#f9a825,stroke-dasharray: 4 2\n")
+
+	for _, st := range subtasks {
+		for _, dep := range st.DependsOn {
+			sb.WriteString(fmt.Sprintf("  %s --> %s\n", mermaidID(dep), mermaidID(st.ID)))
+		}
+	}
+	for _, in := range inputs {
+		for _, st := range subtasks {
+			if len(st.DependsOn) == 0 {
+				sb.WriteString(fmt.Sprintf("  in_%s -.-> %s\n", mermaidID(in), mermaidID(st.ID)))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
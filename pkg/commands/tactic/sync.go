@@ -0,0 +1,183 @@
+package tactic
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/defaults"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type TacticSyncCommand struct {
+	*cmds.CommandDefinition
+}
+
+type TacticSyncSettings struct {
+	TacticIDs []string `glazed.parameter:"tactic-ids"`
+	DryRun    bool     `glazed.parameter:"dry-run"`
+	Force     bool     `glazed.parameter:"force"`
+	Diff      bool     `glazed.parameter:"diff"`
+}
+
+func NewTacticSyncCommand() (*TacticSyncCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("tactic-ids", fields.TypeStringList,
+				fields.WithHelp("Tactic ID(s) to sync (default: all)"),
+			),
+		),
+		schema.WithFields(
+			fields.New("dry-run", fields.TypeBool,
+				fields.WithHelp("Report what would change without saving"),
+				fields.WithDefault(false),
+			),
+			fields.New("force", fields.TypeBool,
+				fields.WithShortFlag("f"),
+				fields.WithHelp("Replace edited and conflicting copies with the builtin version (local edits are lost)"),
+				fields.WithDefault(false),
+			),
+			fields.New("diff", fields.TypeBool,
+				fields.WithHelp("Add a unified diff from the project's copy to the builtin version"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"sync",
+		cmds.WithShort("Update the project's copies of the builtin tactics"),
+		cmds.WithLong("Compare the builtin tactics of this tactician with the versions seeded into the project (init --seed, recorded in "+
+			"seeded-tactics.yaml) and the project's copies. Untouched copies are updated, edited ones are kept (modified), and copies where "+
+			"both changed are reported as conflicts; both are kept unless --force. Builtin tactics the project hasn't seeded yet are added; "+
+			"copies the project deleted stay deleted. Up-to-date tactics are not listed."),
+		cmds.WithSchema(s),
+	)
+
+	return &TacticSyncCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &TacticSyncCommand{}
+
+func (c *TacticSyncCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &TacticSyncSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode tactic sync settings")
+	}
+
+	builtin, err := defaults.Tactics()
+	if err != nil {
+		return err
+	}
+	opts := store.SyncOptions{IDs: settings.TacticIDs, Force: settings.Force, Diff: settings.Diff}
+
+	var results []store.TacticSync
+	if settings.DryRun {
+		st, err := store.Load(ctx, tSettings.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = st.Close() }()
+		if results, err = st.SyncTactics(ctx, builtin, opts); err != nil {
+			return err
+		}
+	} else {
+		err := store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+			var err error
+			results, err = st.SyncTactics(ctx, builtin, opts)
+			if err != nil {
+				return err
+			}
+			var changed []string
+			for _, r := range results {
+				if r.Changed {
+					changed = append(changed, r.ID+" ("+r.Status+")")
+				}
+			}
+			if len(changed) == 0 {
+				return nil
+			}
+			details := "Synced builtin tactics: " + strings.Join(changed, ", ")
+			return st.Project.LogAction(ctx, "tactics_synced", &details, nil, nil)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(results) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "No seeded tactics: the project uses the builtin tactics directly")))
+	}
+	rows := 0
+	for _, r := range results {
+		if r.Status == store.SyncUpToDate && len(settings.TacticIDs) == 0 {
+			continue
+		}
+		row := types.NewRow(
+			types.MRP("id", r.ID),
+			types.MRP("status", r.Status),
+			types.MRP("action", syncAction(r)),
+		)
+		if settings.Diff {
+			row.Set("diff", r.Diff)
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+		rows++
+	}
+	if rows == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "All seeded tactics are up to date")))
+	}
+	return nil
+}
+
+// syncAction says what sync does with a tactic's copy.
+func syncAction(r store.TacticSync) string {
+	switch {
+	case r.Changed && (r.Status == store.SyncModified || r.Status == store.SyncConflict):
+		return "overwrite"
+	case r.Changed && r.Status == store.SyncAdded:
+		return "add"
+	case r.Changed:
+		return "update"
+	case r.Status == store.SyncUpToDate:
+		return ""
+	default:
+		return "keep"
+	}
+}
@@ -0,0 +1,178 @@
+package unapply
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+	"github.com/pkg/errors"
+)
+
+type UnapplyCommand struct {
+	*cmds.CommandDefinition
+}
+
+type UnapplySettings struct {
+	Ref    string `glazed.parameter:"ref"`
+	Force  bool   `glazed.parameter:"force"`
+	DryRun bool   `glazed.parameter:"dry-run"`
+}
+
+func NewUnapplyCommand() (*UnapplyCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithArguments(
+			fields.New("ref", fields.TypeString,
+				fields.WithHelp("Tactic ID or instance ID (e.g. crud[resource=users]) to roll back"),
+				fields.WithRequired(true),
+			),
+		),
+		schema.WithFields(
+			fields.New("force", fields.TypeBool,
+				fields.WithHelp("Remove even if nodes are no longer pending or gained new dependents"),
+				fields.WithDefault(false),
+				fields.WithShortFlag("f"),
+			),
+			fields.New("dry-run", fields.TypeBool,
+				fields.WithHelp("Show the nodes and edges that would be removed without saving"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"unapply",
+		cmds.WithShort("Roll back an applied tactic"),
+		cmds.WithLong("Remove every node and edge created by applying a tactic. Pass the instance ID to pick one application of a parameterized tactic.\n\n"+
+			"Unapply refuses if any of those nodes is no longer pending (someone started, completed or closed it) or other nodes now depend on them; --force removes them anyway. "+
+			"--dry-run lists what would be removed."),
+		cmds.WithSchema(s),
+	)
+
+	return &UnapplyCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &UnapplyCommand{}
+
+func (c *UnapplyCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &UnapplySettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode unapply settings")
+	}
+
+	if settings.DryRun {
+		st, err := store.Load(ctx, tSettings.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = st.Close() }()
+
+		removal, err := planUnapply(ctx, st, settings.Ref)
+		if err != nil {
+			return err
+		}
+		return emitRemoval(ctx, gp, removal)
+	}
+
+	var removed *tactics.Removal
+	err := store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		removal, err := planUnapply(ctx, st, settings.Ref)
+		if err != nil {
+			return err
+		}
+		if len(removal.Problems) > 0 && !settings.Force {
+			return errors.Errorf("cannot unapply %s: %s; use --force", removal.Instance, strings.Join(removal.Problems, ", "))
+		}
+		if err := removal.Execute(ctx, st.Project); err != nil {
+			return err
+		}
+		removed = removal
+		st.Dirty = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return emitRemoval(ctx, gp, removed)
+}
+
+func planUnapply(ctx context.Context, st *store.State, ref string) (*tactics.Removal, error) {
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tactics.PlanUnapply(ref, nodes, edges)
+}
+
+// emitRemoval writes one row per removed node and edge, mirroring apply's rows.
+func emitRemoval(ctx context.Context, gp middlewares.Processor, r *tactics.Removal) error {
+	for _, n := range r.Nodes {
+		row := types.NewRow(
+			types.MRP("change", "remove_node"),
+			types.MRP("instance", r.Instance),
+			types.MRP("id", n.ID),
+			types.MRP("type", n.Type),
+			types.MRP("output", n.Output),
+			types.MRP("status", n.Status),
+			types.MRP("source", ""),
+			types.MRP("target", ""),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	for _, e := range r.Edges {
+		row := types.NewRow(
+			types.MRP("change", "remove_edge"),
+			types.MRP("instance", r.Instance),
+			types.MRP("id", ""),
+			types.MRP("type", ""),
+			types.MRP("output", ""),
+			types.MRP("status", ""),
+			types.MRP("source", e.SourceNodeID),
+			types.MRP("target", e.TargetNodeID),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
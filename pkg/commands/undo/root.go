@@ -0,0 +1,37 @@
+package undo
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterUndoCommands(root *cobra.Command) error {
+	undoCmd, err := NewUndoCommand()
+	if err != nil {
+		return err
+	}
+	cobraUndoCmd, err := cli.BuildCobraCommandFromCommand(
+		undoCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	root.AddCommand(cobraUndoCmd)
+
+	redoCmd, err := NewRedoCommand()
+	if err != nil {
+		return err
+	}
+	cobraRedoCmd, err := cli.BuildCobraCommandFromCommand(
+		redoCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	root.AddCommand(cobraRedoCmd)
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package undo
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type UndoCommand struct {
+	*cmds.CommandDefinition
+	redo bool
+}
+
+type UndoSettings struct {
+	Count int  `glazed.parameter:"count"`
+	Force bool `glazed.parameter:"force"`
+}
+
+func NewUndoCommand() (*UndoCommand, error) {
+	return newUndoRedoCommand(false)
+}
+
+func NewRedoCommand() (*UndoCommand, error) {
+	return newUndoRedoCommand(true)
+}
+
+func newUndoRedoCommand(redo bool) (*UndoCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("count", fields.TypeInteger,
+				fields.WithHelp("Number of changes to revert / re-apply"),
+				fields.WithDefault(1),
+				fields.WithShortFlag("n"),
+			),
+			fields.New("force", fields.TypeBool,
+				fields.WithHelp("Proceed even if the affected nodes or edges changed since"),
+				fields.WithDefault(false),
+				fields.WithShortFlag("f"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	var cmdDef *cmds.CommandDefinition
+	if redo {
+		cmdDef = cmds.NewCommandDefinition(
+			"redo",
+			cmds.WithShort("Re-apply changes reverted by undo"),
+			cmds.WithLong("Re-applies the most recently undone change(s). Any new change after an undo discards the redo history."),
+			cmds.WithSchema(s),
+		)
+	} else {
+		cmdDef = cmds.NewCommandDefinition(
+			"undo",
+			cmds.WithShort("Revert the most recent change(s) to the project"),
+			cmds.WithLong("Reverts the nodes, edges and project meta changed by the most recent mutating command(s), using the change set recorded in the action log. Changes to the tactics library (tactic add/edit/remove/sync) are not recorded; undo lists the ones it passes over as skipped."),
+			cmds.WithSchema(s),
+		)
+	}
+
+	return &UndoCommand{CommandDefinition: cmdDef, redo: redo}, nil
+}
+
+var _ cmds.GlazeCommand = &UndoCommand{}
+
+func (c *UndoCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &UndoSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode undo settings")
+	}
+	if settings.Count < 1 {
+		return errors.New("--count must be at least 1")
+	}
+
+	var results []*store.UndoResult
+	err := store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+		results = nil
+		for i := 0; i < settings.Count; i++ {
+			var r *store.UndoResult
+			var err error
+			if c.redo {
+				r, err = st.Redo(ctx, settings.Force)
+			} else {
+				r, err = st.Undo(ctx, settings.Force)
+			}
+			if err != nil {
+				// Undoing fewer changes than asked for is fine once at least one went through.
+				if len(results) > 0 && (errors.Is(err, store.ErrNothingToUndo) || errors.Is(err, store.ErrNothingToRedo)) {
+					return nil
+				}
+				return err
+			}
+			results = append(results, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	status := "undone"
+	if c.redo {
+		status = "redone"
+	}
+	for _, r := range results {
+		// Tactic library changes can't be undone; say so instead of quietly reverting an older change.
+		for _, e := range r.Skipped {
+			if err := gp.AddRow(ctx, entryRow("skipped (not undoable)", e, 0, 0)); err != nil {
+				return err
+			}
+		}
+		if err := gp.AddRow(ctx, entryRow(status, r.Entry, r.NodesChanged, r.EdgesChanged)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func entryRow(status string, e db.ActionLogEntry, nodesChanged, edgesChanged int) types.Row {
+	var nodeID, tacticID any
+	if e.NodeID != nil {
+		nodeID = *e.NodeID
+	}
+	if e.TacticID != nil {
+		tacticID = *e.TacticID
+	}
+	return types.NewRow(
+		types.MRP("status", status),
+		types.MRP("action", e.Action),
+		types.MRP("timestamp", e.Timestamp),
+		types.MRP("node_id", nodeID),
+		types.MRP("tactic_id", tacticID),
+		types.MRP("nodes_changed", nodesChanged),
+		types.MRP("edges_changed", edgesChanged),
+	)
+}
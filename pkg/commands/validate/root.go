@@ -0,0 +1,24 @@
+package validate
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/tactician/pkg/commands/common"
+	"github.com/spf13/cobra"
+)
+
+func RegisterValidateCommands(root *cobra.Command) error {
+	validateCmd, err := NewValidateCommand()
+	if err != nil {
+		return err
+	}
+	cobraCmd, err := cli.BuildCobraCommandFromCommand(
+		validateCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{AppName: common.AppName}),
+	)
+	if err != nil {
+		return err
+	}
+	cobraCmd.Aliases = []string{"doctor"}
+	root.AddCommand(cobraCmd)
+	return nil
+}
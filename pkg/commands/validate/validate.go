@@ -0,0 +1,129 @@
+package validate
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/tactician/pkg/commands/sections"
+	"github.com/go-go-golems/tactician/pkg/doctor"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/pkg/errors"
+)
+
+type ValidateCommand struct {
+	*cmds.CommandDefinition
+}
+
+type ValidateSettings struct {
+	Fix bool `glazed.parameter:"fix"`
+}
+
+func NewValidateCommand() (*ValidateCommand, error) {
+	glazedSection, err := schema.NewGlazedSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tacticianSection, err := sections.NewTacticianSection()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSection, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithDescription("Default parameters"),
+		schema.WithFields(
+			fields.New("fix", fields.TypeBool,
+				fields.WithHelp("Apply the safe repairs and save"),
+				fields.WithDefault(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema.NewSchema(schema.WithSections(glazedSection, tacticianSection, defaultSection))
+
+	cmdDef := cmds.NewCommandDefinition(
+		"validate",
+		cmds.WithShort("Check .tactician/ for problems (alias: doctor)"),
+		cmds.WithLong("Loads the project and tactics and reports problems with a severity and a suggested fix: graph integrity, tactic dependencies nothing produces, broken subtask depends_on, duplicate tactic ids, inconsistent node fields and dangling references. --fix applies the safe repairs."),
+		cmds.WithSchema(s),
+	)
+
+	return &ValidateCommand{CommandDefinition: cmdDef}, nil
+}
+
+var _ cmds.GlazeCommand = &ValidateCommand{}
+
+func (c *ValidateCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	vals *values.Values,
+	gp middlewares.Processor,
+) error {
+	tSettings := &sections.TacticianSettings{}
+	if err := values.DecodeSectionInto(vals, sections.TacticianSlug, tSettings); err != nil {
+		return errors.Wrap(err, "decode tactician settings")
+	}
+
+	settings := &ValidateSettings{}
+	if err := values.DecodeSectionInto(vals, schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "decode validate settings")
+	}
+
+	var findings []*doctor.Finding
+	if settings.Fix {
+		err := store.Update(ctx, tSettings.Dir, func(ctx context.Context, st *store.State) error {
+			var err error
+			findings, err = doctor.Check(ctx, st)
+			if err != nil {
+				return err
+			}
+			_, err = doctor.Fix(ctx, st, findings)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		st, err := store.Load(ctx, tSettings.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = st.Close() }()
+		findings, err = doctor.Check(ctx, st)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(findings) == 0 {
+		return gp.AddRow(ctx, types.NewRow(types.MRP("message", "No problems found")))
+	}
+
+	for _, f := range findings {
+		row := types.NewRow(
+			types.MRP("severity", string(f.Severity)),
+			types.MRP("check", f.Check),
+			types.MRP("subject", f.Subject),
+			types.MRP("message", f.Message),
+			types.MRP("suggestion", f.Suggestion),
+			types.MRP("fixable", f.Fixable()),
+		)
+		if settings.Fix {
+			row.Set("fixed", f.Fixable())
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
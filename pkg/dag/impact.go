@@ -0,0 +1,96 @@
+package dag
+
+import (
+	"sort"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+// Descendants returns the IDs of the nodes that depend on ids, directly or transitively, sorted.
+// ids themselves are only included when they depend on each other.
+func Descendants(ids []string, edges []db.Edge) []string {
+	succs := map[string][]string{}
+	for _, e := range edges {
+		succs[e.SourceNodeID] = append(succs[e.SourceNodeID], e.TargetNodeID)
+	}
+	return reach(ids, succs)
+}
+
+// Ancestors returns the IDs of the nodes ids depend on, directly or transitively, sorted.
+func Ancestors(ids []string, edges []db.Edge) []string {
+	preds := map[string][]string{}
+	for _, e := range edges {
+		preds[e.TargetNodeID] = append(preds[e.TargetNodeID], e.SourceNodeID)
+	}
+	return reach(ids, preds)
+}
+
+func reach(ids []string, next map[string][]string) []string {
+	seen := map[string]bool{}
+	queue := append([]string{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, n := range next[id] {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	ret := make([]string, 0, len(seen))
+	for id := range seen {
+		ret = append(ret, id)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// WithStatus returns nodes with the nodes in ids replaced by copies in the given status; the
+// others are shared.
+func WithStatus(nodes []*db.Node, ids []string, status string) []*db.Node {
+	set := map[string]bool{}
+	for _, id := range ids {
+		set[id] = true
+	}
+	ret := make([]*db.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if set[n.ID] {
+			cp := *n
+			cp.Status = status
+			n = &cp
+		}
+		ret = append(ret, n)
+	}
+	return ret
+}
+
+// DeriveStatuses returns the status goals shows for every node (policy.Derive over its
+// dependencies), by ID.
+func DeriveStatuses(nodes []*db.Node, edges []db.Edge, policy *db.StatusPolicy) map[string]string {
+	return NewIndex(nodes, edges, policy).statuses
+}
+
+// DependencyOrder sorts ids so that every node comes after the nodes it depends on among ids
+// (ties by ID). If they form a cycle, ids are simply sorted by ID.
+func DependencyOrder(ids []string, edges []db.Edge) []string {
+	set := map[string]bool{}
+	for _, id := range ids {
+		set[id] = true
+	}
+	preds := map[string][]string{}
+	succs := map[string][]string{}
+	for _, e := range edges {
+		if set[e.SourceNodeID] && set[e.TargetNodeID] {
+			preds[e.TargetNodeID] = append(preds[e.TargetNodeID], e.SourceNodeID)
+			succs[e.SourceNodeID] = append(succs[e.SourceNodeID], e.TargetNodeID)
+		}
+	}
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	order, err := topoOrder(sorted, preds, succs)
+	if err == nil {
+		return order
+	}
+	return sorted
+}
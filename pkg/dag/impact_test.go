@@ -0,0 +1,39 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestImpactHelpers(t *testing.T) {
+	nodes := []*db.Node{
+		{ID: "a", Status: db.StatusPending},
+		{ID: "b", Status: db.StatusPending},
+		{ID: "c", Status: db.StatusPending},
+		{ID: "d", Status: db.StatusInProgress},
+		{ID: "e", Status: db.StatusComplete},
+	}
+	edges := edgesOf([2]string{"a", "c"}, [2]string{"b", "c"}, [2]string{"c", "d"}, [2]string{"e", "b"})
+
+	if got, want := Descendants([]string{"a"}, edges), []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Descendants = %v, want %v", got, want)
+	}
+	if got, want := Ancestors([]string{"d"}, edges), []string{"a", "b", "c", "e"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ancestors = %v, want %v", got, want)
+	}
+	if got, want := DependencyOrder([]string{"d", "c", "b"}, edges), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("DependencyOrder = %v, want %v", got, want)
+	}
+
+	policy := db.DefaultStatusPolicy()
+	before := DeriveStatuses(nodes, edges, policy)
+	after := DeriveStatuses(WithStatus(nodes, []string{"a", "b"}, db.StatusComplete), edges, policy)
+	if before["c"] != db.DerivedBlocked || after["c"] != db.DerivedReady || after["d"] != db.DerivedBlocked {
+		t.Fatalf("unexpected statuses before %v / after %v", before, after)
+	}
+	if nodes[0].Status != db.StatusPending {
+		t.Fatalf("WithStatus must not modify the original nodes")
+	}
+}
@@ -0,0 +1,106 @@
+package dag
+
+import (
+	"sort"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+// Index is an in-memory view of the project graph: nodes by ID, adjacency lists in both
+// directions and derived statuses. Commands build it once from GetAllNodes and GetEdges instead
+// of querying the database for every node.
+type Index struct {
+	Nodes []*db.Node
+	Edges []db.Edge
+
+	policy     *db.StatusPolicy
+	byID       map[string]*db.Node
+	deps       map[string][]*db.Node
+	dependents map[string][]*db.Node
+	statuses   map[string]string
+}
+
+// NewIndex indexes nodes and edges. Edges to or from unknown nodes are ignored; adjacency lists
+// keep the order of edges.
+func NewIndex(nodes []*db.Node, edges []db.Edge, policy *db.StatusPolicy) *Index {
+	if policy == nil {
+		policy = db.DefaultStatusPolicy()
+	}
+	ix := &Index{
+		Nodes:      nodes,
+		Edges:      edges,
+		policy:     policy,
+		byID:       make(map[string]*db.Node, len(nodes)),
+		deps:       map[string][]*db.Node{},
+		dependents: map[string][]*db.Node{},
+		statuses:   make(map[string]string, len(nodes)),
+	}
+	for _, n := range nodes {
+		ix.byID[n.ID] = n
+	}
+	for _, e := range edges {
+		src, dst := ix.byID[e.SourceNodeID], ix.byID[e.TargetNodeID]
+		if src == nil || dst == nil {
+			continue
+		}
+		ix.deps[dst.ID] = append(ix.deps[dst.ID], src)
+		ix.dependents[src.ID] = append(ix.dependents[src.ID], dst)
+	}
+	for _, n := range nodes {
+		ix.statuses[n.ID] = policy.Derive(n, ix.deps[n.ID])
+	}
+	return ix
+}
+
+// Node returns the node with the given ID, or nil.
+func (ix *Index) Node(id string) *db.Node {
+	return ix.byID[id]
+}
+
+// Dependencies returns the nodes id depends on (like ProjectDB.GetDependencies).
+func (ix *Index) Dependencies(id string) []*db.Node {
+	return ix.deps[id]
+}
+
+// Dependents returns the nodes that depend on id (like ProjectDB.GetBlockedBy).
+func (ix *Index) Dependents(id string) []*db.Node {
+	return ix.dependents[id]
+}
+
+// Status returns the status goals shows for id (see StatusPolicy.Derive).
+func (ix *Index) Status(id string) string {
+	return ix.statuses[id]
+}
+
+// Blockers returns the dependencies of id whose status doesn't satisfy it yet.
+func (ix *Index) Blockers(id string) []*db.Node {
+	var ret []*db.Node
+	for _, d := range ix.deps[id] {
+		if !ix.policy.Satisfies(d.Status) {
+			ret = append(ret, d)
+		}
+	}
+	return ret
+}
+
+// Descendants returns the IDs of the nodes that depend on id, directly or transitively, sorted.
+func (ix *Index) Descendants(id string) []string {
+	seen := map[string]bool{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range ix.dependents[cur] {
+			if !seen[n.ID] {
+				seen[n.ID] = true
+				queue = append(queue, n.ID)
+			}
+		}
+	}
+	ret := make([]string, 0, len(seen))
+	for id := range seen {
+		ret = append(ret, id)
+	}
+	sort.Strings(ret)
+	return ret
+}
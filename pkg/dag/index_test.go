@@ -0,0 +1,92 @@
+package dag
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/db/dbtest"
+)
+
+func TestIndex(t *testing.T) {
+	nodes := []*db.Node{
+		{ID: "a", Output: "spec", Status: db.StatusComplete},
+		{ID: "b", Output: "code", Status: db.StatusPending},
+		{ID: "c", Output: "tests", Status: db.StatusPending},
+		{ID: "d", Output: "release", Status: db.StatusOnHold},
+	}
+	edges := edgesOf([2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "c"}, [2]string{"c", "d"}, [2]string{"x", "d"})
+	ix := NewIndex(nodes, edges, nil)
+
+	ids := func(nodes []*db.Node) []string {
+		var ret []string
+		for _, n := range nodes {
+			ret = append(ret, n.ID)
+		}
+		return ret
+	}
+	if got := ids(ix.Dependencies("c")); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("Dependencies(c) = %v", got)
+	}
+	if got := ids(ix.Dependents("a")); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Fatalf("Dependents(a) = %v", got)
+	}
+	if got := ids(ix.Dependencies("d")); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Fatalf("expected the edge from the unknown node x to be ignored, got %v", got)
+	}
+	if got := ids(ix.Blockers("c")); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("Blockers(c) = %v", got)
+	}
+	if got := ix.Descendants("b"); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Fatalf("Descendants(b) = %v", got)
+	}
+	want := map[string]string{"a": db.StatusComplete, "b": db.DerivedReady, "c": db.DerivedBlocked, "d": db.StatusOnHold}
+	for id, status := range want {
+		if ix.Status(id) != status {
+			t.Fatalf("Status(%s) = %s, want %s", id, ix.Status(id), status)
+		}
+	}
+	if ix.Node("x") != nil || ix.Node("a") != nodes[0] {
+		t.Fatalf("unexpected Node lookups")
+	}
+}
+
+func BenchmarkNewIndex10k(b *testing.B) {
+	nodes, edges := dbtest.SyntheticGraph(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix := NewIndex(nodes, edges, nil)
+		for _, n := range nodes {
+			_ = ix.Status(n.ID)
+			_ = ix.Dependents(n.ID)
+		}
+	}
+}
+
+func BenchmarkComputeSchedule10k(b *testing.B) {
+	nodes, edges := dbtest.SyntheticGraph(10000)
+	for _, workers := range []int{0, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := ComputeSchedule(nodes, edges, ScheduleOptions{Workers: workers, HoursPerPoint: 8, DefaultHours: 8}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindCycles10k(b *testing.B) {
+	nodes, edges := dbtest.SyntheticGraph(10000)
+	var ids []string
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if cycles := FindCycles(ids, edges); len(cycles) != 0 {
+			b.Fatalf("unexpected cycles: %v", cycles)
+		}
+	}
+}
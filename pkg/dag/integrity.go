@@ -0,0 +1,234 @@
+// Package dag implements graph analyses over the project DAG (nodes and edges as stored in db).
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+type IssueKind string
+
+const (
+	IssueCycle           IssueKind = "cycle"
+	IssueDanglingEdge    IssueKind = "dangling_edge"
+	IssueDuplicateOutput IssueKind = "duplicate_output"
+)
+
+// Issue is one integrity violation. Nodes lists the node ids involved; for cycles it is the cycle path,
+// starting and ending at the same node.
+type Issue struct {
+	Kind    IssueKind
+	Message string
+	Nodes   []string
+	// Edge is set for dangling edges.
+	Edge *db.Edge
+}
+
+// CheckIntegrity reports cycles, edges whose endpoints are not nodes, and outputs produced by more than
+// one node. Results are ordered by kind, then deterministically within a kind.
+func CheckIntegrity(nodes []*db.Node, edges []db.Edge) []Issue {
+	var issues []Issue
+
+	known := map[string]bool{}
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		known[n.ID] = true
+		ids = append(ids, n.ID)
+	}
+
+	var valid []db.Edge
+	for _, e := range edges {
+		var missing []string
+		if !known[e.SourceNodeID] {
+			missing = append(missing, e.SourceNodeID)
+		}
+		if !known[e.TargetNodeID] && e.TargetNodeID != e.SourceNodeID {
+			missing = append(missing, e.TargetNodeID)
+		}
+		if len(missing) > 0 {
+			e := e
+			issues = append(issues, Issue{
+				Kind:    IssueDanglingEdge,
+				Message: fmt.Sprintf("edge %s -> %s references missing node(s): %s", e.SourceNodeID, e.TargetNodeID, strings.Join(missing, ", ")),
+				Nodes:   missing,
+				Edge:    &e,
+			})
+			continue
+		}
+		valid = append(valid, e)
+	}
+
+	for _, cycle := range FindCycles(ids, valid) {
+		issues = append(issues, Issue{
+			Kind:    IssueCycle,
+			Message: "cycle: " + strings.Join(cycle, " -> "),
+			Nodes:   cycle,
+		})
+	}
+
+	byOutput := map[string][]string{}
+	for _, n := range nodes {
+		byOutput[n.Output] = append(byOutput[n.Output], n.ID)
+	}
+	outputs := make([]string, 0, len(byOutput))
+	for output, ids := range byOutput {
+		if len(ids) > 1 {
+			outputs = append(outputs, output)
+		}
+	}
+	sort.Strings(outputs)
+	for _, output := range outputs {
+		ids := byOutput[output]
+		sort.Strings(ids)
+		issues = append(issues, Issue{
+			Kind:    IssueDuplicateOutput,
+			Message: fmt.Sprintf("output %q is produced by %d nodes: %s", output, len(ids), strings.Join(ids, ", ")),
+			Nodes:   ids,
+		})
+	}
+
+	return issues
+}
+
+// FindCycles returns one concrete cycle per strongly connected component that contains a cycle
+// (including self-loops). Each cycle starts and ends at the component's smallest node id.
+func FindCycles(nodeIDs []string, edges []db.Edge) [][]string {
+	out := map[string][]string{}
+	for _, e := range edges {
+		out[e.SourceNodeID] = append(out[e.SourceNodeID], e.TargetNodeID)
+	}
+	for id := range out {
+		sort.Strings(out[id])
+	}
+	ids := append([]string(nil), nodeIDs...)
+	sort.Strings(ids)
+
+	var cycles [][]string
+	for _, scc := range stronglyConnected(ids, out) {
+		start := scc[0]
+		if len(scc) == 1 && !contains(out[start], start) {
+			continue
+		}
+		members := map[string]bool{}
+		for _, id := range scc {
+			members[id] = true
+		}
+		cycles = append(cycles, shortestCycle(start, out, members))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// stronglyConnected is Tarjan's algorithm (iterative, so deep chains don't grow the goroutine stack).
+// Every returned component is sorted.
+func stronglyConnected(ids []string, out map[string][]string) [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var sccs [][]string
+	next := 0
+
+	type frame struct {
+		id   string
+		edge int
+	}
+	for _, root := range ids {
+		if _, seen := index[root]; seen {
+			continue
+		}
+		call := []frame{{id: root}}
+		index[root], low[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(call) > 0 {
+			f := &call[len(call)-1]
+			if f.edge < len(out[f.id]) {
+				w := out[f.id][f.edge]
+				f.edge++
+				if _, seen := index[w]; !seen {
+					index[w], low[w] = next, next
+					next++
+					stack = append(stack, w)
+					onStack[w] = true
+					call = append(call, frame{id: w})
+				} else if onStack[w] && index[w] < low[f.id] {
+					low[f.id] = index[w]
+				}
+				continue
+			}
+
+			v := f.id
+			call = call[:len(call)-1]
+			if len(call) > 0 {
+				parent := call[len(call)-1].id
+				if low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				var scc []string
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					scc = append(scc, w)
+					if w == v {
+						break
+					}
+				}
+				sort.Strings(scc)
+				sccs = append(sccs, scc)
+			}
+		}
+	}
+	return sccs
+}
+
+// shortestCycle finds the shortest path start → … → start that stays inside members.
+func shortestCycle(start string, out map[string][]string, members map[string]bool) []string {
+	prev := map[string]string{}
+	queue := []string{start}
+	visited := map[string]bool{start: true}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range out[cur] {
+			if !members[next] {
+				continue
+			}
+			if next == start {
+				var back []string
+				for n := cur; n != start; n = prev[n] {
+					back = append(back, n)
+				}
+				path := []string{start}
+				for i := len(back) - 1; i >= 0; i-- {
+					path = append(path, back[i])
+				}
+				return append(path, start)
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			prev[next] = cur
+			queue = append(queue, next)
+		}
+	}
+	return []string{start, start}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
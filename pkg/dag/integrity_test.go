@@ -0,0 +1,58 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func edgesOf(pairs ...[2]string) []db.Edge {
+	var edges []db.Edge
+	for _, p := range pairs {
+		edges = append(edges, db.Edge{SourceNodeID: p[0], TargetNodeID: p[1]})
+	}
+	return edges
+}
+
+func TestFindCycles(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	edges := edgesOf(
+		[2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"}, // cycle a -> b -> c -> a
+		[2]string{"c", "d"}, // d hangs off the cycle
+		[2]string{"e", "e"}, // self-loop
+		[2]string{"d", "f"},
+	)
+
+	got := FindCycles(ids, edges)
+	want := [][]string{{"a", "b", "c", "a"}, {"e", "e"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindCycles = %v, want %v", got, want)
+	}
+
+	if cycles := FindCycles(ids, edgesOf([2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "c"})); len(cycles) != 0 {
+		t.Fatalf("expected no cycles in a DAG, got %v", cycles)
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	nodes := []*db.Node{
+		{ID: "a", Output: "spec.md"},
+		{ID: "b", Output: "spec.md"},
+		{ID: "c", Output: "c"},
+	}
+	edges := edgesOf([2]string{"a", "b"}, [2]string{"b", "a"}, [2]string{"c", "ghost"})
+
+	issues := CheckIntegrity(nodes, edges)
+	var kinds []IssueKind
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	want := []IssueKind{IssueDanglingEdge, IssueCycle, IssueDuplicateOutput}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("issue kinds = %v, want %v (%v)", kinds, want, issues)
+	}
+	if issues[1].Message != "cycle: a -> b -> a" {
+		t.Fatalf("unexpected cycle message: %s", issues[1].Message)
+	}
+}
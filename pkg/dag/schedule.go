@@ -0,0 +1,361 @@
+package dag
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// ScheduleOptions tunes ComputeSchedule.
+type ScheduleOptions struct {
+	// Workers caps how many nodes are worked on at once (0: unlimited).
+	Workers int
+	// HoursPerPoint converts story-point estimates to hours.
+	HoursPerPoint float64
+	// DefaultHours is the duration of nodes without an estimate.
+	DefaultHours float64
+	// Policy decides which nodes are still work to do (IsOpen); the others are left out.
+	Policy *db.StatusPolicy
+}
+
+// ScheduledNode is one open node of a Schedule. Times are in working hours from the start.
+type ScheduledNode struct {
+	Node     *db.Node
+	Duration float64
+	// Estimated is false when DefaultHours stood in for a missing estimate.
+	Estimated bool
+
+	// Critical path method, without a worker limit.
+	EarliestStart  float64
+	EarliestFinish float64
+	LatestStart    float64
+	LatestFinish   float64
+	Slack          float64
+	Critical       bool
+
+	// Leveled schedule for the available workers.
+	Start  float64
+	Finish float64
+	// Worker is 1-based; Wave numbers the batches of nodes started together (1-based).
+	Worker int
+	Wave   int
+}
+
+// Schedule is a critical path analysis of the open nodes plus a schedule leveled over a number of
+// workers.
+type Schedule struct {
+	// Nodes are ordered by leveled start, then critical first, then ID.
+	Nodes []*ScheduledNode
+	// CriticalPath lists the node IDs of one longest chain, in order.
+	CriticalPath []string
+	// Duration is the length of the critical path; Makespan the length of the leveled schedule.
+	Duration float64
+	Makespan float64
+	Workers  int
+	// Warnings lists the estimates that couldn't be parsed; those nodes count as unestimated.
+	Warnings []string
+}
+
+const scheduleEpsilon = 1e-9
+
+// NodeHours returns a node's estimate in hours: the estimate field, or `estimate` in its data
+// (points are converted with hoursPerPoint). ok is false when the node has no estimate.
+func NodeHours(n *db.Node, hoursPerPoint float64) (hours float64, ok bool, err error) {
+	raw := ""
+	if n.Estimate != nil {
+		raw = *n.Estimate
+	} else if len(n.Data) > 0 {
+		var data map[string]interface{}
+		if err := json.Unmarshal(n.Data, &data); err == nil {
+			switch v := data["estimate"].(type) {
+			case string:
+				raw = v
+			case float64:
+				raw = fmt.Sprint(v)
+			}
+		}
+	}
+	if strings.TrimSpace(raw) == "" {
+		return 0, false, nil
+	}
+	v, isDuration, err := db.ParseEstimate(raw)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "node %s", n.ID)
+	}
+	if !isDuration {
+		v *= hoursPerPoint
+	}
+	return v, true, nil
+}
+
+// ComputeSchedule runs the critical path method over the open nodes (earliest and latest start,
+// slack) and levels them over opts.Workers with a list-scheduling heuristic: whenever a worker is
+// free, the ready node with the least slack (then the highest priority) goes first. Dependencies on
+// nodes that aren't open are taken as done.
+func ComputeSchedule(nodes []*db.Node, edges []db.Edge, opts ScheduleOptions) (*Schedule, error) {
+	policy := opts.Policy
+	if policy == nil {
+		policy = db.DefaultStatusPolicy()
+	}
+
+	byID := map[string]*ScheduledNode{}
+	var ids []string
+	var warnings []string
+	for _, n := range nodes {
+		if !policy.IsOpen(n.Status) {
+			continue
+		}
+		// A free-form estimate in data (or a hand-edited one) shouldn't break the whole schedule.
+		hours, ok, err := NodeHours(n, opts.HoursPerPoint)
+		if err != nil {
+			warnings = append(warnings, err.Error()+"; using the default estimate")
+			ok = false
+		}
+		if !ok {
+			hours = opts.DefaultHours
+		}
+		byID[n.ID] = &ScheduledNode{Node: n, Duration: hours, Estimated: ok}
+		ids = append(ids, n.ID)
+	}
+	sort.Strings(ids)
+
+	preds := map[string][]string{}
+	succs := map[string][]string{}
+	var open []db.Edge
+	for _, e := range edges {
+		if byID[e.SourceNodeID] == nil || byID[e.TargetNodeID] == nil {
+			continue
+		}
+		preds[e.TargetNodeID] = append(preds[e.TargetNodeID], e.SourceNodeID)
+		succs[e.SourceNodeID] = append(succs[e.SourceNodeID], e.TargetNodeID)
+		open = append(open, e)
+	}
+
+	order, err := topoOrder(ids, preds, succs)
+	if err != nil {
+		if cycles := FindCycles(ids, open); len(cycles) > 0 {
+			return nil, errors.Errorf("cannot schedule: cycle: %s", strings.Join(cycles[0], " -> "))
+		}
+		return nil, err
+	}
+
+	s := &Schedule{Workers: opts.Workers, Warnings: warnings}
+	for _, id := range order {
+		sn := byID[id]
+		for _, p := range preds[id] {
+			sn.EarliestStart = math.Max(sn.EarliestStart, byID[p].EarliestFinish)
+		}
+		sn.EarliestFinish = sn.EarliestStart + sn.Duration
+		s.Duration = math.Max(s.Duration, sn.EarliestFinish)
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		sn := byID[order[i]]
+		sn.LatestFinish = s.Duration
+		for _, succ := range succs[sn.Node.ID] {
+			sn.LatestFinish = math.Min(sn.LatestFinish, byID[succ].LatestStart)
+		}
+		sn.LatestStart = sn.LatestFinish - sn.Duration
+		sn.Slack = sn.LatestStart - sn.EarliestStart
+		if sn.Slack < scheduleEpsilon {
+			sn.Slack = 0
+			sn.Critical = true
+		}
+	}
+	s.CriticalPath = criticalPath(order, byID, preds, succs)
+
+	level(order, byID, preds, succs, opts.Workers, s)
+
+	for _, id := range ids {
+		s.Nodes = append(s.Nodes, byID[id])
+	}
+	sort.SliceStable(s.Nodes, func(i, j int) bool {
+		a, b := s.Nodes[i], s.Nodes[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.Critical != b.Critical {
+			return a.Critical
+		}
+		return a.Node.ID < b.Node.ID
+	})
+	return s, nil
+}
+
+// topoOrder sorts ids so that every node comes after its predecessors (ties by ID).
+func topoOrder(ids []string, preds, succs map[string][]string) ([]string, error) {
+	indegree := map[string]int{}
+	ready := &heapOf[string]{less: func(a, b string) bool { return a < b }}
+	for _, id := range ids {
+		indegree[id] = len(preds[id])
+		if indegree[id] == 0 {
+			heap.Push(ready, id)
+		}
+	}
+	var order []string
+	for ready.Len() > 0 {
+		id := heap.Pop(ready).(string)
+		order = append(order, id)
+		for _, succ := range succs[id] {
+			indegree[succ]--
+			if indegree[succ] == 0 {
+				heap.Push(ready, succ)
+			}
+		}
+	}
+	if len(order) != len(ids) {
+		return nil, errors.New("cannot schedule: the graph has a cycle")
+	}
+	return order, nil
+}
+
+// criticalPath follows critical nodes from a critical start to the end of the project.
+func criticalPath(order []string, byID map[string]*ScheduledNode, preds, succs map[string][]string) []string {
+	var cur *ScheduledNode
+	for _, id := range order {
+		sn := byID[id]
+		if sn.Critical && len(preds[id]) == 0 {
+			cur = sn
+			break
+		}
+	}
+	var path []string
+	for cur != nil {
+		path = append(path, cur.Node.ID)
+		var next *ScheduledNode
+		candidates := append([]string{}, succs[cur.Node.ID]...)
+		sort.Strings(candidates)
+		for _, id := range candidates {
+			sn := byID[id]
+			if sn.Critical && math.Abs(sn.EarliestStart-cur.EarliestFinish) < scheduleEpsilon {
+				next = sn
+				break
+			}
+		}
+		cur = next
+	}
+	return path
+}
+
+// level assigns start times, workers and waves: at every point in time where a worker is free and
+// a node is ready, ready nodes are started by least slack, then priority, then ID. Nodes become
+// ready once all their predecessors have finished.
+func level(order []string, byID map[string]*ScheduledNode, preds, succs map[string][]string, workers int, s *Schedule) {
+	if workers <= 0 {
+		workers = len(order)
+	}
+	rank := make(map[string]int, len(order))
+	remaining := make(map[string]int, len(order))
+	readyAt := make(map[string]float64, len(order))
+	for _, id := range order {
+		rank[id] = db.PriorityRank(byID[id].Node.Priority)
+		remaining[id] = len(preds[id])
+	}
+
+	ready := &heapOf[string]{less: func(x, y string) bool {
+		a, b := byID[x], byID[y]
+		if a.Slack != b.Slack {
+			return a.Slack < b.Slack
+		}
+		if rank[x] != rank[y] {
+			return rank[x] < rank[y]
+		}
+		return x < y
+	}}
+	waiting := &heapOf[string]{less: func(x, y string) bool {
+		if readyAt[x] != readyAt[y] {
+			return readyAt[x] < readyAt[y]
+		}
+		return x < y
+	}}
+	free := make([]float64, workers)
+	idle := &heapOf[int]{less: func(a, b int) bool { return a < b }}
+	busy := &heapOf[int]{less: func(a, b int) bool {
+		if free[a] != free[b] {
+			return free[a] < free[b]
+		}
+		return a < b
+	}}
+	for w := 0; w < workers; w++ {
+		heap.Push(idle, w)
+	}
+	for _, id := range order {
+		if remaining[id] == 0 {
+			heap.Push(waiting, id)
+		}
+	}
+
+	now, wave, scheduled := 0.0, 0, 0
+	for scheduled < len(order) {
+		for waiting.Len() > 0 && readyAt[waiting.items[0]] <= now+scheduleEpsilon {
+			heap.Push(ready, heap.Pop(waiting))
+		}
+		for busy.Len() > 0 && free[busy.items[0]] <= now+scheduleEpsilon {
+			heap.Push(idle, heap.Pop(busy))
+		}
+
+		var started []*ScheduledNode
+		for ready.Len() > 0 && idle.Len() > 0 {
+			sn := byID[heap.Pop(ready).(string)]
+			w := heap.Pop(idle).(int)
+			if len(started) == 0 {
+				wave++
+			}
+			sn.Start, sn.Finish = now, now+sn.Duration
+			sn.Worker, sn.Wave = w+1, wave
+			free[w] = sn.Finish
+			heap.Push(busy, w)
+			s.Makespan = math.Max(s.Makespan, sn.Finish)
+			started = append(started, sn)
+		}
+		scheduled += len(started)
+		// Successors are only considered from the next round on, so each round is one wave.
+		for _, sn := range started {
+			for _, succ := range succs[sn.Node.ID] {
+				readyAt[succ] = math.Max(readyAt[succ], sn.Finish)
+				remaining[succ]--
+				if remaining[succ] == 0 {
+					heap.Push(waiting, succ)
+				}
+			}
+		}
+		if len(started) > 0 {
+			// Zero-length nodes may have made others ready right away.
+			continue
+		}
+
+		next := math.Inf(1)
+		if waiting.Len() > 0 {
+			next = readyAt[waiting.items[0]]
+		}
+		if busy.Len() > 0 {
+			next = math.Min(next, free[busy.items[0]])
+		}
+		if math.IsInf(next, 1) || next <= now+scheduleEpsilon {
+			return
+		}
+		now = next
+	}
+}
+
+// heapOf is a container/heap of T ordered by less.
+type heapOf[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *heapOf[T]) Len() int           { return len(h.items) }
+func (h *heapOf[T]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *heapOf[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *heapOf[T]) Push(x any)         { h.items = append(h.items, x.(T)) }
+func (h *heapOf[T]) Pop() any {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
@@ -0,0 +1,113 @@
+package dag
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func estimated(id, estimate, status string) *db.Node {
+	n := &db.Node{ID: id, Status: status}
+	if estimate != "" {
+		n.Estimate = &estimate
+	}
+	return n
+}
+
+func TestComputeSchedule(t *testing.T) {
+	nodes := []*db.Node{
+		estimated("a", "2d", db.StatusPending),
+		estimated("b", "4h", db.StatusPending),
+		estimated("c", "3", db.StatusInProgress),
+		estimated("d", "", db.StatusPending),
+		estimated("done", "1w", db.StatusComplete),
+	}
+	edges := edgesOf([2]string{"done", "a"}, [2]string{"a", "d"}, [2]string{"b", "c"}, [2]string{"c", "d"})
+
+	s, err := ComputeSchedule(nodes, edges, ScheduleOptions{HoursPerPoint: 8, DefaultHours: 8})
+	if err != nil {
+		t.Fatalf("ComputeSchedule: %v", err)
+	}
+	if s.Duration != 36 || s.Makespan != 36 {
+		t.Fatalf("expected duration and makespan 36h, got %v and %v", s.Duration, s.Makespan)
+	}
+	if want := []string{"b", "c", "d"}; !reflect.DeepEqual(s.CriticalPath, want) {
+		t.Fatalf("CriticalPath = %v, want %v", s.CriticalPath, want)
+	}
+
+	var got []string
+	for _, sn := range s.Nodes {
+		got = append(got, strings.Join([]string{sn.Node.ID,
+			ftoa(sn.EarliestStart), ftoa(sn.LatestStart), ftoa(sn.Slack), ftoa(sn.Start), strconv.Itoa(sn.Wave)}, ":"))
+		if sn.Node.ID == "d" && sn.Estimated {
+			t.Fatalf("expected d to use the default estimate")
+		}
+	}
+	// id:earliest_start:latest_start:slack:start:wave; the complete node is left out.
+	want := "b:0:0:0:0:1|a:0:12:12:0:1|c:4:4:0:4:2|d:28:28:0:28:3"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, "|"))
+	}
+}
+
+func TestComputeSchedule_Workers(t *testing.T) {
+	nodes := []*db.Node{
+		estimated("a", "2d", db.StatusPending),
+		estimated("b", "4h", db.StatusPending),
+		estimated("c", "3d", db.StatusPending),
+		estimated("d", "1d", db.StatusPending),
+	}
+	edges := edgesOf([2]string{"a", "d"}, [2]string{"b", "c"}, [2]string{"c", "d"})
+
+	for _, tc := range []struct {
+		workers  int
+		makespan float64
+		order    string
+	}{
+		// The critical chain goes first; a fills in once a worker is free.
+		{workers: 1, makespan: 52, order: "b:1:0|c:1:4|a:1:28|d:1:44"},
+		{workers: 2, makespan: 36, order: "b:1:0|a:2:0|c:1:4|d:1:28"},
+	} {
+		s, err := ComputeSchedule(nodes, edges, ScheduleOptions{Workers: tc.workers, HoursPerPoint: 8})
+		if err != nil {
+			t.Fatalf("ComputeSchedule: %v", err)
+		}
+		var got []string
+		for _, sn := range s.Nodes {
+			got = append(got, sn.Node.ID+":"+strconv.Itoa(sn.Worker)+":"+ftoa(sn.Start))
+		}
+		if s.Makespan != tc.makespan || strings.Join(got, "|") != tc.order {
+			t.Fatalf("%d workers: expected makespan %v and %q, got %v and %q",
+				tc.workers, tc.makespan, tc.order, s.Makespan, strings.Join(got, "|"))
+		}
+	}
+
+	if _, err := ComputeSchedule(nodes, append(edges, edgesOf([2]string{"d", "b"})...), ScheduleOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestComputeSchedule_BadDataEstimate(t *testing.T) {
+	bad := estimated("b", "", db.StatusPending)
+	bad.Data = []byte(`{"estimate":"lots"}`)
+	nodes := []*db.Node{estimated("a", "1d", db.StatusPending), bad}
+
+	s, err := ComputeSchedule(nodes, edgesOf([2]string{"a", "b"}), ScheduleOptions{HoursPerPoint: 8, DefaultHours: 4})
+	if err != nil {
+		t.Fatalf("ComputeSchedule: %v", err)
+	}
+	if s.Duration != 12 {
+		t.Fatalf("expected b to use the 4h default (duration 12h), got %v", s.Duration)
+	}
+	if len(s.Warnings) != 1 || !strings.Contains(s.Warnings[0], "node b") {
+		t.Fatalf("expected one warning about b, got %v", s.Warnings)
+	}
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityMedium   = "medium"
+	PriorityLow      = "low"
+)
+
+// Priorities lists the node priorities from most to least urgent.
+var Priorities = []string{PriorityCritical, PriorityHigh, PriorityMedium, PriorityLow}
+
+// DueDateLayout is the on-disk and command-line format of node due dates.
+const DueDateLayout = "2006-01-02"
+
+// Working-time units used by duration estimates.
+const (
+	hoursPerDay  = 8
+	hoursPerWeek = 5 * hoursPerDay
+)
+
+// PriorityRank orders priorities (0 = critical). Unset or unknown priorities sort last.
+func PriorityRank(p *string) int {
+	if p != nil {
+		for i, x := range Priorities {
+			if *p == x {
+				return i
+			}
+		}
+	}
+	return len(Priorities)
+}
+
+// IsKnownPriority reports whether p is one of Priorities.
+func IsKnownPriority(p string) bool {
+	for _, x := range Priorities {
+		if p == x {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEstimate parses a node estimate. Plain numbers are story points; a number
+// followed by m, h, d (8h) or w (5d) is a duration, returned in hours.
+func ParseEstimate(s string) (value float64, isDuration bool, err error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return 0, false, errors.New("empty estimate")
+	}
+
+	factor := 0.0
+	switch s[len(s)-1] {
+	case 'm':
+		factor = 1.0 / 60
+	case 'h':
+		factor = 1
+	case 'd':
+		factor = hoursPerDay
+	case 'w':
+		factor = hoursPerWeek
+	}
+	num := s
+	if factor != 0 {
+		num = s[:len(s)-1]
+	}
+
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, false, errors.Errorf("invalid estimate %q (want points like 3 or a duration like 4h, 2d, 1w)", s)
+	}
+	if factor == 0 {
+		return v, false, nil
+	}
+	return v * factor, true, nil
+}
+
+// ParseDueDate parses a YYYY-MM-DD due date as midnight UTC.
+func ParseDueDate(s string) (time.Time, error) {
+	t, err := time.Parse(DueDateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid due date %q (want YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// NormalizeLabels trims, drops empty and duplicate labels, and sorts the result.
+func NormalizeLabels(labels []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasLabel reports whether the node carries label.
+func (n *Node) HasLabel(label string) bool {
+	for _, l := range n.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// DueDateString renders the due date as YYYY-MM-DD ("" when unset).
+func (n *Node) DueDateString() string {
+	if n.DueDate == nil {
+		return ""
+	}
+	return n.DueDate.Format(DueDateLayout)
+}
+
+// StringValue dereferences an optional attribute ("" when unset).
+func StringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
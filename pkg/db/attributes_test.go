@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		in         string
+		value      float64
+		isDuration bool
+		ok         bool
+	}{
+		{"3", 3, false, true},
+		{"0.5", 0.5, false, true},
+		{"4h", 4, true, true},
+		{"90m", 1.5, true, true},
+		{"2d", 16, true, true},
+		{"1W", 40, true, true},
+		{"", 0, false, false},
+		{"3x", 0, false, false},
+		{"-1h", 0, false, false},
+	} {
+		v, d, err := ParseEstimate(tc.in)
+		if (err == nil) != tc.ok {
+			t.Errorf("%q: ok=%v, got err=%v", tc.in, tc.ok, err)
+			continue
+		}
+		if tc.ok && (v != tc.value || d != tc.isDuration) {
+			t.Errorf("%q: expected (%v, %v), got (%v, %v)", tc.in, tc.value, tc.isDuration, v, d)
+		}
+	}
+}
+
+func TestProjectDB_NodeAttributesRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	pdb := newTestProjectDB(t, []string{"a"}, nil)
+
+	n, err := pdb.GetNode(ctx, "a")
+	if err != nil || n == nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if n.Assignee != nil || n.Priority != nil || n.Estimate != nil || n.DueDate != nil || n.Labels != nil {
+		t.Fatalf("expected no attributes on a fresh node, got %+v", n)
+	}
+
+	due, err := ParseDueDate("2026-11-01")
+	if err != nil {
+		t.Fatalf("ParseDueDate: %v", err)
+	}
+	assignee, priority, estimate := "alice", PriorityHigh, "2d"
+	n.Assignee, n.Priority, n.Estimate, n.DueDate = &assignee, &priority, &estimate, &due
+	n.Labels = NormalizeLabels([]string{"backend", " api", "backend", "team: web, mobile"})
+	if err := pdb.ReplaceNode(ctx, n); err != nil {
+		t.Fatalf("ReplaceNode: %v", err)
+	}
+
+	got, err := pdb.GetNode(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if *got.Assignee != "alice" || *got.Priority != PriorityHigh || *got.Estimate != "2d" {
+		t.Fatalf("unexpected attributes: %+v", got)
+	}
+	if got.DueDateString() != "2026-11-01" {
+		t.Fatalf("expected due 2026-11-01, got %q", got.DueDateString())
+	}
+	if !reflect.DeepEqual(got.Labels, []string{"api", "backend", "team: web, mobile"}) {
+		t.Fatalf("expected sorted, deduplicated labels with commas kept, got %v", got.Labels)
+	}
+}
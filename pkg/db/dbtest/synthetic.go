@@ -0,0 +1,58 @@
+// Package dbtest holds fixtures for tests and benchmarks of the packages built on db.
+package dbtest
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+// SyntheticGraph builds a deterministic project of n nodes shaped like a big monorepo plan, for
+// benchmarks: each node depends on one to three of the 50 nodes before it (so the graph is deep
+// as well as wide), the first third is complete, and nodes carry a mix of types and estimates.
+// Node i has ID and output SyntheticNodeID(i).
+func SyntheticGraph(n int) ([]*db.Node, []db.Edge) {
+	r := rand.New(rand.NewSource(1))
+	types := []string{"document", "code", "test", "review"}
+	estimates := []string{"2h", "1d", "3", "1w", ""}
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	nodes := make([]*db.Node, 0, n)
+	var edges []db.Edge
+	for i := 0; i < n; i++ {
+		id := SyntheticNodeID(i)
+		node := &db.Node{
+			ID:        id,
+			Type:      types[r.Intn(len(types))],
+			Output:    id,
+			Status:    db.StatusPending,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+		if i < n/3 {
+			node.Status = db.StatusComplete
+		}
+		if e := estimates[r.Intn(len(estimates))]; e != "" {
+			node.Estimate = &e
+		}
+		nodes = append(nodes, node)
+		if i == 0 {
+			continue
+		}
+		seen := map[int]bool{}
+		for k := 0; k < 1+r.Intn(3); k++ {
+			j := i - 1 - r.Intn(min(i, 50))
+			if !seen[j] {
+				seen[j] = true
+				edges = append(edges, db.Edge{SourceNodeID: SyntheticNodeID(j), TargetNodeID: id})
+			}
+		}
+	}
+	return nodes, edges
+}
+
+// SyntheticNodeID is the ID (and output) of node i of SyntheticGraph.
+func SyntheticNodeID(i int) string {
+	return fmt.Sprintf("n%05d", i)
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CycleError is returned when adding an edge would close a cycle. Path lists the node ids along the
+// cycle, starting and ending at the same node.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	if len(e.Path) == 2 {
+		return "self-loop on node " + e.Path[0]
+	}
+	return "cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// HasEdge reports whether the edge source → target exists.
+func (p *ProjectDB) HasEdge(ctx context.Context, sourceID, targetID string) (bool, error) {
+	if p.db == nil {
+		return false, errors.New("project db not open")
+	}
+	var n int
+	err := p.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM edges WHERE source_node_id = ? AND target_node_id = ?", sourceID, targetID,
+	).Scan(&n)
+	if err != nil {
+		return false, errors.Wrap(err, "count edges")
+	}
+	return n > 0, nil
+}
+
+// FindPath returns the shortest path from → … → to following edge direction, or nil if to is not
+// reachable from from.
+func (p *ProjectDB) FindPath(ctx context.Context, from, to string) ([]string, error) {
+	edges, err := p.GetEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string][]string{}
+	for _, e := range edges {
+		out[e.SourceNodeID] = append(out[e.SourceNodeID], e.TargetNodeID)
+	}
+
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var path []string
+			for n := to; n != from; n = prev[n] {
+				path = append(path, n)
+			}
+			path = append(path, from)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, nil
+		}
+		for _, next := range out[cur] {
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = cur
+			queue = append(queue, next)
+		}
+	}
+	return nil, nil
+}
+
+// CheckEdge returns a *CycleError if adding source → target would create a self-loop or a cycle.
+func (p *ProjectDB) CheckEdge(ctx context.Context, sourceID, targetID string) error {
+	if sourceID == targetID {
+		return &CycleError{Path: []string{sourceID, targetID}}
+	}
+	path, err := p.FindPath(ctx, targetID, sourceID)
+	if err != nil {
+		return err
+	}
+	if path != nil {
+		return &CycleError{Path: append([]string{sourceID}, path...)}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newTestProjectDB(t testing.TB, nodes []string, edges [][2]string) *ProjectDB {
+	t.Helper()
+	ctx := context.Background()
+
+	sqlDB, err := OpenSQLiteMemory(ctx)
+	if err != nil {
+		t.Fatalf("OpenSQLiteMemory: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	pdb := NewProjectDBFromDB(sqlDB)
+	if err := pdb.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+	for _, id := range nodes {
+		if err := pdb.AddNode(ctx, &Node{ID: id, Type: "document", Output: id}); err != nil {
+			t.Fatalf("AddNode: %v", err)
+		}
+	}
+	for _, e := range edges {
+		if err := pdb.AddEdge(ctx, e[0], e[1]); err != nil {
+			t.Fatalf("AddEdge: %v", err)
+		}
+	}
+	return pdb
+}
+
+func TestProjectDB_CheckEdge(t *testing.T) {
+	ctx := context.Background()
+	pdb := newTestProjectDB(t, []string{"a", "b", "c", "d"}, [][2]string{{"a", "b"}, {"b", "c"}, {"a", "d"}})
+
+	if err := pdb.CheckEdge(ctx, "d", "c"); err != nil {
+		t.Fatalf("expected d -> c to be allowed, got %v", err)
+	}
+
+	var cycleErr *CycleError
+	err := pdb.CheckEdge(ctx, "c", "a")
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected CycleError, got %v", err)
+	}
+	if want := []string{"c", "a", "b", "c"}; !reflect.DeepEqual(cycleErr.Path, want) {
+		t.Fatalf("expected cycle path %v, got %v", want, cycleErr.Path)
+	}
+
+	err = pdb.CheckEdge(ctx, "b", "b")
+	if !errors.As(err, &cycleErr) || err.Error() != "self-loop on node b" {
+		t.Fatalf("expected self-loop error, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Predicate kinds, as stored in tactic_dependencies.kind.
+const (
+	// PredicateOutput matches nodes by output name (the plain form, e.g. `api_specification`).
+	PredicateOutput = "output"
+	// PredicateGlob matches outputs against a shell pattern, e.g. `*_tests`.
+	PredicateGlob = "glob"
+	// PredicateType matches the node type, e.g. `type:design_document`.
+	PredicateType = "type"
+	// PredicateLabel matches a node label, e.g. `label:backend` (or `tag:backend`).
+	PredicateLabel = "label"
+	// PredicateData compares a field of the node data, e.g. `data.language == "go"`.
+	PredicateData = "data"
+	// PredicateCount requires several matching nodes, e.g. `>=2 type:design_document`.
+	PredicateCount = "count"
+)
+
+var (
+	countPredicateRE = regexp.MustCompile(`^>=\s*(\d+)\s+(\S.*)$`)
+	dataPredicateRE  = regexp.MustCompile(`^data\.([A-Za-z0-9_\-]+(?:\.[A-Za-z0-9_\-]+)*)\s*(==|!=)\s*(.+)$`)
+)
+
+// Predicate is a parsed tactic match or premise entry: which project nodes satisfy it.
+type Predicate struct {
+	// Raw is the entry as written in the tactic.
+	Raw  string
+	Kind string
+	// Value is the output, glob, type or label to match, or the JSON-encoded value to compare
+	// data against.
+	Value string
+	// Field is the path into the node data (data predicates).
+	Field []string
+	// Negate compares data with != instead of ==.
+	Negate bool
+	// Min and Of are set on count predicates: at least Min nodes matching Of.
+	Min int
+	Of  *Predicate
+}
+
+// PredicateKind returns the kind of a match or premise entry, PredicateOutput for entries that
+// don't parse (they never match anything).
+func PredicateKind(s string) string {
+	p, err := ParsePredicate(s)
+	if err != nil {
+		return PredicateOutput
+	}
+	return p.Kind
+}
+
+// ParsePredicate parses a tactic match or premise entry. Plain names match outputs exactly, names
+// with *, ? or [ are globs, `type:`, `label:` and `data.field ==` select by node attributes, and a
+// `>=N ` prefix asks for at least N matching nodes.
+func ParsePredicate(s string) (*Predicate, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty match predicate")
+	}
+
+	if m := countPredicateRE.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return nil, errors.Errorf("invalid count in match predicate %q (want >=1)", raw)
+		}
+		of, err := ParsePredicate(m[2])
+		if err != nil {
+			return nil, err
+		}
+		if of.Kind == PredicateCount {
+			return nil, errors.Errorf("nested count in match predicate %q", raw)
+		}
+		return &Predicate{Raw: raw, Kind: PredicateCount, Min: n, Of: of}, nil
+	}
+	if strings.HasPrefix(s, ">=") {
+		return nil, errors.Errorf("invalid match predicate %q (want \">=N predicate\")", raw)
+	}
+
+	if m := dataPredicateRE.FindStringSubmatch(s); m != nil {
+		value, err := predicateValue(m[3])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value in match predicate %q", raw)
+		}
+		return &Predicate{Raw: raw, Kind: PredicateData, Field: strings.Split(m[1], "."), Negate: m[2] == "!=", Value: value}, nil
+	}
+	if strings.HasPrefix(s, "data.") {
+		return nil, errors.Errorf("invalid match predicate %q (want data.field == value)", raw)
+	}
+
+	kind, value := PredicateOutput, s
+	for prefix, k := range map[string]string{"type:": PredicateType, "label:": PredicateLabel, "tag:": PredicateLabel} {
+		if strings.HasPrefix(s, prefix) {
+			kind, value = k, strings.TrimSpace(strings.TrimPrefix(s, prefix))
+			if value == "" {
+				return nil, errors.Errorf("match predicate %q has no value", raw)
+			}
+		}
+	}
+	if kind == PredicateOutput && isGlob(value) {
+		kind = PredicateGlob
+	}
+	if isGlob(value) {
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, errors.Errorf("invalid pattern in match predicate %q", raw)
+		}
+	}
+	return &Predicate{Raw: raw, Kind: kind, Value: value}, nil
+}
+
+// MinCount is the number of matching nodes the predicate needs.
+func (p *Predicate) MinCount() int {
+	if p.Kind == PredicateCount {
+		return p.Min
+	}
+	return 1
+}
+
+// Matches reports whether n is one of the nodes the predicate selects, whatever its status.
+func (p *Predicate) Matches(n *Node) bool {
+	switch p.Kind {
+	case PredicateOutput:
+		return n.Output == p.Value
+	case PredicateGlob:
+		return globMatch(p.Value, n.Output)
+	case PredicateType:
+		return globMatch(p.Value, n.Type)
+	case PredicateLabel:
+		for _, l := range n.Labels {
+			if globMatch(p.Value, l) {
+				return true
+			}
+		}
+		return false
+	case PredicateData:
+		return p.matchesData(n) != p.Negate
+	case PredicateCount:
+		return p.Of.Matches(n)
+	}
+	return false
+}
+
+// Select returns the nodes the predicate selects, whatever their status.
+func (p *Predicate) Select(nodes []*Node) []*Node {
+	var ret []*Node
+	for _, n := range nodes {
+		if p.Matches(n) {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
+// Satisfied reports whether enough selected nodes satisfy dependents under policy.
+func (p *Predicate) Satisfied(nodes []*Node, policy *StatusPolicy) bool {
+	count := 0
+	for _, n := range nodes {
+		if p.Matches(n) && policy.Satisfies(n.Status) {
+			count++
+		}
+	}
+	return count >= p.MinCount()
+}
+
+func (p *Predicate) matchesData(n *Node) bool {
+	if len(n.Data) == 0 {
+		return false
+	}
+	var cur interface{}
+	if err := json.Unmarshal(n.Data, &cur); err != nil {
+		return false
+	}
+	for _, f := range p.Field {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if cur, ok = m[f]; !ok {
+			return false
+		}
+	}
+	b, err := json.Marshal(cur)
+	return err == nil && string(b) == p.Value
+}
+
+// predicateValue normalizes the right-hand side of a data predicate to JSON: quoted strings,
+// numbers, booleans and null are taken as such, anything else as a bare string.
+func predicateValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	var v interface{}
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		v = s[1 : len(s)-1]
+	case strings.HasPrefix(s, `"`):
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return "", err
+		}
+	default:
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			v = s
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+func globMatch(pattern, s string) bool {
+	if !isGlob(pattern) {
+		return pattern == s
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
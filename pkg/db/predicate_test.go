@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParsePredicate_Matches(t *testing.T) {
+	node := &Node{
+		ID:     "users_api",
+		Type:   "code",
+		Output: "users_tests",
+		Labels: []string{"backend"},
+		Data:   json.RawMessage(`{"language":"go","review":{"rounds":2}}`),
+	}
+
+	for _, tc := range []struct {
+		predicate string
+		kind      string
+		matches   bool
+	}{
+		{"users_tests", PredicateOutput, true},
+		{"users", PredicateOutput, false},
+		{"*_tests", PredicateGlob, true},
+		{"type:code", PredicateType, true},
+		{"type:design_*", PredicateType, false},
+		{"label:backend", PredicateLabel, true},
+		{"tag:frontend", PredicateLabel, false},
+		{`data.language == "go"`, PredicateData, true},
+		{"data.language == go", PredicateData, true},
+		{"data.language != 'go'", PredicateData, false},
+		{"data.review.rounds == 2", PredicateData, true},
+		{"data.missing == 1", PredicateData, false},
+		{">=2 type:code", PredicateCount, true},
+	} {
+		p, err := ParsePredicate(tc.predicate)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.predicate, err)
+		}
+		if p.Kind != tc.kind || p.Matches(node) != tc.matches {
+			t.Fatalf("%s: got kind %s, matches %v; want %s, %v", tc.predicate, p.Kind, p.Matches(node), tc.kind, tc.matches)
+		}
+	}
+
+	for _, bad := range []string{"", ">=0 spec", ">=x spec", "type:", "data.language", "[_tests", ">=2 >=3 spec"} {
+		if _, err := ParsePredicate(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPredicate_Satisfied_Count(t *testing.T) {
+	policy := DefaultStatusPolicy()
+	nodes := []*Node{
+		{ID: "a", Type: "design_document", Status: StatusComplete},
+		{ID: "b", Type: "design_document", Status: StatusPending},
+	}
+	p, err := ParsePredicate(">=2 type:design_document")
+	if err != nil {
+		t.Fatalf("ParsePredicate: %v", err)
+	}
+	if p.Satisfied(nodes, policy) {
+		t.Fatalf("expected one completed design document not to satisfy >=2")
+	}
+	nodes[1].Status = StatusComplete
+	if !p.Satisfied(nodes, policy) {
+		t.Fatalf("expected two completed design documents to satisfy >=2")
+	}
+}
+
+func TestTacticsDB_SearchTactics_PrefiltersOutputs(t *testing.T) {
+	ctx := context.Background()
+	sqlDB, err := OpenSQLiteMemory(ctx)
+	if err != nil {
+		t.Fatalf("OpenSQLiteMemory: %v", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+	tdb := NewTacticsDBFromDB(sqlDB)
+	if err := tdb.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+
+	err = tdb.AddTactics(ctx, []*Tactic{
+		{ID: "needs_spec", Type: "code", Output: "code", Match: []string{"spec"}},
+		{ID: "needs_plan", Type: "code", Output: "code2", Match: []string{"plan"}},
+		{ID: "needs_docs", Type: "code", Output: "code3", Match: []string{">=2 type:design_document"}},
+		{ID: "no_deps", Type: "document", Output: "spec"},
+	})
+	if err != nil {
+		t.Fatalf("AddTactics: %v", err)
+	}
+
+	found, err := tdb.SearchTactics(ctx, "", nil, nil, []string{"spec"})
+	if err != nil {
+		t.Fatalf("SearchTactics: %v", err)
+	}
+	var ids []string
+	for _, tactic := range found {
+		ids = append(ids, tactic.ID)
+	}
+	sort.Strings(ids)
+	// Predicates other than plain outputs are left to the evaluator.
+	if got := strings.Join(ids, " "); got != "needs_docs needs_spec no_deps" {
+		t.Fatalf("expected tactics waiting on plan to be filtered out, got %q", got)
+	}
+}
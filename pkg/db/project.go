@@ -66,7 +66,15 @@ CREATE TABLE IF NOT EXISTS nodes (
   completed_at TEXT,
   parent_tactic TEXT,
   introduced_as TEXT,
-  data TEXT
+  data TEXT,
+  assignee TEXT,
+  priority TEXT,
+  estimate TEXT,
+  due_date TEXT,
+  labels TEXT,
+  tactic_instance TEXT,
+  tactic_params TEXT,
+  producer_tactic TEXT
 );
 
 CREATE TABLE IF NOT EXISTS edges (
@@ -88,7 +96,9 @@ CREATE TABLE IF NOT EXISTS action_log (
   action TEXT NOT NULL,
   details TEXT,
   node_id TEXT,
-  tactic_id TEXT
+  tactic_id TEXT,
+  change_set TEXT,
+  undone INTEGER NOT NULL DEFAULT 0
 );
 
 CREATE INDEX IF NOT EXISTS idx_log_timestamp ON action_log(timestamp);
@@ -154,6 +164,136 @@ func (p *ProjectDB) GetProjectMeta(ctx context.Context) (map[string]string, erro
 	return meta, nil
 }
 
+// nodeColumns is the column list scanNode expects, qualified with the "n" alias.
+const nodeColumns = `n.id, n.type, n.output, n.status, n.created_by, n.created_at, n.completed_at,
+  n.parent_tactic, n.introduced_as, n.data, n.assignee, n.priority, n.estimate, n.due_date, n.labels,
+  n.tactic_instance, n.tactic_params, n.producer_tactic`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNode(row rowScanner) (*Node, error) {
+	var n Node
+	var createdBy, createdAt, completedAt, parentTactic, introducedAs, data sql.NullString
+	var assignee, priority, estimate, dueDate, labels sql.NullString
+	var tacticInstance, tacticParams, producerTactic sql.NullString
+	if err := row.Scan(
+		&n.ID, &n.Type, &n.Output, &n.Status,
+		&createdBy, &createdAt, &completedAt, &parentTactic, &introducedAs, &data,
+		&assignee, &priority, &estimate, &dueDate, &labels,
+		&tacticInstance, &tacticParams, &producerTactic,
+	); err != nil {
+		return nil, errors.Wrap(err, "scan node")
+	}
+
+	if createdBy.Valid {
+		n.CreatedBy = &createdBy.String
+	}
+	if createdAt.Valid {
+		t, err := time.Parse(time.RFC3339Nano, createdAt.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse created_at")
+		}
+		n.CreatedAt = t
+	}
+	if completedAt.Valid {
+		t, err := time.Parse(time.RFC3339Nano, completedAt.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse completed_at")
+		}
+		n.CompletedAt = &t
+	}
+	if parentTactic.Valid {
+		n.ParentTactic = &parentTactic.String
+	}
+	if introducedAs.Valid {
+		n.IntroducedAs = &introducedAs.String
+	}
+	if data.Valid && data.String != "" {
+		n.Data = json.RawMessage(data.String)
+	}
+	if assignee.Valid {
+		n.Assignee = &assignee.String
+	}
+	if priority.Valid {
+		n.Priority = &priority.String
+	}
+	if estimate.Valid {
+		n.Estimate = &estimate.String
+	}
+	if dueDate.Valid {
+		t, err := time.Parse(DueDateLayout, dueDate.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse due_date")
+		}
+		n.DueDate = &t
+	}
+	if labels.Valid && labels.String != "" {
+		if err := json.Unmarshal([]byte(labels.String), &n.Labels); err != nil {
+			return nil, errors.Wrap(err, "unmarshal labels")
+		}
+	}
+	if tacticInstance.Valid {
+		n.TacticInstance = &tacticInstance.String
+	}
+	if producerTactic.Valid {
+		n.ProducerTactic = &producerTactic.String
+	}
+	if tacticParams.Valid && tacticParams.String != "" {
+		if err := json.Unmarshal([]byte(tacticParams.String), &n.TacticParams); err != nil {
+			return nil, errors.Wrap(err, "unmarshal tactic_params")
+		}
+	}
+
+	return &n, nil
+}
+
+func scanNodes(rows *sql.Rows, what string) ([]*Node, error) {
+	defer func() { _ = rows.Close() }()
+
+	var out []*Node
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate "+what)
+	}
+	return out, nil
+}
+
+// nodeExtraArgs returns the SQL values of the columns after data (assignee,
+// priority, estimate, due_date, labels, tactic_instance, tactic_params).
+func nodeExtraArgs(node *Node) ([]any, error) {
+	var dueDate, labels, tacticParams *string
+	if node.DueDate != nil {
+		s := node.DueDate.UTC().Format(DueDateLayout)
+		dueDate = &s
+	}
+	if len(node.Labels) > 0 {
+		// JSON like tactic_params: labels may contain commas.
+		b, err := json.Marshal(node.Labels)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal labels")
+		}
+		s := string(b)
+		labels = &s
+	}
+	if len(node.TacticParams) > 0 {
+		b, err := json.Marshal(node.TacticParams)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal tactic_params")
+		}
+		s := string(b)
+		tacticParams = &s
+	}
+	return []any{node.Assignee, node.Priority, node.Estimate, dueDate, labels, node.TacticInstance, tacticParams, node.ProducerTactic}, nil
+}
+
 func (p *ProjectDB) AddNode(ctx context.Context, node *Node) error {
 	if p.db == nil {
 		return errors.New("project db not open")
@@ -161,12 +301,28 @@ func (p *ProjectDB) AddNode(ctx context.Context, node *Node) error {
 	if node == nil {
 		return errors.New("node is nil")
 	}
+	args, err := nodeInsertArgs(node)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx, insertNodeSQL, args...)
+	return errors.Wrap(err, "insert node")
+}
+
+const insertNodeSQL = `
+INSERT INTO nodes (id, type, output, status, created_by, created_at, completed_at, parent_tactic, introduced_as, data,
+  assignee, priority, estimate, due_date, labels, tactic_instance, tactic_params, producer_tactic)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// nodeInsertArgs returns the insertNodeSQL arguments for node, defaulting its created_at to now.
+func nodeInsertArgs(node *Node) ([]any, error) {
 	if node.CreatedAt.IsZero() {
 		node.CreatedAt = time.Now()
 	}
 	status := node.Status
 	if status == "" {
-		status = "pending"
+		status = StatusPending
 	}
 
 	var completedAt *string
@@ -181,10 +337,7 @@ func (p *ProjectDB) AddNode(ctx context.Context, node *Node) error {
 		data = &s
 	}
 
-	_, err := p.db.ExecContext(ctx, `
-INSERT INTO nodes (id, type, output, status, created_by, created_at, completed_at, parent_tactic, introduced_as, data)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-`,
+	args := []any{
 		node.ID,
 		node.Type,
 		node.Output,
@@ -195,60 +348,71 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		node.ParentTactic,
 		node.IntroducedAs,
 		data,
-	)
-	return errors.Wrap(err, "insert node")
+	}
+	extra, err := nodeExtraArgs(node)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, extra...), nil
 }
 
-func (p *ProjectDB) GetNode(ctx context.Context, id string) (*Node, error) {
+// ImportGraph inserts nodes and edges in one transaction with prepared statements, which is much
+// faster than AddNode/ImportEdge per row on large projects. Like ImportEdge, it doesn't check
+// edges for cycles and ignores duplicate edges.
+func (p *ProjectDB) ImportGraph(ctx context.Context, nodes []*Node, edges []Edge) error {
 	if p.db == nil {
-		return nil, errors.New("project db not open")
+		return errors.New("project db not open")
 	}
 
-	row := p.db.QueryRowContext(ctx, `
-SELECT id, type, output, status, created_by, created_at, completed_at, parent_tactic, introduced_as, data
-FROM nodes WHERE id = ?
-`, id)
-
-	var n Node
-	var createdBy, createdAt, completedAt, parentTactic, introducedAs, data sql.NullString
-	if err := row.Scan(
-		&n.ID, &n.Type, &n.Output, &n.Status,
-		&createdBy, &createdAt, &completedAt, &parentTactic, &introducedAs, &data,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, errors.Wrap(err, "scan node")
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	if createdBy.Valid {
-		n.CreatedBy = &createdBy.String
+	nodeStmt, err := tx.PrepareContext(ctx, insertNodeSQL)
+	if err != nil {
+		return errors.Wrap(err, "prepare insert node")
 	}
-	if createdAt.Valid {
-		t, err := time.Parse(time.RFC3339Nano, createdAt.String)
+	defer func() { _ = nodeStmt.Close() }()
+	for _, n := range nodes {
+		args, err := nodeInsertArgs(n)
 		if err != nil {
-			return nil, errors.Wrap(err, "parse created_at")
+			return err
 		}
-		n.CreatedAt = t
-	}
-	if completedAt.Valid {
-		t, err := time.Parse(time.RFC3339Nano, completedAt.String)
-		if err != nil {
-			return nil, errors.Wrap(err, "parse completed_at")
+		if _, err := nodeStmt.ExecContext(ctx, args...); err != nil {
+			return errors.Wrapf(err, "insert node %s", n.ID)
 		}
-		n.CompletedAt = &t
 	}
-	if parentTactic.Valid {
-		n.ParentTactic = &parentTactic.String
+
+	edgeStmt, err := tx.PrepareContext(ctx, "INSERT OR IGNORE INTO edges (source_node_id, target_node_id) VALUES (?, ?)")
+	if err != nil {
+		return errors.Wrap(err, "prepare insert edge")
 	}
-	if introducedAs.Valid {
-		n.IntroducedAs = &introducedAs.String
+	defer func() { _ = edgeStmt.Close() }()
+	for _, e := range edges {
+		if _, err := edgeStmt.ExecContext(ctx, e.SourceNodeID, e.TargetNodeID); err != nil {
+			return errors.Wrapf(err, "insert edge %s -> %s", e.SourceNodeID, e.TargetNodeID)
+		}
 	}
-	if data.Valid && data.String != "" {
-		n.Data = json.RawMessage(data.String)
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
 	}
+	return nil
+}
 
-	return &n, nil
+func (p *ProjectDB) GetNode(ctx context.Context, id string) (*Node, error) {
+	if p.db == nil {
+		return nil, errors.New("project db not open")
+	}
+
+	row := p.db.QueryRowContext(ctx, "SELECT "+nodeColumns+" FROM nodes n WHERE n.id = ?", id)
+	n, err := scanNode(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return n, err
 }
 
 func (p *ProjectDB) GetAllNodes(ctx context.Context) ([]*Node, error) {
@@ -256,58 +420,11 @@ func (p *ProjectDB) GetAllNodes(ctx context.Context) ([]*Node, error) {
 		return nil, errors.New("project db not open")
 	}
 
-	rows, err := p.db.QueryContext(ctx, `
-SELECT id, type, output, status, created_by, created_at, completed_at, parent_tactic, introduced_as, data
-FROM nodes
-`)
+	rows, err := p.db.QueryContext(ctx, "SELECT "+nodeColumns+" FROM nodes n")
 	if err != nil {
 		return nil, errors.Wrap(err, "select nodes")
 	}
-	defer func() { _ = rows.Close() }()
-
-	var ret []*Node
-	for rows.Next() {
-		var n Node
-		var createdBy, createdAt, completedAt, parentTactic, introducedAs, data sql.NullString
-		if err := rows.Scan(
-			&n.ID, &n.Type, &n.Output, &n.Status,
-			&createdBy, &createdAt, &completedAt, &parentTactic, &introducedAs, &data,
-		); err != nil {
-			return nil, errors.Wrap(err, "scan node")
-		}
-		if createdBy.Valid {
-			n.CreatedBy = &createdBy.String
-		}
-		if createdAt.Valid && createdAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, createdAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse created_at")
-			}
-			n.CreatedAt = t
-		}
-		if completedAt.Valid && completedAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, completedAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse completed_at")
-			}
-			n.CompletedAt = &t
-		}
-		if parentTactic.Valid {
-			n.ParentTactic = &parentTactic.String
-		}
-		if introducedAs.Valid {
-			n.IntroducedAs = &introducedAs.String
-		}
-		if data.Valid && data.String != "" {
-			n.Data = json.RawMessage(data.String)
-		}
-
-		ret = append(ret, &n)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "iterate nodes")
-	}
-	return ret, nil
+	return scanNodes(rows, "nodes")
 }
 
 func (p *ProjectDB) UpdateNodeStatus(ctx context.Context, id string, status string, completedAt *time.Time) error {
@@ -334,7 +451,21 @@ func (p *ProjectDB) DeleteNode(ctx context.Context, id string) error {
 	return errors.Wrap(err, "delete node")
 }
 
+// AddEdge inserts source → target, refusing self-loops and edges that would close a cycle
+// (*CycleError). Adding an existing edge is a no-op.
 func (p *ProjectDB) AddEdge(ctx context.Context, sourceID, targetID string) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	if err := p.CheckEdge(ctx, sourceID, targetID); err != nil {
+		return err
+	}
+	return p.ImportEdge(ctx, sourceID, targetID)
+}
+
+// ImportEdge inserts source → target without the cycle check. It is meant for loading persisted state,
+// which is validated as a whole instead.
+func (p *ProjectDB) ImportEdge(ctx context.Context, sourceID, targetID string) error {
 	if p.db == nil {
 		return errors.New("project db not open")
 	}
@@ -373,7 +504,7 @@ func (p *ProjectDB) GetDependencies(ctx context.Context, nodeID string) ([]*Node
 	}
 
 	rows, err := p.db.QueryContext(ctx, `
-SELECT n.id, n.type, n.output, n.status, n.created_by, n.created_at, n.completed_at, n.parent_tactic, n.introduced_as, n.data
+SELECT `+nodeColumns+`
 FROM nodes n
 INNER JOIN edges e ON e.source_node_id = n.id
 WHERE e.target_node_id = ?
@@ -381,50 +512,7 @@ WHERE e.target_node_id = ?
 	if err != nil {
 		return nil, errors.Wrap(err, "select dependencies")
 	}
-	defer func() { _ = rows.Close() }()
-
-	var ret []*Node
-	for rows.Next() {
-		var n Node
-		var createdBy, createdAt, completedAt, parentTactic, introducedAs, data sql.NullString
-		if err := rows.Scan(
-			&n.ID, &n.Type, &n.Output, &n.Status,
-			&createdBy, &createdAt, &completedAt, &parentTactic, &introducedAs, &data,
-		); err != nil {
-			return nil, errors.Wrap(err, "scan dependency node")
-		}
-		if createdBy.Valid {
-			n.CreatedBy = &createdBy.String
-		}
-		if createdAt.Valid && createdAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, createdAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse created_at")
-			}
-			n.CreatedAt = t
-		}
-		if completedAt.Valid && completedAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, completedAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse completed_at")
-			}
-			n.CompletedAt = &t
-		}
-		if parentTactic.Valid {
-			n.ParentTactic = &parentTactic.String
-		}
-		if introducedAs.Valid {
-			n.IntroducedAs = &introducedAs.String
-		}
-		if data.Valid && data.String != "" {
-			n.Data = json.RawMessage(data.String)
-		}
-		ret = append(ret, &n)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "iterate dependencies")
-	}
-	return ret, nil
+	return scanNodes(rows, "dependencies")
 }
 
 func (p *ProjectDB) GetBlockedBy(ctx context.Context, nodeID string) ([]*Node, error) {
@@ -433,7 +521,7 @@ func (p *ProjectDB) GetBlockedBy(ctx context.Context, nodeID string) ([]*Node, e
 	}
 
 	rows, err := p.db.QueryContext(ctx, `
-SELECT n.id, n.type, n.output, n.status, n.created_by, n.created_at, n.completed_at, n.parent_tactic, n.introduced_as, n.data
+SELECT `+nodeColumns+`
 FROM nodes n
 INNER JOIN edges e ON e.target_node_id = n.id
 WHERE e.source_node_id = ?
@@ -441,50 +529,7 @@ WHERE e.source_node_id = ?
 	if err != nil {
 		return nil, errors.Wrap(err, "select blocked-by")
 	}
-	defer func() { _ = rows.Close() }()
-
-	var ret []*Node
-	for rows.Next() {
-		var n Node
-		var createdBy, createdAt, completedAt, parentTactic, introducedAs, data sql.NullString
-		if err := rows.Scan(
-			&n.ID, &n.Type, &n.Output, &n.Status,
-			&createdBy, &createdAt, &completedAt, &parentTactic, &introducedAs, &data,
-		); err != nil {
-			return nil, errors.Wrap(err, "scan blocked-by node")
-		}
-		if createdBy.Valid {
-			n.CreatedBy = &createdBy.String
-		}
-		if createdAt.Valid && createdAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, createdAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse created_at")
-			}
-			n.CreatedAt = t
-		}
-		if completedAt.Valid && completedAt.String != "" {
-			t, err := time.Parse(time.RFC3339Nano, completedAt.String)
-			if err != nil {
-				return nil, errors.Wrap(err, "parse completed_at")
-			}
-			n.CompletedAt = &t
-		}
-		if parentTactic.Valid {
-			n.ParentTactic = &parentTactic.String
-		}
-		if introducedAs.Valid {
-			n.IntroducedAs = &introducedAs.String
-		}
-		if data.Valid && data.String != "" {
-			n.Data = json.RawMessage(data.String)
-		}
-		ret = append(ret, &n)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "iterate blocked-by")
-	}
-	return ret, nil
+	return scanNodes(rows, "blocked-by")
 }
 
 func (p *ProjectDB) LogAction(ctx context.Context, action string, details *string, nodeID *string, tacticID *string) error {
@@ -504,13 +549,13 @@ func (p *ProjectDB) GetActionLog(ctx context.Context, limit *int, since *time.Ti
 		return nil, errors.New("project db not open")
 	}
 
-	query := "SELECT id, timestamp, action, details, node_id, tactic_id FROM action_log"
+	query := "SELECT id, timestamp, action, details, node_id, tactic_id, change_set, undone FROM action_log"
 	var args []any
 	if since != nil && !since.IsZero() {
 		query += " WHERE timestamp >= ?"
 		args = append(args, since.UTC().Format(time.RFC3339Nano))
 	}
-	query += " ORDER BY timestamp DESC"
+	query += " ORDER BY timestamp DESC, id DESC"
 	if limit != nil && *limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, *limit)
@@ -526,8 +571,8 @@ func (p *ProjectDB) GetActionLog(ctx context.Context, limit *int, since *time.Ti
 	for rows.Next() {
 		var e ActionLogEntry
 		var ts sql.NullString
-		var details, nodeID, tacticID sql.NullString
-		if err := rows.Scan(&e.ID, &ts, &e.Action, &details, &nodeID, &tacticID); err != nil {
+		var details, nodeID, tacticID, changeSet sql.NullString
+		if err := rows.Scan(&e.ID, &ts, &e.Action, &details, &nodeID, &tacticID, &changeSet, &e.Undone); err != nil {
 			return nil, errors.Wrap(err, "scan action_log")
 		}
 		if ts.Valid && ts.String != "" {
@@ -546,6 +591,9 @@ func (p *ProjectDB) GetActionLog(ctx context.Context, limit *int, since *time.Ti
 		if tacticID.Valid {
 			e.TacticID = &tacticID.String
 		}
+		if changeSet.Valid {
+			e.ChangeSet = &changeSet.String
+		}
 		ret = append(ret, e)
 	}
 	if err := rows.Err(); err != nil {
@@ -555,6 +603,178 @@ func (p *ProjectDB) GetActionLog(ctx context.Context, limit *int, since *time.Ti
 	return ret, nil
 }
 
+// SetActionChangeSet attaches (or with nil, drops) the serialized undo change set of a log entry.
+func (p *ProjectDB) SetActionChangeSet(ctx context.Context, id int64, changeSet *string) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	_, err := p.db.ExecContext(ctx, "UPDATE action_log SET change_set = ? WHERE id = ?", changeSet, id)
+	return errors.Wrap(err, "update action_log change_set")
+}
+
+// SetActionNodeID points a log entry at another node ID (undo/redo of a rename).
+func (p *ProjectDB) SetActionNodeID(ctx context.Context, id int64, nodeID string) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	_, err := p.db.ExecContext(ctx, "UPDATE action_log SET node_id = ? WHERE id = ?", nodeID, id)
+	return errors.Wrap(err, "update action_log node_id")
+}
+
+// SetActionUndone marks a log entry's change set as undone (or redone).
+func (p *ProjectDB) SetActionUndone(ctx context.Context, id int64, undone bool) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	_, err := p.db.ExecContext(ctx, "UPDATE action_log SET undone = ? WHERE id = ?", undone, id)
+	return errors.Wrap(err, "update action_log undone")
+}
+
+// DropRedoableChangeSets forgets the change sets of undone entries. A new change invalidates redo.
+func (p *ProjectDB) DropRedoableChangeSets(ctx context.Context) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	_, err := p.db.ExecContext(ctx, "UPDATE action_log SET change_set = NULL WHERE undone = 1 AND change_set IS NOT NULL")
+	return errors.Wrap(err, "drop redoable change sets")
+}
+
+// MaxActionLogID returns the id of the most recently inserted log entry (0 if the log is empty).
+func (p *ProjectDB) MaxActionLogID(ctx context.Context) (int64, error) {
+	if p.db == nil {
+		return 0, errors.New("project db not open")
+	}
+	var id sql.NullInt64
+	if err := p.db.QueryRowContext(ctx, "SELECT MAX(id) FROM action_log").Scan(&id); err != nil {
+		return 0, errors.Wrap(err, "select max action_log id")
+	}
+	return id.Int64, nil
+}
+
+// ReplaceNode overwrites every column of an existing node, keeping its edges.
+func (p *ProjectDB) ReplaceNode(ctx context.Context, node *Node) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	if node == nil {
+		return errors.New("node is nil")
+	}
+
+	var completedAt *string
+	if node.CompletedAt != nil && !node.CompletedAt.IsZero() {
+		s := node.CompletedAt.UTC().Format(time.RFC3339Nano)
+		completedAt = &s
+	}
+	var data *string
+	if len(node.Data) > 0 {
+		s := string(node.Data)
+		data = &s
+	}
+
+	args := []any{
+		node.Type,
+		node.Output,
+		node.Status,
+		node.CreatedBy,
+		node.CreatedAt.UTC().Format(time.RFC3339Nano),
+		completedAt,
+		node.ParentTactic,
+		node.IntroducedAs,
+		data,
+	}
+	extra, err := nodeExtraArgs(node)
+	if err != nil {
+		return err
+	}
+	args = append(args, extra...)
+	args = append(args, node.ID)
+
+	res, err := p.db.ExecContext(ctx, `
+UPDATE nodes SET type = ?, output = ?, status = ?, created_by = ?, created_at = ?, completed_at = ?,
+  parent_tactic = ?, introduced_as = ?, data = ?,
+  assignee = ?, priority = ?, estimate = ?, due_date = ?, labels = ?, tactic_instance = ?, tactic_params = ?,
+  producer_tactic = ?
+WHERE id = ?
+`, args...)
+	if err != nil {
+		return errors.Wrap(err, "update node")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "update node rows affected")
+	}
+	if n == 0 {
+		return errors.Errorf("node not found: %s", node.ID)
+	}
+	return nil
+}
+
+// RenameNode changes a node's ID and rewrites every reference to it (edges,
+// parent_tactic, root_goal and action-log node_ids) in one transaction.
+func (p *ProjectDB) RenameNode(ctx context.Context, oldID, newID string) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	if oldID == newID {
+		return errors.Errorf("node %s already has that ID", oldID)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin tx")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM nodes WHERE id = ?", newID).Scan(&n); err != nil {
+		return errors.Wrap(err, "check new node id")
+	}
+	if n > 0 {
+		return errors.Errorf("node already exists: %s", newID)
+	}
+
+	// Edges point at the old ID until they are rewritten below.
+	if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+		return errors.Wrap(err, "defer foreign keys")
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE nodes SET id = ? WHERE id = ?", newID, oldID)
+	if err != nil {
+		return errors.Wrap(err, "rename node")
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return errors.Wrap(err, "rename node rows affected")
+	} else if affected == 0 {
+		return errors.Errorf("node not found: %s", oldID)
+	}
+
+	for _, q := range []struct{ what, sql string }{
+		{"edge sources", "UPDATE edges SET source_node_id = ? WHERE source_node_id = ?"},
+		{"edge targets", "UPDATE edges SET target_node_id = ? WHERE target_node_id = ?"},
+		{"parent_tactic", "UPDATE nodes SET parent_tactic = ? WHERE parent_tactic = ?"},
+		{"root_goal", "UPDATE project SET value = ? WHERE key = 'root_goal' AND value = ?"},
+		{"action log", "UPDATE action_log SET node_id = ? WHERE node_id = ?"},
+	} {
+		if _, err := tx.ExecContext(ctx, q.sql, newID, oldID); err != nil {
+			return errors.Wrap(err, "rewrite "+q.what)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit tx")
+	}
+	return nil
+}
+
+// RemoveEdge deletes a single edge (no-op if it doesn't exist).
+func (p *ProjectDB) RemoveEdge(ctx context.Context, sourceID, targetID string) error {
+	if p.db == nil {
+		return errors.New("project db not open")
+	}
+	_, err := p.db.ExecContext(ctx, "DELETE FROM edges WHERE source_node_id = ? AND target_node_id = ?", sourceID, targetID)
+	return errors.Wrap(err, "delete edge")
+}
+
 func (p *ProjectDB) GetSessionSummary(ctx context.Context, since *time.Time) (*SessionSummary, error) {
 	logs, err := p.GetActionLog(ctx, nil, since)
 	if err != nil {
@@ -619,6 +839,18 @@ func (p *ProjectDB) ExportToYAML(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	// Edges are grouped once rather than queried per node.
+	edges, err := p.GetEdges(ctx)
+	if err != nil {
+		return "", err
+	}
+	depsOf := map[string][]string{}
+	blocksOf := map[string][]string{}
+	for _, e := range edges {
+		depsOf[e.TargetNodeID] = append(depsOf[e.TargetNodeID], e.SourceNodeID)
+		blocksOf[e.SourceNodeID] = append(blocksOf[e.SourceNodeID], e.TargetNodeID)
+	}
+
 	out := projectYAML{
 		Nodes: map[string]nodeYAML{},
 	}
@@ -643,27 +875,10 @@ func (p *ProjectDB) ExportToYAML(ctx context.Context) (string, error) {
 			entry.CompletedAt = &s
 		}
 
-		deps, err := p.GetDependencies(ctx, n.ID)
-		if err != nil {
-			return "", err
-		}
-		if len(deps) > 0 {
-			entry.Dependencies = &nodeDepsYAML{Match: make([]string, 0, len(deps))}
-			for _, d := range deps {
-				entry.Dependencies.Match = append(entry.Dependencies.Match, d.ID)
-			}
-		}
-
-		blocks, err := p.GetBlockedBy(ctx, n.ID)
-		if err != nil {
-			return "", err
-		}
-		if len(blocks) > 0 {
-			entry.Blocks = make([]string, 0, len(blocks))
-			for _, b := range blocks {
-				entry.Blocks = append(entry.Blocks, b.ID)
-			}
+		if deps := depsOf[n.ID]; len(deps) > 0 {
+			entry.Dependencies = &nodeDepsYAML{Match: deps}
 		}
+		entry.Blocks = blocksOf[n.ID]
 
 		if len(n.Data) > 0 {
 			var m map[string]interface{}
@@ -739,7 +954,7 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	for id, n := range data.Nodes {
 		status := n.Status
 		if status == "" {
-			status = "pending"
+			status = StatusPending
 		}
 
 		createdAt := time.Now().UTC().Format(time.RFC3339Nano)
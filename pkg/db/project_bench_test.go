@@ -0,0 +1,55 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/db/dbtest"
+)
+
+// newLargeProjectDB loads dbtest.SyntheticGraph(n) the way store.Load does. The benchmarks are in
+// package db_test since dbtest imports db.
+func newLargeProjectDB(b *testing.B, n int) *db.ProjectDB {
+	b.Helper()
+	ctx := context.Background()
+	sqlDB, err := db.OpenSQLiteMemory(ctx)
+	if err != nil {
+		b.Fatalf("OpenSQLiteMemory: %v", err)
+	}
+	b.Cleanup(func() { _ = sqlDB.Close() })
+	pdb := db.NewProjectDBFromDB(sqlDB)
+	if err := pdb.InitSchema(ctx); err != nil {
+		b.Fatalf("InitSchema: %v", err)
+	}
+	nodes, edges := dbtest.SyntheticGraph(n)
+	if err := pdb.ImportGraph(ctx, nodes, edges); err != nil {
+		b.Fatalf("ImportGraph: %v", err)
+	}
+	return pdb
+}
+
+func BenchmarkProjectDB_ExportToYAML10k(b *testing.B) {
+	ctx := context.Background()
+	pdb := newLargeProjectDB(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pdb.ExportToYAML(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProjectDB_LoadGraph10k(b *testing.B) {
+	ctx := context.Background()
+	pdb := newLargeProjectDB(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pdb.GetAllNodes(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := pdb.GetEdges(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestProjectDB_RenameNode(t *testing.T) {
+	ctx := context.Background()
+	pdb := newTestProjectDB(t, []string{"a", "b", "c"}, [][2]string{{"a", "b"}, {"b", "c"}})
+
+	parent := "b"
+	child := &Node{ID: "d", Type: "document", Output: "d", ParentTactic: &parent}
+	if err := pdb.AddNode(ctx, child); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := pdb.SetProjectMeta(ctx, "p", "b"); err != nil {
+		t.Fatalf("SetProjectMeta: %v", err)
+	}
+	nodeID := "b"
+	if err := pdb.LogAction(ctx, "node_created", nil, &nodeID, nil); err != nil {
+		t.Fatalf("LogAction: %v", err)
+	}
+
+	if err := pdb.RenameNode(ctx, "b", "c"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a collision error, got %v", err)
+	}
+	if err := pdb.RenameNode(ctx, "missing", "x"); err == nil {
+		t.Fatalf("expected an error renaming a missing node")
+	}
+
+	if err := pdb.RenameNode(ctx, "b", "beta"); err != nil {
+		t.Fatalf("RenameNode: %v", err)
+	}
+
+	edges, err := pdb.GetEdges(ctx)
+	if err != nil {
+		t.Fatalf("GetEdges: %v", err)
+	}
+	var got []string
+	for _, e := range edges {
+		got = append(got, e.SourceNodeID+"->"+e.TargetNodeID)
+	}
+	if want := []string{"a->beta", "beta->c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected edges %v, got %v", want, got)
+	}
+
+	d, err := pdb.GetNode(ctx, "d")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if d.ParentTactic == nil || *d.ParentTactic != "beta" {
+		t.Fatalf("expected parent_tactic to be rewritten, got %v", d.ParentTactic)
+	}
+	meta, err := pdb.GetProjectMeta(ctx)
+	if err != nil {
+		t.Fatalf("GetProjectMeta: %v", err)
+	}
+	if meta["root_goal"] != "beta" {
+		t.Fatalf("expected root_goal beta, got %q", meta["root_goal"])
+	}
+	logs, err := pdb.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetActionLog: %v", err)
+	}
+	if len(logs) != 1 || logs[0].NodeID == nil || *logs[0].NodeID != "beta" {
+		t.Fatalf("expected the log entry to point at beta, got %+v", logs)
+	}
+}
+
+func TestProjectDB_ExportToYAML(t *testing.T) {
+	ctx := context.Background()
+	pdb := newTestProjectDB(t, []string{"a", "b", "c"}, [][2]string{{"a", "b"}, {"a", "c"}, {"b", "c"}})
+
+	out, err := pdb.ExportToYAML(ctx)
+	if err != nil {
+		t.Fatalf("ExportToYAML: %v", err)
+	}
+	var got struct {
+		Nodes map[string]struct {
+			Dependencies *struct {
+				Match []string `yaml:"match"`
+			} `yaml:"dependencies"`
+			Blocks []string `yaml:"blocks"`
+		} `yaml:"nodes"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if a := got.Nodes["a"]; a.Dependencies != nil || !reflect.DeepEqual(a.Blocks, []string{"b", "c"}) {
+		t.Fatalf("unexpected node a: %+v", a)
+	}
+	if c := got.Nodes["c"]; c.Dependencies == nil || !reflect.DeepEqual(c.Dependencies.Match, []string{"a", "b"}) || c.Blocks != nil {
+		t.Fatalf("unexpected node c: %+v", c)
+	}
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sync/atomic"
 
 	_ "modernc.org/sqlite"
 
@@ -36,10 +38,13 @@ func openSQLite(ctx context.Context, path string) (*sql.DB, error) {
 	return db, nil
 }
 
+var memoryDBCounter atomic.Int64
+
 // OpenSQLiteMemory opens a shared in-memory sqlite database.
 // This is the only runtime mode we want for tactician: YAML on disk, sqlite in memory.
 func OpenSQLiteMemory(ctx context.Context) (*sql.DB, error) {
-	// "file::memory:?cache=shared" allows the driver to share an in-memory database across connections.
-	// We still treat the returned *sql.DB as a single handle for the process.
-	return openSQLite(ctx, "file::memory:?cache=shared")
+	// "mode=memory&cache=shared" allows the driver to share an in-memory database across the pool's
+	// connections. Each call gets its own name so two states loaded by the same process stay separate.
+	name := fmt.Sprintf("file:tactician-%d?mode=memory&cache=shared", memoryDBCounter.Add(1))
+	return openSQLite(ctx, name)
 }
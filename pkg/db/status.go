@@ -0,0 +1,123 @@
+package db
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Node lifecycle statuses as stored in project.yaml.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusOnHold     = "on_hold"
+	StatusComplete   = "complete"
+	StatusCancelled  = "cancelled"
+	StatusWontDo     = "wont_do"
+)
+
+// Statuses lists every stored status, in lifecycle order.
+var Statuses = []string{StatusPending, StatusInProgress, StatusOnHold, StatusComplete, StatusCancelled, StatusWontDo}
+
+// Derived statuses, computed from a node's status and its dependencies (see StatusPolicy.Derive).
+const (
+	DerivedReady   = "ready"
+	DerivedBlocked = "blocked"
+)
+
+// IsKnownStatus reports whether s is one of Statuses.
+func IsKnownStatus(s string) bool {
+	for _, status := range Statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusPolicy decides which statuses satisfy dependents and which status transitions are legal.
+type StatusPolicy struct {
+	// Satisfying statuses unblock dependents. complete always does.
+	Satisfying map[string]bool
+	// Transitions maps a status to the statuses it may move to.
+	Transitions map[string][]string
+}
+
+// DefaultStatusPolicy lets cancelled and wont_do satisfy dependents (the work is not going to happen,
+// so nothing should wait for it) and allows every transition except closing an on_hold node directly.
+func DefaultStatusPolicy() *StatusPolicy {
+	return &StatusPolicy{
+		Satisfying: map[string]bool{
+			StatusComplete:  true,
+			StatusCancelled: true,
+			StatusWontDo:    true,
+		},
+		Transitions: map[string][]string{
+			StatusPending:    {StatusInProgress, StatusOnHold, StatusComplete, StatusCancelled, StatusWontDo},
+			StatusInProgress: {StatusPending, StatusOnHold, StatusComplete, StatusCancelled, StatusWontDo},
+			StatusOnHold:     {StatusPending, StatusInProgress, StatusCancelled, StatusWontDo},
+			StatusComplete:   {StatusPending, StatusInProgress},
+			StatusCancelled:  {StatusPending},
+			StatusWontDo:     {StatusPending},
+		},
+	}
+}
+
+// Satisfies reports whether a dependency in this status unblocks its dependents.
+func (p *StatusPolicy) Satisfies(status string) bool {
+	return p.Satisfying[status]
+}
+
+// IsOpen reports whether a node in this status still represents work to do (pending, in_progress or
+// on_hold).
+func (p *StatusPolicy) IsOpen(status string) bool {
+	switch status {
+	case StatusPending, StatusInProgress, StatusOnHold:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckTransition returns an error if a node may not move from one status to another.
+func (p *StatusPolicy) CheckTransition(from, to string) error {
+	if !IsKnownStatus(to) {
+		return errors.Errorf("unknown status %q (expected one of %s)", to, strings.Join(Statuses, ", "))
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range p.Transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	allowed := append([]string(nil), p.Transitions[from]...)
+	sort.Strings(allowed)
+	if len(allowed) == 0 {
+		return errors.Errorf("illegal status transition %s -> %s (%s is final)", from, to, from)
+	}
+	return errors.Errorf("illegal status transition %s -> %s (allowed: %s)", from, to, strings.Join(allowed, ", "))
+}
+
+// Derive computes the status shown by goals/graph: closed nodes keep their status, on_hold is a manual
+// block, otherwise a node is blocked until every dependency is in a satisfying status. Unblocked pending
+// nodes are ready; unblocked in_progress nodes stay in_progress.
+func (p *StatusPolicy) Derive(n *Node, deps []*Node) string {
+	if !p.IsOpen(n.Status) {
+		return n.Status
+	}
+	if n.Status == StatusOnHold {
+		return StatusOnHold
+	}
+	for _, d := range deps {
+		if !p.Satisfies(d.Status) {
+			return DerivedBlocked
+		}
+	}
+	if n.Status == StatusInProgress {
+		return StatusInProgress
+	}
+	return DerivedReady
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatusPolicy_CheckTransition(t *testing.T) {
+	p := DefaultStatusPolicy()
+
+	for _, tc := range []struct {
+		from, to string
+		ok       bool
+	}{
+		{StatusPending, StatusInProgress, true},
+		{StatusInProgress, StatusComplete, true},
+		{StatusPending, StatusPending, true},
+		{StatusOnHold, StatusComplete, false},
+		{StatusCancelled, StatusComplete, false},
+		{StatusPending, "done", false},
+	} {
+		err := p.CheckTransition(tc.from, tc.to)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s -> %s: ok=%v, got err=%v", tc.from, tc.to, tc.ok, err)
+		}
+	}
+
+	err := p.CheckTransition(StatusOnHold, StatusComplete)
+	if err == nil || !strings.Contains(err.Error(), "on_hold -> complete") {
+		t.Fatalf("expected the error to name the transition, got %v", err)
+	}
+}
+
+func TestStatusPolicy_Derive(t *testing.T) {
+	p := DefaultStatusPolicy()
+	node := func(status string) *Node { return &Node{ID: status, Status: status} }
+
+	for _, tc := range []struct {
+		name string
+		n    *Node
+		deps []*Node
+		want string
+	}{
+		{"no deps", node(StatusPending), nil, DerivedReady},
+		{"dep complete", node(StatusPending), []*Node{node(StatusComplete)}, DerivedReady},
+		{"dep cancelled satisfies", node(StatusPending), []*Node{node(StatusCancelled)}, DerivedReady},
+		{"dep in progress blocks", node(StatusPending), []*Node{node(StatusInProgress)}, DerivedBlocked},
+		{"in progress unblocked", node(StatusInProgress), nil, StatusInProgress},
+		{"in progress blocked", node(StatusInProgress), []*Node{node(StatusPending)}, DerivedBlocked},
+		{"on hold is a manual block", node(StatusOnHold), nil, StatusOnHold},
+		{"closed keeps status", node(StatusWontDo), []*Node{node(StatusPending)}, StatusWontDo},
+	} {
+		if got := p.Derive(tc.n, tc.deps); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	// Without cancelled in the satisfying set, a cancelled dependency blocks.
+	delete(p.Satisfying, StatusCancelled)
+	if got := p.Derive(node(StatusPending), []*Node{node(StatusCancelled)}); got != DerivedBlocked {
+		t.Fatalf("expected cancelled dep to block when not satisfying, got %s", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -29,7 +30,10 @@ CREATE TABLE IF NOT EXISTS tactics (
   output TEXT NOT NULL,
   description TEXT,
   tags TEXT,
-  data TEXT
+  data TEXT,
+  params TEXT,
+  definition TEXT,
+  source TEXT
 );
 
 CREATE TABLE IF NOT EXISTS tactic_dependencies (
@@ -37,6 +41,7 @@ CREATE TABLE IF NOT EXISTS tactic_dependencies (
   tactic_id TEXT NOT NULL,
   dependency_type TEXT NOT NULL,
   artifact_type TEXT NOT NULL,
+  kind TEXT NOT NULL DEFAULT 'output',
   FOREIGN KEY (tactic_id) REFERENCES tactics(id) ON DELETE CASCADE
 );
 
@@ -48,6 +53,8 @@ CREATE TABLE IF NOT EXISTS tactic_subtasks (
   type TEXT NOT NULL,
   depends_on TEXT,
   data TEXT,
+  condition TEXT,
+  optional INTEGER NOT NULL DEFAULT 0,
   FOREIGN KEY (tactic_id) REFERENCES tactics(id) ON DELETE CASCADE
 );
 
@@ -59,14 +66,158 @@ CREATE INDEX IF NOT EXISTS idx_tactic_subtasks ON tactic_subtasks(tactic_id);
 	return errors.Wrap(err, "init tactics schema")
 }
 
+// AddTactic stores a tactic, replacing one with the same id. See AddTactics.
 func (t *TacticsDB) AddTactic(ctx context.Context, tactic *Tactic) error {
+	return t.AddTactics(ctx, []*Tactic{tactic})
+}
+
+// AddTactics stores tactics as written, then resolves extends/include for the composed ones and for
+// every stored tactic composed from them. Adding them together lets them reference each other in any order.
+func (t *TacticsDB) AddTactics(ctx context.Context, tactics []*Tactic) error {
 	if t.db == nil {
 		return errors.New("tactics db not open")
 	}
-	if tactic == nil {
-		return errors.New("nil tactic")
+	var ids []string
+	for _, tactic := range tactics {
+		if tactic == nil {
+			return errors.New("nil tactic")
+		}
+		var definition *string
+		if tactic.IsComposed() {
+			b, err := json.Marshal(tactic)
+			if err != nil {
+				return errors.Wrap(err, "marshal tactic definition")
+			}
+			s := string(b)
+			definition = &s
+		}
+		if err := t.storeTactic(ctx, tactic, definition); err != nil {
+			return err
+		}
+		ids = append(ids, tactic.ID)
+	}
+	return t.resolveComposed(ctx, ids)
+}
+
+// resolveComposed re-resolves the composed tactics that are among ids or extend/include them, directly
+// or not, and stores the flattened result next to the definition.
+func (t *TacticsDB) resolveComposed(ctx context.Context, ids []string) error {
+	defs, err := t.composedDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	if len(defs) == 0 {
+		return nil
+	}
+
+	changed := map[string]bool{}
+	for _, id := range ids {
+		changed[id] = true
+	}
+	for progress := true; progress; {
+		progress = false
+		for id, def := range defs {
+			if changed[id] {
+				continue
+			}
+			for _, ref := range def.References() {
+				if changed[ref] {
+					changed[id] = true
+					progress = true
+					break
+				}
+			}
+		}
+	}
+
+	lookup := func(id string) (*Tactic, error) { return t.GetTacticDefinition(ctx, id) }
+	for id, def := range defs {
+		if !changed[id] {
+			continue
+		}
+		resolved, err := ResolveTactic(def, lookup)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(def)
+		if err != nil {
+			return errors.Wrap(err, "marshal tactic definition")
+		}
+		definition := string(b)
+		if err := t.storeTactic(ctx, resolved, &definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TacticsDB) composedDefinitions(ctx context.Context) (map[string]*Tactic, error) {
+	rows, err := t.db.QueryContext(ctx, "SELECT id, definition FROM tactics WHERE definition IS NOT NULL")
+	if err != nil {
+		return nil, errors.Wrap(err, "select tactic definitions")
+	}
+	defer func() { _ = rows.Close() }()
+
+	ret := map[string]*Tactic{}
+	for rows.Next() {
+		var id, definition string
+		if err := rows.Scan(&id, &definition); err != nil {
+			return nil, errors.Wrap(err, "scan tactic definition")
+		}
+		var def Tactic
+		if err := json.Unmarshal([]byte(definition), &def); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal definition of tactic %s", id)
+		}
+		ret[id] = &def
+	}
+	return ret, errors.Wrap(rows.Err(), "iterate tactic definitions")
+}
+
+// GetTacticDefinition returns a tactic as written, with extends/include unresolved (nil if unknown).
+// For tactics that don't compose others it is the same as GetTactic.
+func (t *TacticsDB) GetTacticDefinition(ctx context.Context, id string) (*Tactic, error) {
+	if t.db == nil {
+		return nil, errors.New("tactics db not open")
+	}
+	var definition sql.NullString
+	err := t.db.QueryRowContext(ctx, "SELECT definition FROM tactics WHERE id = ?", id).Scan(&definition)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "select tactic definition")
+	}
+	if !definition.Valid {
+		return t.GetTactic(ctx, id)
+	}
+	var def Tactic
+	if err := json.Unmarshal([]byte(definition.String), &def); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal definition of tactic %s", id)
+	}
+	return &def, nil
+}
+
+// GetAllTacticDefinitions returns every tactic as written (what the tactics dir stores).
+func (t *TacticsDB) GetAllTacticDefinitions(ctx context.Context) ([]*Tactic, error) {
+	ids, err := t.tacticIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Tactic
+	for _, id := range ids {
+		def, err := t.GetTacticDefinition(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if def != nil {
+			ret = append(ret, def)
+		}
 	}
+	return ret, nil
+}
 
+// storeTactic writes one tactic's rows; definition is the unresolved form of a composed tactic.
+func (t *TacticsDB) storeTactic(ctx context.Context, tactic *Tactic, definition *string) error {
 	var tags *string
 	if len(tactic.Tags) > 0 {
 		s := strings.Join(tactic.Tags, ",")
@@ -83,6 +234,16 @@ func (t *TacticsDB) AddTactic(ctx context.Context, tactic *Tactic) error {
 		data = &s
 	}
 
+	var params *string
+	if len(tactic.Params) > 0 {
+		b, err := json.Marshal(tactic.Params)
+		if err != nil {
+			return errors.Wrap(err, "marshal tactic params")
+		}
+		s := string(b)
+		params = &s
+	}
+
 	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "begin tx")
@@ -90,9 +251,9 @@ func (t *TacticsDB) AddTactic(ctx context.Context, tactic *Tactic) error {
 	defer func() { _ = tx.Rollback() }()
 
 	_, err = tx.ExecContext(ctx, `
-INSERT OR REPLACE INTO tactics (id, type, output, description, tags, data)
-VALUES (?, ?, ?, ?, ?, ?)
-`, tactic.ID, tactic.Type, tactic.Output, nullIfEmpty(tactic.Description), tags, data)
+INSERT OR REPLACE INTO tactics (id, type, output, description, tags, data, params, definition, source)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, tactic.ID, tactic.Type, tactic.Output, nullIfEmpty(tactic.Description), tags, data, params, definition, nullIfEmpty(tactic.Source))
 	if err != nil {
 		return errors.Wrap(err, "upsert tactic")
 	}
@@ -106,8 +267,8 @@ VALUES (?, ?, ?, ?, ?, ?)
 	}
 
 	depStmt, err := tx.PrepareContext(ctx, `
-INSERT INTO tactic_dependencies (tactic_id, dependency_type, artifact_type)
-VALUES (?, ?, ?)
+INSERT INTO tactic_dependencies (tactic_id, dependency_type, artifact_type, kind)
+VALUES (?, ?, ?, ?)
 `)
 	if err != nil {
 		return errors.Wrap(err, "prepare dep insert")
@@ -115,12 +276,12 @@ VALUES (?, ?, ?)
 	defer func() { _ = depStmt.Close() }()
 
 	for _, a := range tactic.Match {
-		if _, err := depStmt.ExecContext(ctx, tactic.ID, "match", a); err != nil {
+		if _, err := depStmt.ExecContext(ctx, tactic.ID, "match", a, PredicateKind(a)); err != nil {
 			return errors.Wrap(err, "insert match dependency")
 		}
 	}
 	for _, a := range tactic.Premises {
-		if _, err := depStmt.ExecContext(ctx, tactic.ID, "premise", a); err != nil {
+		if _, err := depStmt.ExecContext(ctx, tactic.ID, "premise", a, PredicateKind(a)); err != nil {
 			return errors.Wrap(err, "insert premise dependency")
 		}
 	}
@@ -160,6 +321,12 @@ VALUES (?, ?, ?)
 					if v, ok := m["data"].(map[string]interface{}); ok {
 						st.Data = v
 					}
+					if v, ok := m["when"].(string); ok {
+						st.When = v
+					}
+					if v, ok := m["optional"].(bool); ok {
+						st.Optional = v
+					}
 					if st.ID != "" {
 						subtasks = append(subtasks, st)
 					}
@@ -170,8 +337,8 @@ VALUES (?, ?, ?)
 
 	if len(subtasks) > 0 {
 		subStmt, err := tx.PrepareContext(ctx, `
-INSERT INTO tactic_subtasks (tactic_id, subtask_id, output, type, depends_on, data)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO tactic_subtasks (tactic_id, subtask_id, output, type, depends_on, data, condition, optional)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 `)
 		if err != nil {
 			return errors.Wrap(err, "prepare subtask insert")
@@ -195,7 +362,12 @@ VALUES (?, ?, ?, ?, ?, ?)
 				stData = &s
 			}
 
-			if _, err := subStmt.ExecContext(ctx, tactic.ID, st.ID, st.Output, st.Type, dependsOn, stData); err != nil {
+			var condition *string
+			if st.When != "" {
+				condition = &st.When
+			}
+
+			if _, err := subStmt.ExecContext(ctx, tactic.ID, st.ID, st.Output, st.Type, dependsOn, stData, condition, st.Optional); err != nil {
 				return errors.Wrap(err, "insert subtask")
 			}
 		}
@@ -207,15 +379,39 @@ VALUES (?, ?, ?, ?, ?, ?)
 	return nil
 }
 
+// DeleteTactic removes a tactic with its dependencies and subtasks. It refuses while other tactics
+// extend or include it; deleting an unknown id is a no-op.
+func (t *TacticsDB) DeleteTactic(ctx context.Context, id string) error {
+	if t.db == nil {
+		return errors.New("tactics db not open")
+	}
+	defs, err := t.composedDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	var users []string
+	for other, def := range defs {
+		if other != id && contains(def.References(), id) {
+			users = append(users, other)
+		}
+	}
+	if len(users) > 0 {
+		sort.Strings(users)
+		return errors.Errorf("tactic %s is extended or included by %s", id, strings.Join(users, ", "))
+	}
+	_, err = t.db.ExecContext(ctx, "DELETE FROM tactics WHERE id = ?", id)
+	return errors.Wrap(err, "delete tactic")
+}
+
 func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 	if t.db == nil {
 		return nil, errors.New("tactics db not open")
 	}
 
-	row := t.db.QueryRowContext(ctx, "SELECT id, type, output, description, tags, data FROM tactics WHERE id = ?", id)
+	row := t.db.QueryRowContext(ctx, "SELECT id, type, output, description, tags, data, params, source FROM tactics WHERE id = ?", id)
 	var tactic Tactic
-	var description, tags, data sql.NullString
-	if err := row.Scan(&tactic.ID, &tactic.Type, &tactic.Output, &description, &tags, &data); err != nil {
+	var description, tags, data, params, source sql.NullString
+	if err := row.Scan(&tactic.ID, &tactic.Type, &tactic.Output, &description, &tags, &data, &params, &source); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -224,6 +420,9 @@ func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 	if description.Valid {
 		tactic.Description = description.String
 	}
+	if source.Valid {
+		tactic.Source = source.String
+	}
 	if tags.Valid && tags.String != "" {
 		tactic.Tags = strings.Split(tags.String, ",")
 	}
@@ -234,6 +433,11 @@ func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 		}
 		tactic.Data = m
 	}
+	if params.Valid && params.String != "" {
+		if err := json.Unmarshal([]byte(params.String), &tactic.Params); err != nil {
+			return nil, errors.Wrap(err, "unmarshal tactic params")
+		}
+	}
 
 	// Dependencies
 	depRows, err := t.db.QueryContext(ctx, "SELECT dependency_type, artifact_type FROM tactic_dependencies WHERE tactic_id = ?", id)
@@ -259,7 +463,7 @@ func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 	}
 
 	// Subtasks
-	subRows, err := t.db.QueryContext(ctx, "SELECT subtask_id, output, type, depends_on, data FROM tactic_subtasks WHERE tactic_id = ? ORDER BY id", id)
+	subRows, err := t.db.QueryContext(ctx, "SELECT subtask_id, output, type, depends_on, data, condition, optional FROM tactic_subtasks WHERE tactic_id = ? ORDER BY id", id)
 	if err != nil {
 		return nil, errors.Wrap(err, "select subtasks")
 	}
@@ -267,10 +471,11 @@ func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 
 	for subRows.Next() {
 		var st TacticSubtask
-		var dependsOn, stData sql.NullString
-		if err := subRows.Scan(&st.ID, &st.Output, &st.Type, &dependsOn, &stData); err != nil {
+		var dependsOn, stData, condition sql.NullString
+		if err := subRows.Scan(&st.ID, &st.Output, &st.Type, &dependsOn, &stData, &condition, &st.Optional); err != nil {
 			return nil, errors.Wrap(err, "scan subtask")
 		}
+		st.When = condition.String
 		if dependsOn.Valid && dependsOn.String != "" {
 			st.DependsOn = strings.Split(dependsOn.String, ",")
 		}
@@ -290,7 +495,26 @@ func (t *TacticsDB) GetTactic(ctx context.Context, id string) (*Tactic, error) {
 	return &tactic, nil
 }
 
+// GetAllTactics returns every tactic, with extends/include resolved.
 func (t *TacticsDB) GetAllTactics(ctx context.Context) ([]*Tactic, error) {
+	ids, err := t.tacticIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Tactic
+	for _, id := range ids {
+		tactic, err := t.GetTactic(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if tactic != nil {
+			ret = append(ret, tactic)
+		}
+	}
+	return ret, nil
+}
+
+func (t *TacticsDB) tacticIDs(ctx context.Context) ([]string, error) {
 	if t.db == nil {
 		return nil, errors.New("tactics db not open")
 	}
@@ -301,29 +525,26 @@ func (t *TacticsDB) GetAllTactics(ctx context.Context) ([]*Tactic, error) {
 	}
 	defer func() { _ = rows.Close() }()
 
-	var ret []*Tactic
+	var ids []string
 	for rows.Next() {
 		var id string
 		if err := rows.Scan(&id); err != nil {
 			return nil, errors.Wrap(err, "scan tactic id")
 		}
-		tactic, err := t.GetTactic(ctx, id)
-		if err != nil {
-			return nil, err
-		}
-		if tactic != nil {
-			ret = append(ret, tactic)
-		}
+		ids = append(ids, id)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, errors.Wrap(err, "iterate tactic ids")
 	}
-	return ret, nil
+	return ids, nil
 }
 
 // SearchTactics provides a minimal filter layer similar to JS searchTactics(filters).
 // Ranking logic lives in the command/helper layer.
-func (t *TacticsDB) SearchTactics(ctx context.Context, typeFilter string, tags []string, keywords []string) ([]*Tactic, error) {
+//
+// When available is non-nil, tactics with a plain-output match dependency that isn't in it are
+// left out; match predicates of other kinds are left to the caller to evaluate.
+func (t *TacticsDB) SearchTactics(ctx context.Context, typeFilter string, tags []string, keywords []string, available []string) ([]*Tactic, error) {
 	if t.db == nil {
 		return nil, errors.New("tactics db not open")
 	}
@@ -360,6 +581,19 @@ func (t *TacticsDB) SearchTactics(ctx context.Context, typeFilter string, tags [
 		}
 	}
 
+	if available != nil {
+		b, err := json.Marshal(available)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal available outputs")
+		}
+		conditions = append(conditions, `NOT EXISTS (
+  SELECT 1 FROM tactic_dependencies d
+  WHERE d.tactic_id = t.id AND d.dependency_type = 'match' AND d.kind = ?
+    AND d.artifact_type NOT IN (SELECT value FROM json_each(?))
+)`)
+		params = append(params, PredicateOutput, string(b))
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
@@ -391,6 +625,15 @@ func (t *TacticsDB) SearchTactics(ctx context.Context, typeFilter string, tags [
 	return ret, nil
 }
 
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
 func nullIfEmpty(s string) any {
 	if s == "" {
 		return nil
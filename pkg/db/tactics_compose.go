@@ -0,0 +1,200 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TacticInclude pulls another tactic's subtasks into a tactic as a block.
+type TacticInclude struct {
+	Tactic string `yaml:"tactic" json:"tactic"`
+	// Prefix is prepended (joined with "_") to the included subtask IDs and
+	// outputs. It defaults to the included tactic's ID.
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	// DependsOn lists subtasks of the including tactic that the block's first
+	// subtasks wait for.
+	DependsOn []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+}
+
+// IsComposed reports whether the tactic extends or includes other tactics.
+func (t *Tactic) IsComposed() bool {
+	return t.Extends != "" || len(t.Include) > 0
+}
+
+// References lists the tactics t extends or includes.
+func (t *Tactic) References() []string {
+	var ret []string
+	if t.Extends != "" {
+		ret = append(ret, t.Extends)
+	}
+	for _, inc := range t.Include {
+		ret = append(ret, inc.Tactic)
+	}
+	return ret
+}
+
+// ResolveTactic flattens extends and include into a plain tactic. lookup
+// returns a tactic's definition as written (nil if there is none).
+//
+// Extending inherits type, output, description and subtasks unless the tactic
+// sets its own; tags, match, premises and params are merged and data is merged
+// key by key, the extending tactic winning. Each include adds the included
+// tactic's subtasks (or its single output) with prefixed IDs and outputs, after
+// the tactic's own subtasks, and merges its match, premises and params.
+func ResolveTactic(t *Tactic, lookup func(id string) (*Tactic, error)) (*Tactic, error) {
+	return resolveTactic(t, lookup, nil)
+}
+
+func resolveTactic(t *Tactic, lookup func(id string) (*Tactic, error), stack []string) (*Tactic, error) {
+	for i, id := range stack {
+		if id == t.ID {
+			return nil, errors.Errorf("tactic composition cycle: %s", strings.Join(append(stack[i:], t.ID), " -> "))
+		}
+	}
+	out := *t
+	out.Extends = ""
+	out.Include = nil
+	if !t.IsComposed() {
+		return &out, nil
+	}
+	stack = append(append([]string{}, stack...), t.ID)
+
+	resolveRef := func(id, how string) (*Tactic, error) {
+		def, err := lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		if def == nil {
+			return nil, errors.Errorf("tactic %s %s unknown tactic %s", t.ID, how, id)
+		}
+		return resolveTactic(def, lookup, stack)
+	}
+
+	if t.Extends != "" {
+		base, err := resolveRef(t.Extends, "extends")
+		if err != nil {
+			return nil, err
+		}
+		if out.Type == "" {
+			out.Type = base.Type
+		}
+		if out.Output == "" {
+			out.Output = base.Output
+		}
+		if out.Description == "" {
+			out.Description = base.Description
+		}
+		if len(out.Subtasks) == 0 {
+			out.Subtasks = base.Subtasks
+		}
+		out.Tags = unionStrings(base.Tags, t.Tags)
+		out.Match = unionStrings(base.Match, t.Match)
+		out.Premises = unionStrings(base.Premises, t.Premises)
+		out.Params = mergeParams(base.Params, t.Params)
+		out.Data = mergeData(base.Data, t.Data)
+	}
+
+	var included []TacticSubtask
+	for _, inc := range t.Include {
+		sub, err := resolveRef(inc.Tactic, "includes")
+		if err != nil {
+			return nil, err
+		}
+		prefix := inc.Prefix
+		if prefix == "" {
+			prefix = sub.ID
+		}
+		included = append(included, includeBlock(sub, prefix, inc.DependsOn)...)
+		out.Match = unionStrings(out.Match, sub.Match)
+		out.Premises = unionStrings(out.Premises, sub.Premises)
+		out.Params = mergeParams(sub.Params, out.Params)
+	}
+	if len(included) > 0 {
+		out.Subtasks = append(append([]TacticSubtask{}, out.Subtasks...), included...)
+	}
+
+	return &out, nil
+}
+
+// includeBlock copies sub's subtasks (or one subtask for its output) with
+// prefixed IDs and outputs; the block's first subtasks wait for dependsOn.
+func includeBlock(sub *Tactic, prefix string, dependsOn []string) []TacticSubtask {
+	subtasks := sub.Subtasks
+	if len(subtasks) == 0 {
+		subtasks = []TacticSubtask{{ID: sub.Output, Type: sub.Type, Output: sub.Output, Data: sub.Data}}
+	}
+	ret := make([]TacticSubtask, 0, len(subtasks))
+	for _, st := range subtasks {
+		block := TacticSubtask{
+			ID:     prefix + "_" + st.ID,
+			Type:   st.Type,
+			Output: prefix + "_" + st.Output,
+			Data:   st.Data,
+			When:   st.When,
+			// An optional subtask is asked for by its prefixed id.
+			Optional: st.Optional,
+		}
+		for _, dep := range st.DependsOn {
+			block.DependsOn = append(block.DependsOn, prefix+"_"+dep)
+		}
+		if len(st.DependsOn) == 0 {
+			block.DependsOn = append(block.DependsOn, dependsOn...)
+		}
+		ret = append(ret, block)
+	}
+	return ret
+}
+
+func unionStrings(a, b []string) []string {
+	var ret []string
+	seen := map[string]bool{}
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				ret = append(ret, s)
+			}
+		}
+	}
+	return ret
+}
+
+// mergeParams returns base's params with override's replacing them by name.
+func mergeParams(base, override []TacticParam) []TacticParam {
+	var ret []TacticParam
+	overridden := map[string]bool{}
+	for _, p := range override {
+		overridden[p.Name] = true
+	}
+	for _, p := range base {
+		if !overridden[p.Name] {
+			ret = append(ret, p)
+		}
+	}
+	return append(ret, override...)
+}
+
+// mergeData merges override into a copy of base, recursing into nested maps.
+func mergeData(base, override map[string]interface{}) map[string]interface{} {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	ret := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		ret[k] = v
+	}
+	for k, v := range override {
+		bm, bok := ret[k].(map[string]interface{})
+		om, ook := v.(map[string]interface{})
+		if bok && ook {
+			ret[k] = mergeData(bm, om)
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
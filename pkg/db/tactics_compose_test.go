@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func subtaskSummary(subtasks []TacticSubtask) string {
+	var parts []string
+	for _, st := range subtasks {
+		s := st.ID + ":" + st.Output
+		if len(st.DependsOn) > 0 {
+			s += "<-" + strings.Join(st.DependsOn, "+")
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestResolveTactic(t *testing.T) {
+	library := map[string]*Tactic{
+		"base": {
+			ID:       "base",
+			Type:     "code",
+			Output:   "code",
+			Tags:     []string{"backend"},
+			Match:    []string{"spec"},
+			Params:   []TacticParam{{Name: "lang", Default: "go"}, {Name: "style"}},
+			Data:     map[string]interface{}{"review": map[string]interface{}{"required": true, "owners": 1}, "ci": "basic"},
+			Subtasks: []TacticSubtask{{ID: "write", Type: "code", Output: "code"}},
+		},
+		"review": {
+			ID:       "review",
+			Type:     "review",
+			Output:   "approval",
+			Premises: []string{"guidelines"},
+			Subtasks: []TacticSubtask{
+				{ID: "read", Type: "review", Output: "notes"},
+				{ID: "sign", Type: "review", Output: "approval", DependsOn: []string{"read"}},
+			},
+		},
+		"lint": {ID: "lint", Type: "check", Output: "lint_report"},
+	}
+	lookup := func(id string) (*Tactic, error) { return library[id], nil }
+
+	child := &Tactic{
+		ID:      "service",
+		Extends: "base",
+		Tags:    []string{"service"},
+		Params:  []TacticParam{{Name: "lang", Default: "rust"}},
+		Data:    map[string]interface{}{"review": map[string]interface{}{"owners": 2}},
+		Include: []TacticInclude{
+			{Tactic: "review", Prefix: "qa", DependsOn: []string{"write"}},
+			{Tactic: "lint"},
+		},
+	}
+	got, err := ResolveTactic(child, lookup)
+	if err != nil {
+		t.Fatalf("ResolveTactic: %v", err)
+	}
+	if got.Type != "code" || got.Output != "code" || got.Extends != "" || got.Include != nil {
+		t.Fatalf("expected type/output inherited and composition flattened, got %+v", got)
+	}
+	if strings.Join(got.Tags, ",") != "backend,service" || strings.Join(got.Match, ",") != "spec" ||
+		strings.Join(got.Premises, ",") != "guidelines" {
+		t.Fatalf("unexpected tags/match/premises: %v %v %v", got.Tags, got.Match, got.Premises)
+	}
+	if len(got.Params) != 2 || got.Params[0].Name != "style" || got.Params[1].Default != "rust" {
+		t.Fatalf("expected the child's lang param to override the base's, got %+v", got.Params)
+	}
+	review := got.Data["review"].(map[string]interface{})
+	if review["required"] != true || review["owners"] != 2 || got.Data["ci"] != "basic" {
+		t.Fatalf("expected data merged key by key, got %v", got.Data)
+	}
+	want := "write:code qa_read:qa_notes<-write qa_sign:qa_approval<-qa_read lint_lint_report:lint_lint_report"
+	if s := subtaskSummary(got.Subtasks); s != want {
+		t.Fatalf("expected subtasks %q, got %q", want, s)
+	}
+	if library["review"].Subtasks[0].ID != "read" || len(library["base"].Tags) != 1 {
+		t.Fatalf("resolving must not modify the referenced tactics")
+	}
+
+	library["service"] = child
+	library["base"].Include = []TacticInclude{{Tactic: "service"}}
+	if _, err := ResolveTactic(child, lookup); err == nil || !strings.Contains(err.Error(), "service -> base -> service") {
+		t.Fatalf("expected a composition cycle, got %v", err)
+	}
+	if _, err := ResolveTactic(&Tactic{ID: "x", Extends: "nope"}, lookup); err == nil || !strings.Contains(err.Error(), "extends unknown tactic nope") {
+		t.Fatalf("expected an unknown base error, got %v", err)
+	}
+}
+
+func TestTacticsDB_ComposedTactics(t *testing.T) {
+	ctx := context.Background()
+
+	sqlDB, err := OpenSQLiteMemory(ctx)
+	if err != nil {
+		t.Fatalf("OpenSQLiteMemory: %v", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+
+	tdb := NewTacticsDBFromDB(sqlDB)
+	if err := tdb.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+
+	// The child comes first: tactics added together may reference each other in any order.
+	child := &Tactic{ID: "child", Extends: "base", Match: []string{"design"}}
+	base := &Tactic{ID: "base", Type: "document", Output: "spec", Match: []string{"requirements"}}
+	if err := tdb.AddTactics(ctx, []*Tactic{child, base}); err != nil {
+		t.Fatalf("AddTactics: %v", err)
+	}
+
+	got, err := tdb.GetTactic(ctx, "child")
+	if err != nil || got == nil || got.Output != "spec" || strings.Join(got.Match, ",") != "requirements,design" {
+		t.Fatalf("expected the resolved child, got %+v, %v", got, err)
+	}
+	def, err := tdb.GetTacticDefinition(ctx, "child")
+	if err != nil || def == nil || def.Extends != "base" || def.Output != "" {
+		t.Fatalf("expected the child as written, got %+v, %v", def, err)
+	}
+
+	// Changing the base re-resolves the tactics built on it.
+	base.Output = "design_doc"
+	if err := tdb.AddTactic(ctx, base); err != nil {
+		t.Fatalf("AddTactic: %v", err)
+	}
+	if got, _ := tdb.GetTactic(ctx, "child"); got == nil || got.Output != "design_doc" {
+		t.Fatalf("expected child to follow its base, got %+v", got)
+	}
+
+	if err := tdb.DeleteTactic(ctx, "base"); err == nil || !strings.Contains(err.Error(), "extended or included by child") {
+		t.Fatalf("expected deleting a base to be refused, got %v", err)
+	}
+	if err := tdb.AddTactic(ctx, &Tactic{ID: "base", Type: "document", Output: "spec", Extends: "child"}); err == nil {
+		t.Fatalf("expected a composition cycle to be rejected")
+	}
+}
@@ -31,6 +31,8 @@ func TestTacticsDB_AddTactic_SubtasksFromData(t *testing.T) {
 					"type":       "document",
 					"output":     "o1",
 					"depends_on": []interface{}{"s0"},
+					"when":       "type:data_model",
+					"optional":   true,
 				},
 			},
 		},
@@ -56,4 +58,7 @@ func TestTacticsDB_AddTactic_SubtasksFromData(t *testing.T) {
 	if len(got.Subtasks[0].DependsOn) != 1 || got.Subtasks[0].DependsOn[0] != "s0" {
 		t.Fatalf("expected depends_on [s0], got %#v", got.Subtasks[0].DependsOn)
 	}
+	if got.Subtasks[0].When != "type:data_model" || !got.Subtasks[0].Optional {
+		t.Fatalf("expected the when condition and optional flag to round-trip, got %#v", got.Subtasks[0])
+	}
 }
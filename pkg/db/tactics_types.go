@@ -5,14 +5,54 @@ package db
 
 type Tactic struct {
 	ID          string                 `yaml:"id"`
-	Type        string                 `yaml:"type"`
-	Output      string                 `yaml:"output"`
+	Type        string                 `yaml:"type,omitempty"`
+	Output      string                 `yaml:"output,omitempty"`
 	Description string                 `yaml:"description,omitempty"`
 	Tags        []string               `yaml:"tags,omitempty"`
 	Match       []string               `yaml:"match,omitempty"`
 	Premises    []string               `yaml:"premises,omitempty"`
 	Subtasks    []TacticSubtask        `yaml:"subtasks,omitempty"`
 	Data        map[string]interface{} `yaml:"data,omitempty"`
+	// Params makes the tactic a template: `{{.name}}` placeholders in output,
+	// match/premises, subtasks and data are filled in by `apply --param name=value`.
+	Params []TacticParam `yaml:"params,omitempty"`
+	// Extends names a base tactic to inherit from; Include pulls in other
+	// tactics' subtasks. Both are flattened by ResolveTactic when stored.
+	Extends string          `yaml:"extends,omitempty"`
+	Include []TacticInclude `yaml:"include,omitempty"`
+
+	// Source is the layer of the tactics library the tactic was loaded from:
+	// TacticSourceBuiltin, TacticSourceUser, TacticSourceProject or the path of
+	// an extra tactics dir. It is not part of the file.
+	Source string `yaml:"-"`
+}
+
+// Tactic sources, from lowest to highest precedence (extra tactics dirs sit
+// between user and project). A tactic without a source belongs to the project.
+const (
+	TacticSourceBuiltin = "builtin"
+	TacticSourceUser    = "user"
+	TacticSourceProject = "project"
+)
+
+// IsProjectLocal reports whether the tactic lives in the project's tactics dir.
+func (t *Tactic) IsProjectLocal() bool {
+	return t.Source == "" || t.Source == TacticSourceProject
+}
+
+// Parameter types accepted in TacticParam.Type (empty means string).
+const (
+	ParamTypeString = "string"
+	ParamTypeInt    = "int"
+	ParamTypeBool   = "bool"
+)
+
+type TacticParam struct {
+	Name        string      `yaml:"name" json:"name"`
+	Type        string      `yaml:"type,omitempty" json:"type,omitempty"`
+	Description string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool        `yaml:"required,omitempty" json:"required,omitempty"`
 }
 
 type TacticSubtask struct {
@@ -21,4 +61,9 @@ type TacticSubtask struct {
 	Type      string                 `yaml:"type"`
 	DependsOn []string               `yaml:"depends_on,omitempty"`
 	Data      map[string]interface{} `yaml:"data,omitempty"`
+	// When is a condition on the project (a match predicate, optionally negated with `!`, or
+	// true/false once parameters are rendered); the subtask is skipped when it doesn't hold.
+	When string `yaml:"when,omitempty"`
+	// Optional subtasks are only created when asked for (apply --with).
+	Optional bool `yaml:"optional,omitempty"`
 }
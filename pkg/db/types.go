@@ -16,6 +16,19 @@ type Node struct {
 	ParentTactic *string         `json:"parent_tactic,omitempty"`
 	IntroducedAs *string         `json:"introduced_as,omitempty"`
 	Data         json.RawMessage `json:"data,omitempty"`
+	Assignee     *string         `json:"assignee,omitempty"`
+	// Priority is one of Priorities.
+	Priority *string `json:"priority,omitempty"`
+	// Estimate is story points ("3") or a duration ("4h", "2d"); see ParseEstimate.
+	Estimate *string    `json:"estimate,omitempty"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+	// TacticInstance names the parameterized tactic application that created the
+	// node, e.g. "implement_crud_endpoints[resource=users]"; TacticParams holds its arguments.
+	TacticInstance *string           `json:"tactic_instance,omitempty"`
+	TacticParams   map[string]string `json:"tactic_params,omitempty"`
+	// ProducerTactic is, for a premise placeholder, the tactic that would produce its output.
+	ProducerTactic *string `json:"producer_tactic,omitempty"`
 }
 
 type Edge struct {
@@ -30,6 +43,10 @@ type ActionLogEntry struct {
 	Details   *string   `json:"details,omitempty"`
 	NodeID    *string   `json:"node_id,omitempty"`
 	TacticID  *string   `json:"tactic_id,omitempty"`
+	// ChangeSet is the serialized undo/redo payload of the command that wrote this entry (JSON).
+	ChangeSet *string `json:"change_set,omitempty"`
+	// Undone is set while the entry's change set has been reverted by `undo`.
+	Undone bool `json:"undone,omitempty"`
 }
 
 type SessionSummary struct {
@@ -1,11 +1,27 @@
 package defaults
 
-import _ "embed"
+import (
+	_ "embed"
 
-// DefaultTacticsYAML is the built-in tactics library used by `tactician init`.
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTacticsYAML is the built-in tactics library. Every project sees it as the lowest layer of
+// its tactics (see store.TacticSources); `tactician init --seed` copies it into the project.
 //
 // The persistent format is one-file-per-tactic under `.tactician/tactics/`, but we
 // embed the library as a single YAML list so the binary is self-contained.
 //
 //go:embed default-tactics.yaml
 var DefaultTacticsYAML []byte
+
+// Tactics parses DefaultTacticsYAML.
+func Tactics() ([]*db.Tactic, error) {
+	var tactics []*db.Tactic
+	if err := yaml.Unmarshal(DefaultTacticsYAML, &tactics); err != nil {
+		return nil, errors.Wrap(err, "parse embedded default tactics")
+	}
+	return tactics, nil
+}
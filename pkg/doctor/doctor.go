@@ -0,0 +1,348 @@
+// Package doctor runs whole-state health checks over a loaded `.tactician/` directory.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is one problem reported by Check.
+type Finding struct {
+	Check    string
+	Severity Severity
+	// Subject is the tactic, node, edge or file the finding is about.
+	Subject    string
+	Message    string
+	Suggestion string
+
+	// fix repairs the finding in st; nil when there is no safe automatic repair.
+	fix func(ctx context.Context, st *store.State) error
+}
+
+// Fixable reports whether Fix can repair the finding.
+func (f *Finding) Fixable() bool {
+	return f.fix != nil
+}
+
+// Check runs every check against st.
+func Check(ctx context.Context, st *store.State) ([]*Finding, error) {
+	var findings []*Finding
+
+	findings = append(findings, integrityFindings(st.Issues)...)
+
+	tacticFiles, err := store.ListTacticFiles(st.Dir)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, duplicateTacticFindings(tacticFiles)...)
+
+	tactics, err := st.Tactics.GetAllTactics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, tacticFindings(tactics, nodes)...)
+	findings = append(findings, nodeFindings(nodes, tactics)...)
+
+	logs, err := st.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	logFindings, err := actionLogFindings(logs, nodes)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, logFindings...)
+
+	return findings, nil
+}
+
+// Fix applies the safe repairs of findings to st and marks it dirty. It returns how many were applied.
+func Fix(ctx context.Context, st *store.State, findings []*Finding) (int, error) {
+	fixed := 0
+	for _, f := range findings {
+		if f.fix == nil {
+			continue
+		}
+		if err := f.fix(ctx, st); err != nil {
+			return fixed, err
+		}
+		fixed++
+	}
+	if fixed > 0 {
+		st.Dirty = true
+	}
+	return fixed, nil
+}
+
+func integrityFindings(issues []dag.Issue) []*Finding {
+	var findings []*Finding
+	for _, issue := range issues {
+		switch issue.Kind {
+		case dag.IssueCycle:
+			findings = append(findings, &Finding{
+				Check:      "cycle",
+				Severity:   SeverityError,
+				Subject:    issue.Nodes[0],
+				Message:    issue.Message,
+				Suggestion: "remove one edge of the cycle with `edge remove`",
+			})
+		case dag.IssueDanglingEdge:
+			findings = append(findings, &Finding{
+				Check:      "dangling_edge",
+				Severity:   SeverityWarning,
+				Subject:    issue.Edge.SourceNodeID + " -> " + issue.Edge.TargetNodeID,
+				Message:    issue.Message,
+				Suggestion: "--fix saves project.yaml without the edge",
+				// The edge was never imported; saving is enough to drop it.
+				fix: func(ctx context.Context, st *store.State) error { return nil },
+			})
+		case dag.IssueDuplicateOutput:
+			findings = append(findings, &Finding{
+				Check:      "duplicate_output",
+				Severity:   SeverityWarning,
+				Subject:    strings.Join(issue.Nodes, ", "),
+				Message:    issue.Message,
+				Suggestion: "give each node a distinct output, or delete the redundant node",
+			})
+		}
+	}
+	return findings
+}
+
+func duplicateTacticFindings(files []store.TacticFile) []*Finding {
+	byID := map[string][]string{}
+	for _, f := range files {
+		byID[f.ID] = append(byID[f.ID], filepath.Base(f.Path))
+	}
+	ids := make([]string, 0, len(byID))
+	for id, names := range byID {
+		if len(names) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var findings []*Finding
+	for _, id := range ids {
+		findings = append(findings, &Finding{
+			Check:      "duplicate_tactic_id",
+			Severity:   SeverityError,
+			Subject:    id,
+			Message:    fmt.Sprintf("tactic id %q is declared by %s; only one is loaded", id, strings.Join(byID[id], ", ")),
+			Suggestion: "rename the id in one file or delete the copy",
+		})
+	}
+	return findings
+}
+
+func tacticFindings(all []*db.Tactic, nodes []*db.Node) []*Finding {
+	produced := map[string]bool{}
+	for _, t := range all {
+		produced[t.Output] = true
+		for _, st := range t.Subtasks {
+			produced[st.Output] = true
+		}
+	}
+	for _, n := range nodes {
+		produced[n.Output] = true
+	}
+
+	var findings []*Finding
+	for _, t := range all {
+		// The builtin tactics ship with tactician; `tactic lint` still reports them.
+		if t.Source == db.TacticSourceBuiltin {
+			continue
+		}
+		for _, m := range t.Match {
+			// Parameterized names are only known once the tactic is applied; predicates other
+			// than plain outputs depend on the nodes at hand.
+			if produced[m] || isTemplate(m) || !isOutputName(m) {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Check:      "unknown_dependency",
+				Severity:   SeverityWarning,
+				Subject:    t.ID,
+				Message:    fmt.Sprintf("match %q is not produced by any tactic or node, so %s can never become ready", m, t.ID),
+				Suggestion: "fix the output name, add a tactic that produces it, or add the node by hand",
+			})
+		}
+		for _, p := range t.Premises {
+			if produced[p] || isTemplate(p) || !isOutputName(p) {
+				continue
+			}
+			// apply introduces missing premises as placeholder nodes, so this doesn't block anything.
+			findings = append(findings, &Finding{
+				Check:      "unknown_dependency",
+				Severity:   SeverityInfo,
+				Subject:    t.ID,
+				Message:    fmt.Sprintf("premise %q is not produced by any tactic or node; apply will introduce it as a placeholder", p),
+				Suggestion: "add a tactic that produces it if it should be planned work",
+			})
+		}
+
+		for _, issue := range tactics.Lint(t, nil) {
+			findings = append(findings, &Finding{
+				Check:      issue.Check,
+				Severity:   Severity(issue.Severity),
+				Subject:    t.ID,
+				Message:    issue.Message,
+				Suggestion: lintSuggestions[issue.Check],
+			})
+		}
+	}
+	return findings
+}
+
+// lintSuggestions covers the checks tactics.Lint reports without the full tactic set.
+var lintSuggestions = map[string]string{
+	tactics.CheckMissingField:             "fill in the field in the tactic file",
+	tactics.CheckDuplicateSubtask:         "give each subtask of the tactic a distinct id",
+	tactics.CheckUnknownSubtaskDependency: "fix depends_on to name a subtask of the same tactic",
+	tactics.CheckSubtaskCycle:             "remove one depends_on entry of the cycle",
+	tactics.CheckInvalidParam:             "fix the params declaration (types: string, int, bool)",
+}
+
+func isTemplate(name string) bool {
+	return strings.Contains(name, "{{")
+}
+
+func isOutputName(name string) bool {
+	return db.PredicateKind(name) == db.PredicateOutput
+}
+
+func nodeFindings(nodes []*db.Node, tactics []*db.Tactic) []*Finding {
+	tacticIDs := map[string]bool{}
+	for _, t := range tactics {
+		tacticIDs[t.ID] = true
+	}
+
+	var findings []*Finding
+	for _, n := range nodes {
+		if n.Status == db.StatusPending && n.CompletedAt != nil {
+			node := n
+			findings = append(findings, &Finding{
+				Check:      "completed_at_on_pending",
+				Severity:   SeverityWarning,
+				Subject:    n.ID,
+				Message:    fmt.Sprintf("node %s is pending but has completed_at %s", n.ID, n.CompletedAt.Format("2006-01-02 15:04:05")),
+				Suggestion: "--fix clears completed_at; mark the node complete instead if it is done",
+				fix: func(ctx context.Context, st *store.State) error {
+					return st.Project.UpdateNodeStatus(ctx, node.ID, node.Status, nil)
+				},
+			})
+		}
+		if n.ParentTactic != nil && *n.ParentTactic != "" && !tacticIDs[*n.ParentTactic] {
+			findings = append(findings, &Finding{
+				Check:      "missing_parent_tactic",
+				Severity:   SeverityInfo,
+				Subject:    n.ID,
+				Message:    fmt.Sprintf("node %s was introduced by tactic %q, which no longer exists", n.ID, *n.ParentTactic),
+				Suggestion: "restore the tactic file if it was removed by accident",
+			})
+		}
+		if n.Priority != nil && !db.IsKnownPriority(*n.Priority) {
+			findings = append(findings, &Finding{
+				Check:      "invalid_attribute",
+				Severity:   SeverityWarning,
+				Subject:    n.ID,
+				Message:    fmt.Sprintf("node %s has unknown priority %q", n.ID, *n.Priority),
+				Suggestion: "set one of " + strings.Join(db.Priorities, ", ") + " with node edit --priority",
+			})
+		}
+		if n.Estimate != nil {
+			if _, _, err := db.ParseEstimate(*n.Estimate); err != nil {
+				findings = append(findings, &Finding{
+					Check:      "invalid_attribute",
+					Severity:   SeverityWarning,
+					Subject:    n.ID,
+					Message:    fmt.Sprintf("node %s: %v", n.ID, err),
+					Suggestion: "fix it with node edit --estimate",
+				})
+			}
+		} else if _, _, err := dag.NodeHours(n, 1); err != nil {
+			findings = append(findings, &Finding{
+				Check:      "invalid_attribute",
+				Severity:   SeverityWarning,
+				Subject:    n.ID,
+				Message:    fmt.Sprintf("data.estimate of %v; schedule, impact and next treat the node as unestimated", err),
+				Suggestion: "fix it with node edit --estimate or node edit --set estimate=...",
+			})
+		}
+	}
+	return findings
+}
+
+func actionLogFindings(logs []db.ActionLogEntry, nodes []*db.Node) ([]*Finding, error) {
+	existing := map[string]bool{}
+	for _, n := range nodes {
+		existing[n.ID] = true
+	}
+	deleted := map[string]bool{}
+	for _, l := range logs {
+		// An undone entry's node may be gone with it, even once a later change dropped the change set.
+		if (l.Action == "node_deleted" || l.Undone) && l.NodeID != nil {
+			deleted[*l.NodeID] = true
+		}
+		// undo and unapply remove nodes without a node_deleted entry; their change sets say which.
+		removed, err := store.RemovedNodes(l)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range removed {
+			deleted[id] = true
+		}
+	}
+
+	// Entries for nodes deleted through tactician are normal history; report nodes that disappeared
+	// otherwise (hand edits, merges), once per node.
+	missing := map[string]int{}
+	for _, l := range logs {
+		if l.NodeID == nil || existing[*l.NodeID] || deleted[*l.NodeID] {
+			continue
+		}
+		missing[*l.NodeID]++
+	}
+	ids := make([]string, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var findings []*Finding
+	for _, id := range ids {
+		nodeID := id
+		findings = append(findings, &Finding{
+			Check:      "log_references_missing_node",
+			Severity:   SeverityWarning,
+			Subject:    id,
+			Message:    fmt.Sprintf("node %s is referenced by %d action log entries but is not in project.yaml and was not removed through tactician (delete, undo, unapply)", id, missing[id]),
+			Suggestion: "--fix records the deletion in the action log",
+			fix: func(ctx context.Context, st *store.State) error {
+				details := "Deleted node: " + nodeID + " (recorded by validate --fix)"
+				return st.Project.LogAction(ctx, "node_deleted", &details, &nodeID, nil)
+			},
+		})
+	}
+	return findings, nil
+}
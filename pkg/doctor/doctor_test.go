@@ -0,0 +1,192 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/store"
+	"github.com/go-go-golems/tactician/pkg/tactics"
+)
+
+var fixture = map[string]string{
+	"config.yaml": `tactics:
+  user: false
+`,
+	"project.yaml": `version: 1
+project:
+  name: doctor
+nodes:
+  - {id: spec, type: document, output: spec, status: pending, completed_at: 2025-01-01T00:00:00Z}
+  - {id: impl, type: code, output: impl, status: pending, parent_tactic: gone, data: {estimate: lots}}
+edges:
+  - {source: spec, target: impl}
+`,
+	"action-log.yaml": `version: 1
+entries:
+  - {timestamp: 2025-01-02T00:00:00Z, action: node_created, node_id: vanished}
+  - {timestamp: 2025-01-01T00:00:00Z, action: node_deleted, node_id: removed}
+  - {timestamp: 2024-12-31T00:00:00Z, action: node_created, node_id: removed}
+`,
+	"tactics/write_spec.yaml": `version: 1
+id: write_spec
+type: document
+output: spec
+match: [requirements]
+subtasks:
+  - {id: draft, type: document, output: draft}
+  - {id: review, type: document, output: review, depends_on: [drfat]}
+`,
+	"tactics/old_write_spec.yaml": `version: 1
+id: write_spec
+type: document
+output: spec
+`,
+}
+
+func setup(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	for rel, content := range fixture {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return dir
+}
+
+func checks(t *testing.T, dir string) []string {
+	t.Helper()
+	ctx := context.Background()
+	st, err := store.Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	findings, err := Check(ctx, st)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	var ret []string
+	for _, f := range findings {
+		ret = append(ret, f.Check+":"+f.Subject)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestCheck(t *testing.T) {
+	got := checks(t, setup(t))
+	want := []string{
+		"completed_at_on_pending:spec",
+		"duplicate_tactic_id:write_spec",
+		"invalid_attribute:impl",
+		"log_references_missing_node:vanished",
+		"missing_parent_tactic:impl",
+		"unknown_dependency:write_spec",
+		"unknown_subtask_dependency:write_spec",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("findings = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	dir := setup(t)
+	// Keep only one copy of the tactic: saving would otherwise drop the duplicate file.
+	if err := os.Remove(filepath.Join(dir, "tactics", "old_write_spec.yaml")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	err := store.Update(context.Background(), dir, func(ctx context.Context, st *store.State) error {
+		findings, err := Check(ctx, st)
+		if err != nil {
+			return err
+		}
+		fixed, err := Fix(ctx, st, findings)
+		if err != nil {
+			return err
+		}
+		if fixed != 2 {
+			t.Errorf("expected 2 fixes, got %d", fixed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	for _, c := range checks(t, dir) {
+		if c == "completed_at_on_pending:spec" || c == "log_references_missing_node:vanished" {
+			t.Fatalf("expected %s to be fixed", c)
+		}
+	}
+}
+
+func TestCheck_UndoAndUnapplyAreNotMissingNodes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := store.InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	update := func(fn func(ctx context.Context, st *store.State) error) {
+		t.Helper()
+		if err := store.Update(context.Background(), dir, func(ctx context.Context, st *store.State) error {
+			st.Dirty = true
+			return fn(ctx, st)
+		}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	addNode := func(ctx context.Context, st *store.State, n *db.Node) error {
+		n.Type, n.Output, n.Status = "document", n.ID, db.StatusPending
+		if err := st.Project.AddNode(ctx, n); err != nil {
+			return err
+		}
+		return st.Project.LogAction(ctx, "node_created", nil, &n.ID, nil)
+	}
+
+	// node add foo && undo, then another change, which drops foo's change set from redo.
+	update(func(ctx context.Context, st *store.State) error { return addNode(ctx, st, &db.Node{ID: "foo"}) })
+	update(func(ctx context.Context, st *store.State) error {
+		_, err := st.Undo(ctx, false)
+		return err
+	})
+	update(func(ctx context.Context, st *store.State) error { return addNode(ctx, st, &db.Node{ID: "bar"}) })
+
+	// apply, then unapply.
+	createdBy := "tactic:gen"
+	update(func(ctx context.Context, st *store.State) error {
+		return addNode(ctx, st, &db.Node{ID: "generated", CreatedBy: &createdBy})
+	})
+	update(func(ctx context.Context, st *store.State) error {
+		nodes, err := st.Project.GetAllNodes(ctx)
+		if err != nil {
+			return err
+		}
+		edges, err := st.Project.GetEdges(ctx)
+		if err != nil {
+			return err
+		}
+		removal, err := tactics.PlanUnapply("gen", nodes, edges)
+		if err != nil {
+			return err
+		}
+		return removal.Execute(ctx, st.Project)
+	})
+
+	if got := checks(t, dir); len(got) != 0 {
+		t.Fatalf("expected no findings after undo and unapply, got %v", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -181,6 +182,21 @@ func TestCLI_InitAddApply(t *testing.T) {
 	if !bytes.Contains([]byte(m2), []byte("graph TD")) {
 		t.Fatalf("expected goals mermaid output to contain \"graph TD\"\nmermaid=\n%s", m2)
 	}
+
+	// next --json is short for --output json; recommendations are ranked and explained.
+	nextJSON := runOut("next", "--json", "--limit", "3")
+	rows3 := decodeRowsJSON(t, nextJSON)
+	if len(rows3) == 0 || len(rows3) > 3 {
+		t.Fatalf("expected 1 to 3 recommendations from next --limit 3, got %d\noutput=\n%s", len(rows3), string(nextJSON))
+	}
+	for i, r := range rows3 {
+		if rank, _ := r["rank"].(float64); int(rank) != i+1 {
+			t.Fatalf("expected row %d to have rank %d\noutput=\n%s", i, i+1, string(nextJSON))
+		}
+		if cmd, _ := r["command"].(string); !strings.HasPrefix(cmd, "tactician ") {
+			t.Fatalf("expected a command to run in next row %d\noutput=\n%s", i, string(nextJSON))
+		}
+	}
 }
 
 func TestCLI_FlagCombinations(t *testing.T) {
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Undo/redo change sets.
+//
+// On save, the State diffs the project (nodes, edges, meta) and the node IDs of earlier action log
+// entries (rewritten by a rename) against the snapshot taken at load and
+// attaches the result to the newest action log entry written by the command. The change set is stored
+// in the action_log.change_set column as JSON and persisted in action-log.yaml, so `undo` and `redo`
+// work across invocations.
+
+type changeSet struct {
+	Nodes        []nodeChange `yaml:"nodes,omitempty" json:"nodes,omitempty"`
+	EdgesAdded   []diskEdge   `yaml:"edges_added,omitempty" json:"edges_added,omitempty"`
+	EdgesRemoved []diskEdge   `yaml:"edges_removed,omitempty" json:"edges_removed,omitempty"`
+	Meta         *metaChange  `yaml:"meta,omitempty" json:"meta,omitempty"`
+	LogNodeIDs   []logChange  `yaml:"log_node_ids,omitempty" json:"log_node_ids,omitempty"`
+}
+
+// nodeChange records one node before and after a command. Before is nil for created nodes, After is
+// nil for deleted ones.
+type nodeChange struct {
+	ID     string    `yaml:"id" json:"id"`
+	Before *diskNode `yaml:"before,omitempty" json:"before,omitempty"`
+	After  *diskNode `yaml:"after,omitempty" json:"after,omitempty"`
+}
+
+// logChange records the node ID of one earlier action log entry before and after a command.
+type logChange struct {
+	Entry  int64  `yaml:"entry" json:"entry"`
+	Before string `yaml:"before" json:"before"`
+	After  string `yaml:"after" json:"after"`
+}
+
+type metaChange struct {
+	Before diskProjectMeta `yaml:"before" json:"before"`
+	After  diskProjectMeta `yaml:"after" json:"after"`
+}
+
+func (cs *changeSet) empty() bool {
+	return len(cs.Nodes) == 0 && len(cs.EdgesAdded) == 0 && len(cs.EdgesRemoved) == 0 && cs.Meta == nil && len(cs.LogNodeIDs) == 0
+}
+
+// inverse returns the change set that takes the project from After back to Before.
+func (cs *changeSet) inverse() *changeSet {
+	inv := &changeSet{
+		EdgesAdded:   cs.EdgesRemoved,
+		EdgesRemoved: cs.EdgesAdded,
+	}
+	for _, n := range cs.Nodes {
+		inv.Nodes = append(inv.Nodes, nodeChange{ID: n.ID, Before: n.After, After: n.Before})
+	}
+	if cs.Meta != nil {
+		inv.Meta = &metaChange{Before: cs.Meta.After, After: cs.Meta.Before}
+	}
+	for _, l := range cs.LogNodeIDs {
+		inv.LogNodeIDs = append(inv.LogNodeIDs, logChange{Entry: l.Entry, Before: l.After, After: l.Before})
+	}
+	return inv
+}
+
+func diffProject(before, after *diskProjectFile) *changeSet {
+	cs := &changeSet{}
+
+	beforeNodes := map[string]*diskNode{}
+	for i := range before.Nodes {
+		beforeNodes[before.Nodes[i].ID] = &before.Nodes[i]
+	}
+	afterNodes := map[string]*diskNode{}
+	for i := range after.Nodes {
+		afterNodes[after.Nodes[i].ID] = &after.Nodes[i]
+	}
+	for id, b := range beforeNodes {
+		a, ok := afterNodes[id]
+		switch {
+		case !ok:
+			cs.Nodes = append(cs.Nodes, nodeChange{ID: id, Before: b})
+		case !reflect.DeepEqual(a, b):
+			cs.Nodes = append(cs.Nodes, nodeChange{ID: id, Before: b, After: a})
+		}
+	}
+	for id, a := range afterNodes {
+		if _, ok := beforeNodes[id]; !ok {
+			cs.Nodes = append(cs.Nodes, nodeChange{ID: id, After: a})
+		}
+	}
+	sort.Slice(cs.Nodes, func(i, j int) bool { return cs.Nodes[i].ID < cs.Nodes[j].ID })
+
+	beforeEdges := map[diskEdge]bool{}
+	for _, e := range before.Edges {
+		beforeEdges[e] = true
+	}
+	afterEdges := map[diskEdge]bool{}
+	for _, e := range after.Edges {
+		afterEdges[e] = true
+		if !beforeEdges[e] {
+			cs.EdgesAdded = append(cs.EdgesAdded, e)
+		}
+	}
+	for _, e := range before.Edges {
+		if !afterEdges[e] {
+			cs.EdgesRemoved = append(cs.EdgesRemoved, e)
+		}
+	}
+	sortEdges(cs.EdgesAdded)
+	sortEdges(cs.EdgesRemoved)
+
+	if before.Project != after.Project {
+		cs.Meta = &metaChange{Before: before.Project, After: after.Project}
+	}
+	return cs
+}
+
+func sortEdges(edges []diskEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source == edges[j].Source {
+			return edges[i].Target < edges[j].Target
+		}
+		return edges[i].Source < edges[j].Source
+	})
+}
+
+// RemovedNodes returns the IDs of the nodes e's change set removed from the project: the nodes the
+// change deleted or, once it is undone, the ones it created. Commands that delete nodes without a
+// node_deleted entry (undo, unapply) are accounted for this way.
+func RemovedNodes(e db.ActionLogEntry) ([]string, error) {
+	if e.ChangeSet == nil {
+		return nil, nil
+	}
+	cs := &changeSet{}
+	if err := json.Unmarshal([]byte(*e.ChangeSet), cs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal change set")
+	}
+	if e.Undone {
+		cs = cs.inverse()
+	}
+	var ret []string
+	for _, n := range cs.Nodes {
+		if n.Before != nil && n.After == nil {
+			ret = append(ret, n.ID)
+		}
+	}
+	return ret, nil
+}
+
+// diffLogNodeIDs lists the log entries of before whose node ID changed, oldest first.
+func diffLogNodeIDs(before, after map[int64]string) []logChange {
+	var ret []logChange
+	for id, b := range before {
+		if a, ok := after[id]; ok && a != b {
+			ret = append(ret, logChange{Entry: id, Before: b, After: a})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Entry < ret[j].Entry })
+	return ret
+}
+
+// logNodeIDs maps the action log entries that refer to a node to its ID.
+func (s *State) logNodeIDs(ctx context.Context) (map[int64]string, error) {
+	entries, err := s.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[int64]string{}
+	for _, e := range entries {
+		if e.NodeID != nil {
+			ret[e.ID] = *e.NodeID
+		}
+	}
+	return ret, nil
+}
+
+// recordChangeSet diffs the project against the load-time baseline and attaches the change set to the
+// newest log entry written since. Entries undone earlier lose their change set: a new change ends redo.
+func (s *State) recordChangeSet(ctx context.Context) error {
+	if s.baseline == nil {
+		return nil
+	}
+	current, err := s.projectFile(ctx)
+	if err != nil {
+		return err
+	}
+	cs := diffProject(s.baseline, current)
+	logNodeIDs, err := s.logNodeIDs(ctx)
+	if err != nil {
+		return err
+	}
+	cs.LogNodeIDs = diffLogNodeIDs(s.baselineLogNodeIDs, logNodeIDs)
+	if cs.empty() {
+		return nil
+	}
+
+	logID, err := s.Project.MaxActionLogID(ctx)
+	if err != nil {
+		return err
+	}
+	if logID <= s.baselineLogID {
+		// The command changed the project without logging; log it so the change can be undone.
+		if err := s.Project.LogAction(ctx, "project_changed", nil, nil, nil); err != nil {
+			return err
+		}
+		logID, err = s.Project.MaxActionLogID(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(cs)
+	if err != nil {
+		return errors.Wrap(err, "marshal change set")
+	}
+	payload := string(b)
+	if err := s.Project.DropRedoableChangeSets(ctx); err != nil {
+		return err
+	}
+	return s.Project.SetActionChangeSet(ctx, logID, &payload)
+}
+
+// applyChangeSet moves the project from cs's Before state to its After state. Unless force is set, it
+// refuses when the project no longer matches Before (e.g. a node was edited after the change).
+func (s *State) applyChangeSet(ctx context.Context, cs *changeSet, force bool) error {
+	current, err := s.projectFile(ctx)
+	if err != nil {
+		return err
+	}
+	currentNodes := map[string]*diskNode{}
+	for i := range current.Nodes {
+		currentNodes[current.Nodes[i].ID] = &current.Nodes[i]
+	}
+	currentEdges := map[diskEdge]bool{}
+	for _, e := range current.Edges {
+		currentEdges[e] = true
+	}
+
+	if !force {
+		var conflicts []string
+		for _, n := range cs.Nodes {
+			cur := currentNodes[n.ID]
+			switch {
+			case n.Before == nil && cur != nil:
+				conflicts = append(conflicts, "node "+n.ID+" exists again")
+			case n.Before != nil && cur == nil:
+				conflicts = append(conflicts, "node "+n.ID+" no longer exists")
+			case n.Before != nil && !reflect.DeepEqual(n.Before, cur):
+				conflicts = append(conflicts, "node "+n.ID+" was modified since")
+			}
+		}
+		for _, e := range cs.EdgesRemoved {
+			if !currentEdges[e] {
+				conflicts = append(conflicts, "edge "+e.Source+" -> "+e.Target+" no longer exists")
+			}
+		}
+		if len(cs.LogNodeIDs) > 0 {
+			logNodeIDs, err := s.logNodeIDs(ctx)
+			if err != nil {
+				return err
+			}
+			for _, l := range cs.LogNodeIDs {
+				if logNodeIDs[l.Entry] != l.Before {
+					conflicts = append(conflicts, fmt.Sprintf("action log entry %d no longer refers to %s", l.Entry, l.Before))
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return errors.Errorf("project changed since; refusing without --force: %s", strings.Join(conflicts, ", "))
+		}
+	}
+
+	for _, e := range cs.EdgesRemoved {
+		if err := s.Project.RemoveEdge(ctx, e.Source, e.Target); err != nil {
+			return err
+		}
+	}
+	// Create and update nodes before adding edges; delete nodes last (deleting cascades their edges).
+	for _, n := range cs.Nodes {
+		if n.After == nil {
+			continue
+		}
+		node, err := nodeFromDisk(*n.After)
+		if err != nil {
+			return err
+		}
+		if _, exists := currentNodes[n.ID]; exists {
+			err = s.Project.ReplaceNode(ctx, node)
+		} else {
+			err = s.Project.AddNode(ctx, node)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range cs.EdgesAdded {
+		if err := s.Project.AddEdge(ctx, e.Source, e.Target); err != nil {
+			return err
+		}
+	}
+	for _, n := range cs.Nodes {
+		if n.After != nil {
+			continue
+		}
+		if _, exists := currentNodes[n.ID]; !exists {
+			continue
+		}
+		if err := s.Project.DeleteNode(ctx, n.ID); err != nil {
+			return err
+		}
+	}
+	if cs.Meta != nil {
+		if err := s.Project.SetProjectMeta(ctx, cs.Meta.After.Name, cs.Meta.After.RootGoal); err != nil {
+			return err
+		}
+	}
+	for _, l := range cs.LogNodeIDs {
+		if err := s.Project.SetActionNodeID(ctx, l.Entry, l.After); err != nil {
+			return err
+		}
+	}
+	return nil
+}
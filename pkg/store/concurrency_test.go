@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+func TestSave_RefusesToClobberConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+
+	st1, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load (1): %v", err)
+	}
+	defer func() { _ = st1.Close() }()
+	st2, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load (2): %v", err)
+	}
+	defer func() { _ = st2.Close() }()
+
+	for i, st := range []*State{st1, st2} {
+		id := fmt.Sprintf("n%d", i+1)
+		if err := st.Project.AddNode(ctx, &db.Node{ID: id, Type: "document", Output: id}); err != nil {
+			t.Fatalf("AddNode: %v", err)
+		}
+		st.Dirty = true
+	}
+
+	if err := st1.Save(ctx); err != nil {
+		t.Fatalf("Save (1): %v", err)
+	}
+	if err := st2.Save(ctx); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict from second save, got %v", err)
+	}
+
+	st3, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load (3): %v", err)
+	}
+	defer func() { _ = st3.Close() }()
+	n1, _ := st3.Project.GetNode(ctx, "n1")
+	n2, _ := st3.Project.GetNode(ctx, "n2")
+	if n1 == nil || n2 != nil {
+		t.Fatalf("expected only n1 on disk, got n1=%v n2=%v", n1 != nil, n2 != nil)
+	}
+}
+
+func TestUpdate_SerializesConcurrentMutations(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- Update(ctx, dir, func(ctx context.Context, st *State) error {
+				id := fmt.Sprintf("n%d", i)
+				if err := st.Project.AddNode(ctx, &db.Node{ID: id, Type: "document", Output: id}); err != nil {
+					return err
+				}
+				if err := st.Project.LogAction(ctx, "node_created", nil, &id, nil); err != nil {
+					return err
+				}
+				st.Dirty = true
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNodes: %v", err)
+	}
+	logs, err := st.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetActionLog: %v", err)
+	}
+	if len(nodes) != workers || len(logs) != workers {
+		t.Fatalf("expected %d nodes and log entries, got %d nodes, %d log entries", workers, len(nodes), len(logs))
+	}
+}
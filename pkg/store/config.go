@@ -0,0 +1,115 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// IntegrityMode controls what Load does when project.yaml violates DAG integrity (cycles, dangling
+// edges, duplicate outputs).
+type IntegrityMode string
+
+const (
+	// IntegrityWarn loads anyway, prints the problems to stderr and drops dangling edges.
+	IntegrityWarn IntegrityMode = "warn"
+	// IntegrityStrict refuses to load.
+	IntegrityStrict IntegrityMode = "strict"
+)
+
+// Config is `.tactician/config.yaml`. Every field is optional.
+type Config struct {
+	Integrity IntegrityMode  `yaml:"integrity,omitempty"`
+	Statuses  StatusesConfig `yaml:"statuses,omitempty"`
+	Tactics   TacticsConfig  `yaml:"tactics,omitempty"`
+}
+
+// TacticsConfig selects the layers of the tactics library (see TacticSources).
+type TacticsConfig struct {
+	// Builtin loads the tactics embedded in the binary (default true).
+	Builtin *bool `yaml:"builtin,omitempty"`
+	// User loads the user's tactics dir, e.g. ~/.config/tactician/tactics (default true).
+	User *bool `yaml:"user,omitempty"`
+	// Paths are extra tactics dirs, relative to the directory containing `.tactician/`.
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// StatusesConfig overrides parts of db.DefaultStatusPolicy.
+type StatusesConfig struct {
+	// Satisfying replaces the statuses that unblock dependents (complete is always included).
+	Satisfying []string `yaml:"satisfying,omitempty"`
+	// Transitions replaces the allowed target statuses for each listed status.
+	Transitions map[string][]string `yaml:"transitions,omitempty"`
+}
+
+// StatusPolicy builds the node status policy from the defaults and the config overrides.
+func (c *Config) StatusPolicy() *db.StatusPolicy {
+	p := db.DefaultStatusPolicy()
+	if c == nil {
+		return p
+	}
+	if c.Statuses.Satisfying != nil {
+		p.Satisfying = map[string]bool{db.StatusComplete: true}
+		for _, s := range c.Statuses.Satisfying {
+			p.Satisfying[s] = true
+		}
+	}
+	for from, to := range c.Statuses.Transitions {
+		p.Transitions[from] = to
+	}
+	return p
+}
+
+func (c *Config) validate() error {
+	switch c.Integrity {
+	case "":
+		c.Integrity = IntegrityWarn
+	case IntegrityWarn, IntegrityStrict:
+	default:
+		return errors.Errorf("config.yaml: unknown integrity mode %q (expected strict or warn)", c.Integrity)
+	}
+
+	for _, s := range c.Statuses.Satisfying {
+		if !db.IsKnownStatus(s) {
+			return errors.Errorf("config.yaml: statuses.satisfying: unknown status %q", s)
+		}
+	}
+	for from, to := range c.Statuses.Transitions {
+		if !db.IsKnownStatus(from) {
+			return errors.Errorf("config.yaml: statuses.transitions: unknown status %q", from)
+		}
+		for _, s := range to {
+			if !db.IsKnownStatus(s) {
+				return errors.Errorf("config.yaml: statuses.transitions.%s: unknown status %q", from, s)
+			}
+		}
+	}
+	return nil
+}
+
+func configFilePath(tacticianDir string) string {
+	return filepath.Join(tacticianDir, configFileName)
+}
+
+func readConfig(tacticianDir string) (*Config, error) {
+	cfg := &Config{}
+	b, err := os.ReadFile(configFilePath(tacticianDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "read config.yaml")
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(b, cfg); err != nil {
+			return nil, errors.Wrap(err, "unmarshal config.yaml")
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
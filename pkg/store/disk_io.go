@@ -1,11 +1,12 @@
 package store
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
-	"time"
 
+	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
@@ -34,27 +35,40 @@ func readProjectFile(tacticianDir string) (*diskProjectFile, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "read project.yaml")
 	}
+	f, _, _, err := decodeProjectFile(b)
+	return f, err
+}
+
+// decodeProjectFile migrates and decodes project.yaml, returning the version found on disk and the
+// migrations that were applied in memory.
+func decodeProjectFile(b []byte) (*diskProjectFile, int, []string, error) {
+	b, from, steps, err := migrateDocument(fileKindProject, b)
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "migrate project.yaml")
+	}
 
 	var f diskProjectFile
 	if err := yaml.Unmarshal(b, &f); err != nil {
-		return nil, errors.Wrap(err, "unmarshal project.yaml")
+		return nil, 0, nil, errors.Wrap(err, "unmarshal project.yaml")
 	}
 
 	// Normalize defaults.
 	for i := range f.Nodes {
 		if f.Nodes[i].Status == "" {
-			f.Nodes[i].Status = "pending"
+			f.Nodes[i].Status = db.StatusPending
 		}
 	}
 
-	return &f, nil
+	return &f, from, steps, nil
 }
 
-func writeProjectFile(tacticianDir string, f *diskProjectFile) error {
+func stageProjectFile(txn *diskTxn, f *diskProjectFile) error {
 	if f == nil {
 		return errors.New("nil project file")
 	}
 
+	f.Version = projectFileVersion
+
 	// Deterministic ordering for stable diffs.
 	sort.Slice(f.Nodes, func(i, j int) bool { return f.Nodes[i].ID < f.Nodes[j].ID })
 	sort.Slice(f.Edges, func(i, j int) bool {
@@ -68,19 +82,16 @@ func writeProjectFile(tacticianDir string, f *diskProjectFile) error {
 	if err != nil {
 		return errors.Wrap(err, "marshal project.yaml")
 	}
-	p := projectFilePath(tacticianDir)
-	if err := os.WriteFile(p, b, 0o644); err != nil {
-		return errors.Wrap(err, "write project.yaml")
-	}
+	txn.write(projectFileName, b)
 	return nil
 }
 
-func readActionLogFile(tacticianDir string) (diskActionLogFile, error) {
+func readActionLogFile(tacticianDir string) (*diskActionLogFile, error) {
 	p := actionLogFilePath(tacticianDir)
 	_, err := os.Stat(p)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return diskActionLogFile{}, nil
+			return &diskActionLogFile{}, nil
 		}
 		return nil, errors.Wrap(err, "stat action-log.yaml")
 	}
@@ -89,31 +100,43 @@ func readActionLogFile(tacticianDir string) (diskActionLogFile, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "read action-log.yaml")
 	}
+	f, _, _, err := decodeActionLogFile(b)
+	return f, err
+}
+
+func decodeActionLogFile(b []byte) (*diskActionLogFile, int, []string, error) {
 	if len(b) == 0 {
-		return diskActionLogFile{}, nil
+		return &diskActionLogFile{}, actionLogFileVersion, nil, nil
+	}
+
+	b, from, steps, err := migrateDocument(fileKindActionLog, b)
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "migrate action-log.yaml")
 	}
 
 	var f diskActionLogFile
 	if err := yaml.Unmarshal(b, &f); err != nil {
-		return nil, errors.Wrap(err, "unmarshal action-log.yaml")
+		return nil, 0, nil, errors.Wrap(err, "unmarshal action-log.yaml")
 	}
-	return f, nil
+	return &f, from, steps, nil
 }
 
-func writeActionLogFile(tacticianDir string, f diskActionLogFile) error {
+func stageActionLogFile(txn *diskTxn, f *diskActionLogFile) error {
+	f.Version = actionLogFileVersion
+	if f.Entries == nil {
+		f.Entries = []diskActionLogEntry{}
+	}
+
 	// Deterministic ordering: newest first (matches most CLI displays).
-	sort.Slice(f, func(i, j int) bool {
-		return f[i].Timestamp.After(f[j].Timestamp)
+	sort.SliceStable(f.Entries, func(i, j int) bool {
+		return f.Entries[i].Timestamp.After(f.Entries[j].Timestamp)
 	})
 
 	b, err := yaml.Marshal(f)
 	if err != nil {
 		return errors.Wrap(err, "marshal action-log.yaml")
 	}
-	p := actionLogFilePath(tacticianDir)
-	if err := os.WriteFile(p, b, 0o644); err != nil {
-		return errors.Wrap(err, "write action-log.yaml")
-	}
+	txn.write(actionLogFileName, b)
 	return nil
 }
 
@@ -126,25 +149,40 @@ func ensureTacticianDir(tacticianDir string) error {
 		return errors.Wrap(err, "mkdir tactics dir")
 	}
 
+	lock, err := acquireLock(context.Background(), tacticianDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.release() }()
+
+	if err := recoverDisk(tacticianDir); err != nil {
+		return err
+	}
+
 	// If files don't exist yet, create minimal defaults.
+	txn, err := newDiskTxn(tacticianDir)
+	if err != nil {
+		return err
+	}
 	if _, err := os.Stat(projectFilePath(tacticianDir)); os.IsNotExist(err) {
-		now := time.Now().UTC()
-		_ = now
 		f := &diskProjectFile{
 			Project: diskProjectMeta{Name: "untitled", RootGoal: ""},
 			Nodes:   []diskNode{},
 			Edges:   []diskEdge{},
 		}
-		if err := writeProjectFile(tacticianDir, f); err != nil {
+		if err := stageProjectFile(txn, f); err != nil {
 			return err
 		}
 	}
 	if _, err := os.Stat(actionLogFilePath(tacticianDir)); os.IsNotExist(err) {
-		if err := writeActionLogFile(tacticianDir, diskActionLogFile{}); err != nil {
+		if err := stageActionLogFile(txn, &diskActionLogFile{}); err != nil {
 			return err
 		}
 	}
-	return nil
+	if len(txn.writes) == 0 {
+		return nil
+	}
+	return txn.commit()
 }
 
 // InitDir creates the `.tactician/` directory structure and minimal YAML files if missing.
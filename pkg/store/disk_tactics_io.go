@@ -1,6 +1,8 @@
 package store
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,8 +13,8 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func readTacticsDir(tacticianDir string) ([]*db.Tactic, error) {
-	dir := tacticsDirPath(tacticianDir)
+// tacticFiles lists the tactic files in a tactics dir, sorted by name.
+func tacticFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, errors.Wrap(err, "read tactics dir")
@@ -29,6 +31,15 @@ func readTacticsDir(tacticianDir string) ([]*db.Tactic, error) {
 		}
 	}
 	sort.Strings(files)
+	return files, nil
+}
+
+// readTacticsDir reads every tactic file of a tactics dir (the project's or another source's).
+func readTacticsDir(dir string) ([]*db.Tactic, error) {
+	files, err := tacticFiles(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	var tactics []*db.Tactic
 	for _, p := range files {
@@ -36,20 +47,88 @@ func readTacticsDir(tacticianDir string) ([]*db.Tactic, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "read tactic file")
 		}
-		var t db.Tactic
-		if err := yaml.Unmarshal(b, &t); err != nil {
-			return nil, errors.Wrap(err, "unmarshal tactic file")
+		t, _, _, err := decodeTacticFile(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tactic file %s", p)
 		}
 		if t.ID == "" {
 			return nil, errors.Errorf("tactic file missing id: %s", p)
 		}
-		tactics = append(tactics, &t)
+		tactics = append(tactics, t)
 	}
 
 	return tactics, nil
 }
 
-func writeTacticFile(tacticianDir string, tactic *db.Tactic) error {
+// TacticFile is one file of the tactics dir and the tactic id it declares.
+type TacticFile struct {
+	Path string
+	ID   string
+}
+
+// ListTacticFiles reads the id of every tactic file. Unlike Load, it keeps files that declare the same
+// id apart (Load keeps the last one), so callers can report duplicates.
+func ListTacticFiles(tacticianDir string) ([]TacticFile, error) {
+	if _, err := os.Stat(tacticsDirPath(tacticianDir)); os.IsNotExist(err) {
+		return nil, nil
+	}
+	files, err := tacticFiles(tacticsDirPath(tacticianDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []TacticFile
+	for _, p := range files {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "read tactic file")
+		}
+		t, _, _, err := decodeTacticFile(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tactic file %s", p)
+		}
+		ret = append(ret, TacticFile{Path: p, ID: t.ID})
+	}
+	return ret, nil
+}
+
+// ReadTacticFile reads a single tactic file (in the tactics dir format, older versions migrated),
+// e.g. for `tactic add --from-file`.
+func ReadTacticFile(path string) (*db.Tactic, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read tactic file")
+	}
+	t, _, _, err := decodeTacticFile(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tactic file %s", path)
+	}
+	return t, nil
+}
+
+func decodeTacticFile(b []byte) (*db.Tactic, int, []string, error) {
+	b, from, steps, err := migrateDocument(fileKindTactic, b)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	var f diskTacticFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, 0, nil, errors.Wrap(err, "unmarshal tactic file")
+	}
+	t := f.Tactic
+	return &t, from, steps, nil
+}
+
+func marshalTacticFile(tactic *db.Tactic) ([]byte, error) {
+	b, err := yaml.Marshal(&diskTacticFile{Version: tacticFileVersion, Tactic: *tactic})
+	return b, errors.Wrap(err, "marshal tactic")
+}
+
+func tacticFileRel(id string) string {
+	return filepath.Join(tacticsDirName, id+".yaml")
+}
+
+func stageTacticFile(txn *diskTxn, tactic *db.Tactic) error {
 	if tactic == nil {
 		return errors.New("nil tactic")
 	}
@@ -57,60 +136,78 @@ func writeTacticFile(tacticianDir string, tactic *db.Tactic) error {
 		return errors.New("tactic has empty id")
 	}
 
-	b, err := yaml.Marshal(tactic)
+	b, err := marshalTacticFile(tactic)
 	if err != nil {
-		return errors.Wrap(err, "marshal tactic")
+		return err
 	}
 
-	p := filepath.Join(tacticsDirPath(tacticianDir), tactic.ID+".yaml")
-	if err := os.WriteFile(p, b, 0o644); err != nil {
-		return errors.Wrap(err, "write tactic file")
-	}
+	txn.write(tacticFileRel(tactic.ID), b)
 	return nil
 }
 
-func writeTacticsDir(tacticianDir string, tactics []*db.Tactic) error {
-	dir := tacticsDirPath(tacticianDir)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return errors.Wrap(err, "mkdir tactics dir")
+// projectTactic is a project-local tactic as last loaded or saved: the file it lives in (relative
+// to the tactician dir) and its rendered form.
+type projectTactic struct {
+	rel  string
+	data []byte
+}
+
+// projectTactics renders the project-local tactics and maps them to the files they were read from.
+// Tactics without a file (added since) map to tactics/<id>.yaml.
+func projectTactics(tacticianDir string, tactics []*db.Tactic) (map[string]projectTactic, error) {
+	files, err := ListTacticFiles(tacticianDir)
+	if err != nil {
+		return nil, err
+	}
+	// Like readTacticsDir, the last file declaring an id wins.
+	rels := map[string]string{}
+	for _, f := range files {
+		rels[f.ID] = filepath.Join(tacticsDirName, filepath.Base(f.Path))
 	}
 
-	// Build desired set.
-	want := map[string]struct{}{}
+	ret := map[string]projectTactic{}
 	for _, t := range tactics {
 		if t == nil || t.ID == "" {
 			continue
 		}
-		want[t.ID] = struct{}{}
+		b, err := marshalTacticFile(t)
+		if err != nil {
+			return nil, err
+		}
+		rel, ok := rels[t.ID]
+		if !ok {
+			rel = tacticFileRel(t.ID)
+		}
+		ret[t.ID] = projectTactic{rel: rel, data: b}
 	}
+	return ret, nil
+}
 
-	// Remove stale files.
-	entries, err := os.ReadDir(dir)
+// stageTacticsDir writes the project-local tactics that changed since baseline, in place, and removes
+// the files of those that were removed. Other files, hand-edited ones included, are left untouched.
+func stageTacticsDir(txn *diskTxn, baseline map[string]projectTactic, tactics []*db.Tactic) error {
+	current, err := projectTactics(txn.dir, tactics)
 	if err != nil {
-		return errors.Wrap(err, "read tactics dir")
+		return err
 	}
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		name := e.Name()
-		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
-			continue
-		}
-		id := strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml")
-		if _, ok := want[id]; ok {
-			continue
-		}
-		if err := os.Remove(filepath.Join(dir, name)); err != nil {
-			return errors.Wrap(err, "remove stale tactic file")
+
+	for id, old := range baseline {
+		if _, ok := current[id]; !ok {
+			txn.remove(old.rel)
 		}
 	}
-
-	// Write current tactics.
-	for _, t := range tactics {
-		if err := writeTacticFile(tacticianDir, t); err != nil {
-			return err
+	for id, t := range current {
+		rel := t.rel
+		if old, ok := baseline[id]; ok {
+			if bytes.Equal(old.data, t.data) {
+				continue
+			}
+			rel = old.rel
 		}
+		if err := os.MkdirAll(tacticsDirPath(txn.dir), 0o755); err != nil {
+			return errors.Wrap(err, "mkdir tactics dir")
+		}
+		txn.write(rel, t.data)
 	}
 
 	return nil
@@ -123,6 +220,16 @@ func SeedTacticsIfMissing(tacticianDir string, tactics []*db.Tactic) error {
 		return errors.Wrap(err, "mkdir tactics dir")
 	}
 
+	lock, err := acquireLock(context.Background(), tacticianDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.release() }()
+
+	txn, err := newDiskTxn(tacticianDir)
+	if err != nil {
+		return err
+	}
 	for _, t := range tactics {
 		if t == nil || t.ID == "" {
 			continue
@@ -133,10 +240,13 @@ func SeedTacticsIfMissing(tacticianDir string, tactics []*db.Tactic) error {
 		} else if !os.IsNotExist(err) {
 			return errors.Wrap(err, "stat tactic file")
 		}
-		if err := writeTacticFile(tacticianDir, t); err != nil {
+		if err := stageTacticFile(txn, t); err != nil {
 			return err
 		}
 	}
+	if len(txn.writes) == 0 {
+		return nil
+	}
 
-	return nil
+	return txn.commit()
 }
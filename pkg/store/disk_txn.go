@@ -0,0 +1,255 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Crash-safe multi-file commits for `.tactician/`.
+//
+// A commit stages every file as `<target>.tmp-<txid>` (fsynced), then atomically writes a journal
+// listing the renames/removals to perform, applies them, and finally deletes the journal.
+//
+// Recovery (run by Load and before every commit):
+//   - journal present: roll forward (finish the renames/removals it lists), then drop the journal.
+//   - no journal: roll back (delete any leftover staged files; the targets were never touched).
+//
+// Either way, project.yaml, action-log.yaml and the tactics dir always reflect one complete save.
+
+const (
+	journalFileName = ".journal.yaml"
+	stagedMarker    = ".tmp-"
+)
+
+// commitHook is called before each commit step. Tests use it to simulate a crash at that step;
+// a non-nil error aborts the commit without any cleanup (exactly like a dying process).
+var commitHook func(step string) error
+
+type diskTxn struct {
+	dir     string
+	id      string
+	writes  []txnWrite
+	removes []string
+}
+
+type txnWrite struct {
+	rel  string
+	data []byte
+}
+
+type txnJournal struct {
+	ID      string            `yaml:"id"`
+	Writes  []txnJournalWrite `yaml:"writes"`
+	Removes []string          `yaml:"removes,omitempty"`
+}
+
+type txnJournalWrite struct {
+	Target string `yaml:"target"`
+	Staged string `yaml:"staged"`
+}
+
+func newDiskTxn(tacticianDir string) (*diskTxn, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.Wrap(err, "generate txn id")
+	}
+	return &diskTxn{dir: tacticianDir, id: hex.EncodeToString(b)}, nil
+}
+
+// write schedules a file write. rel is relative to the tactician dir.
+func (t *diskTxn) write(rel string, data []byte) {
+	t.writes = append(t.writes, txnWrite{rel: rel, data: data})
+}
+
+// remove schedules a file removal. rel is relative to the tactician dir.
+func (t *diskTxn) remove(rel string) {
+	t.removes = append(t.removes, rel)
+}
+
+func (t *diskTxn) stagedName(rel string) string {
+	return rel + stagedMarker + t.id
+}
+
+func fireCommitHook(step string) error {
+	if commitHook == nil {
+		return nil
+	}
+	return commitHook(step)
+}
+
+func (t *diskTxn) commit() error {
+	if err := recoverDisk(t.dir); err != nil {
+		return err
+	}
+
+	journal := txnJournal{ID: t.id, Removes: t.removes}
+	dirs := map[string]struct{}{t.dir: {}}
+
+	// 1) Stage all files next to their targets.
+	for _, w := range t.writes {
+		if err := fireCommitHook("stage " + w.rel); err != nil {
+			return err
+		}
+		staged := t.stagedName(w.rel)
+		if err := writeFileSynced(filepath.Join(t.dir, staged), w.data); err != nil {
+			return errors.Wrapf(err, "stage %s", w.rel)
+		}
+		journal.Writes = append(journal.Writes, txnJournalWrite{Target: w.rel, Staged: staged})
+		dirs[filepath.Dir(filepath.Join(t.dir, w.rel))] = struct{}{}
+	}
+	for d := range dirs {
+		syncDir(d)
+	}
+
+	// 2) Make the journal durable: from here on, recovery rolls forward.
+	if err := fireCommitHook("journal"); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(&journal)
+	if err != nil {
+		return errors.Wrap(err, "marshal journal")
+	}
+	if err := writeFileAtomic(filepath.Join(t.dir, journalFileName), b); err != nil {
+		return errors.Wrap(err, "write journal")
+	}
+
+	// 3) Apply.
+	if err := applyJournal(t.dir, &journal); err != nil {
+		return err
+	}
+
+	// 4) Done.
+	if err := fireCommitHook("cleanup"); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(t.dir, journalFileName)); err != nil {
+		return errors.Wrap(err, "remove journal")
+	}
+	syncDir(t.dir)
+	return nil
+}
+
+func applyJournal(tacticianDir string, j *txnJournal) error {
+	dirs := map[string]struct{}{tacticianDir: {}}
+	for _, w := range j.Writes {
+		if err := fireCommitHook("apply " + w.Target); err != nil {
+			return err
+		}
+		staged := filepath.Join(tacticianDir, w.Staged)
+		target := filepath.Join(tacticianDir, w.Target)
+		if _, err := os.Stat(staged); err != nil {
+			if os.IsNotExist(err) {
+				// Already renamed by an earlier (interrupted) attempt.
+				continue
+			}
+			return errors.Wrapf(err, "stat staged %s", w.Target)
+		}
+		if err := os.Rename(staged, target); err != nil {
+			return errors.Wrapf(err, "rename %s", w.Target)
+		}
+		dirs[filepath.Dir(target)] = struct{}{}
+	}
+	for _, rel := range j.Removes {
+		if err := fireCommitHook("remove " + rel); err != nil {
+			return err
+		}
+		p := filepath.Join(tacticianDir, rel)
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "remove %s", rel)
+		}
+		dirs[filepath.Dir(p)] = struct{}{}
+	}
+	for d := range dirs {
+		syncDir(d)
+	}
+	return nil
+}
+
+// recoverDisk finishes or discards an interrupted commit.
+func recoverDisk(tacticianDir string) error {
+	jp := filepath.Join(tacticianDir, journalFileName)
+	b, err := os.ReadFile(jp)
+	switch {
+	case err == nil:
+		var j txnJournal
+		if err := yaml.Unmarshal(b, &j); err != nil {
+			// The journal is written atomically, so this means outside interference.
+			return errors.Wrapf(err, "unreadable commit journal %s (inspect and remove it manually)", jp)
+		}
+		if err := applyJournal(tacticianDir, &j); err != nil {
+			return errors.Wrap(err, "replay commit journal")
+		}
+		if err := os.Remove(jp); err != nil {
+			return errors.Wrap(err, "remove journal")
+		}
+		syncDir(tacticianDir)
+	case os.IsNotExist(err):
+	default:
+		return errors.Wrap(err, "read commit journal")
+	}
+
+	// Anything still staged belongs to a commit that never reached its journal.
+	for _, d := range []string{tacticianDir, tacticsDirPath(tacticianDir)} {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrap(err, "read dir for recovery")
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.Contains(e.Name(), stagedMarker) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(d, e.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "remove staged file")
+			}
+		}
+	}
+	return nil
+}
+
+func writeFileSynced(p string, data []byte) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFileAtomic replaces a single file via temp file + fsync + rename.
+func writeFileAtomic(p string, data []byte) error {
+	tmp := p + stagedMarker + "single"
+	if err := writeFileSynced(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(p))
+	return nil
+}
+
+// syncDir makes renames in a directory durable. Best-effort: not every platform supports it.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+var errSimulatedCrash = errors.New("simulated crash")
+
+// setupCommitFixture creates a tactician dir with one node, one log entry and one tactic ("state A").
+func setupCommitFixture(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	if err := SeedTacticsIfMissing(dir, []*db.Tactic{
+		{ID: "t_old", Type: "document", Output: "old_doc"},
+	}); err != nil {
+		t.Fatalf("SeedTacticsIfMissing: %v", err)
+	}
+
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	if err := st.Project.AddNode(ctx, &db.Node{ID: "a", Type: "document", Output: "a", Status: "pending"}); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	nodeID := "a"
+	if err := st.Project.LogAction(ctx, "node_created", nil, &nodeID, nil); err != nil {
+		t.Fatalf("LogAction: %v", err)
+	}
+	st.Dirty = true
+	if err := st.Save(ctx); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return dir
+}
+
+// saveStateB mutates every file of the fixture: a new node + log entry (project.yaml, action-log.yaml),
+// a new tactic file and the removal of the old one (tactics dir).
+func saveStateB(t *testing.T, dir string) error {
+	t.Helper()
+	ctx := context.Background()
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	if err := st.Project.AddNode(ctx, &db.Node{ID: "b", Type: "document", Output: "b", Status: "pending"}); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	nodeID := "b"
+	if err := st.Project.LogAction(ctx, "node_created", nil, &nodeID, nil); err != nil {
+		t.Fatalf("LogAction: %v", err)
+	}
+	if err := st.Tactics.AddTactic(ctx, &db.Tactic{ID: "t_new", Type: "document", Output: "new_doc"}); err != nil {
+		t.Fatalf("AddTactic: %v", err)
+	}
+	if _, err := st.SQL.ExecContext(ctx, "DELETE FROM tactics WHERE id = ?", "t_old"); err != nil {
+		t.Fatalf("delete tactic: %v", err)
+	}
+	st.Dirty = true
+	return st.Save(ctx)
+}
+
+// assertConsistent loads the dir and checks that it reflects exactly state A or exactly state B.
+func assertConsistent(t *testing.T, dir string, wantB bool) {
+	t.Helper()
+	ctx := context.Background()
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load after crash: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	nodeB, err := st.Project.GetNode(ctx, "b")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	logs, err := st.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetActionLog: %v", err)
+	}
+	tNew, err := st.Tactics.GetTactic(ctx, "t_new")
+	if err != nil {
+		t.Fatalf("GetTactic: %v", err)
+	}
+	tOld, err := st.Tactics.GetTactic(ctx, "t_old")
+	if err != nil {
+		t.Fatalf("GetTactic: %v", err)
+	}
+
+	gotB := nodeB != nil && len(logs) == 2 && tNew != nil && tOld == nil
+	gotA := nodeB == nil && len(logs) == 1 && tNew == nil && tOld != nil
+	switch {
+	case wantB && !gotB:
+		t.Fatalf("expected state B: node b=%v logs=%d t_new=%v t_old=%v", nodeB != nil, len(logs), tNew != nil, tOld != nil)
+	case !wantB && !gotA:
+		t.Fatalf("expected state A: node b=%v logs=%d t_new=%v t_old=%v", nodeB != nil, len(logs), tNew != nil, tOld != nil)
+	}
+
+	for _, d := range []string{dir, tacticsDirPath(dir)} {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			t.Fatalf("ReadDir: %v", err)
+		}
+		for _, e := range entries {
+			if strings.Contains(e.Name(), stagedMarker) || e.Name() == journalFileName {
+				t.Fatalf("leftover commit file after recovery: %s", filepath.Join(d, e.Name()))
+			}
+		}
+	}
+}
+
+func TestCommit_CrashAtEveryStep(t *testing.T) {
+	// Record the steps of a normal commit.
+	var steps []string
+	commitHook = func(step string) error {
+		steps = append(steps, step)
+		return nil
+	}
+	dir := setupCommitFixture(t)
+	steps = nil
+	if err := saveStateB(t, dir); err != nil {
+		commitHook = nil
+		t.Fatalf("save without crash: %v", err)
+	}
+	commitHook = nil
+	assertConsistent(t, dir, true)
+
+	if len(steps) < 5 {
+		t.Fatalf("expected stage/journal/apply/remove/cleanup steps, got %v", steps)
+	}
+
+	for i, crashAt := range steps {
+		t.Run(crashAt, func(t *testing.T) {
+			dir := setupCommitFixture(t)
+
+			journalWritten := false
+			for _, s := range steps[:i] {
+				if s == "journal" {
+					journalWritten = true
+				}
+			}
+
+			commitHook = func(step string) error {
+				if step == crashAt {
+					return errSimulatedCrash
+				}
+				return nil
+			}
+			err := saveStateB(t, dir)
+			commitHook = nil
+			if !errors.Is(err, errSimulatedCrash) {
+				t.Fatalf("expected simulated crash at %q, got %v", crashAt, err)
+			}
+
+			// Crashes before the journal is durable roll back; afterwards they roll forward.
+			assertConsistent(t, dir, journalWritten)
+		})
+	}
+}
+
+func TestCommit_CorruptProjectIsNeverVisible(t *testing.T) {
+	dir := setupCommitFixture(t)
+
+	// A half-written staged file must not be picked up by Load.
+	staged := filepath.Join(dir, projectFileName+stagedMarker+"deadbeef")
+	if err := os.WriteFile(staged, []byte("nodes: [ {id: "), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	assertConsistent(t, dir, false)
+}
@@ -2,47 +2,79 @@ package store
 
 import (
 	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
 )
 
 // Disk layout (YAML source-of-truth).
 //
 // NOTE: This is intentionally separate from the sqlite schema; it is the persisted representation.
+//
+// Every file carries a `version:` key. Bump the matching constant and register a migration
+// (see migrations.go) whenever the layout changes.
+
+const (
+	projectFileVersion   = 1
+	actionLogFileVersion = 1
+	tacticFileVersion    = 1
+)
 
 type diskProjectFile struct {
+	Version int             `yaml:"version"`
 	Project diskProjectMeta `yaml:"project"`
 	Nodes   []diskNode      `yaml:"nodes"`
 	Edges   []diskEdge      `yaml:"edges"`
 }
 
 type diskProjectMeta struct {
-	Name     string `yaml:"name"`
-	RootGoal string `yaml:"root_goal"`
+	Name     string `yaml:"name" json:"name"`
+	RootGoal string `yaml:"root_goal" json:"root_goal"`
 }
 
 type diskNode struct {
-	ID           string                 `yaml:"id"`
-	Type         string                 `yaml:"type"`
-	Output       string                 `yaml:"output"`
-	Status       string                 `yaml:"status"`
-	CreatedBy    *string                `yaml:"created_by,omitempty"`
-	CreatedAt    *time.Time             `yaml:"created_at,omitempty"`
-	CompletedAt  *time.Time             `yaml:"completed_at,omitempty"`
-	ParentTactic *string                `yaml:"parent_tactic,omitempty"`
-	IntroducedAs *string                `yaml:"introduced_as,omitempty"`
-	Data         map[string]interface{} `yaml:"data,omitempty"`
+	ID           string                 `yaml:"id" json:"id"`
+	Type         string                 `yaml:"type" json:"type"`
+	Output       string                 `yaml:"output" json:"output"`
+	Status       string                 `yaml:"status" json:"status"`
+	CreatedBy    *string                `yaml:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt    *time.Time             `yaml:"created_at,omitempty" json:"created_at,omitempty"`
+	CompletedAt  *time.Time             `yaml:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ParentTactic *string                `yaml:"parent_tactic,omitempty" json:"parent_tactic,omitempty"`
+	IntroducedAs *string                `yaml:"introduced_as,omitempty" json:"introduced_as,omitempty"`
+	Data         map[string]interface{} `yaml:"data,omitempty" json:"data,omitempty"`
+	Assignee     *string                `yaml:"assignee,omitempty" json:"assignee,omitempty"`
+	Priority     *string                `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Estimate     *string                `yaml:"estimate,omitempty" json:"estimate,omitempty"`
+	// DueDate is YYYY-MM-DD (db.DueDateLayout).
+	DueDate *string  `yaml:"due_date,omitempty" json:"due_date,omitempty"`
+	Labels  []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	TacticInstance *string           `yaml:"tactic_instance,omitempty" json:"tactic_instance,omitempty"`
+	TacticParams   map[string]string `yaml:"tactic_params,omitempty" json:"tactic_params,omitempty"`
+	ProducerTactic *string           `yaml:"producer_tactic,omitempty" json:"producer_tactic,omitempty"`
 }
 
 type diskEdge struct {
-	Source string `yaml:"source"`
-	Target string `yaml:"target"`
+	Source string `yaml:"source" json:"source"`
+	Target string `yaml:"target" json:"target"`
 }
 
-type diskActionLogFile []diskActionLogEntry
+type diskActionLogFile struct {
+	Version int                  `yaml:"version"`
+	Entries []diskActionLogEntry `yaml:"entries"`
+}
 
 type diskActionLogEntry struct {
-	Timestamp time.Time `yaml:"timestamp"`
-	Action    string    `yaml:"action"`
-	Details   *string   `yaml:"details,omitempty"`
-	NodeID    *string   `yaml:"node_id,omitempty"`
-	TacticID  *string   `yaml:"tactic_id,omitempty"`
+	Timestamp time.Time  `yaml:"timestamp"`
+	Action    string     `yaml:"action"`
+	Details   *string    `yaml:"details,omitempty"`
+	NodeID    *string    `yaml:"node_id,omitempty"`
+	TacticID  *string    `yaml:"tactic_id,omitempty"`
+	ChangeSet *changeSet `yaml:"change_set,omitempty"`
+	Undone    bool       `yaml:"undone,omitempty"`
+}
+
+type diskTacticFile struct {
+	Version   int `yaml:"version"`
+	db.Tactic `yaml:",inline"`
 }
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// diskFingerprint hashes every file a save would write, so State.Save can tell whether someone
+// else changed `.tactician/` after this process loaded it.
+func diskFingerprint(tacticianDir string) (string, error) {
+	files := []string{projectFileName, actionLogFileName, seededFileName}
+
+	entries, err := os.ReadDir(tacticsDirPath(tacticianDir))
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrap(err, "read tactics dir")
+	}
+	var tacticFiles []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+		tacticFiles = append(tacticFiles, filepath.Join(tacticsDirName, name))
+	}
+	sort.Strings(tacticFiles)
+	files = append(files, tacticFiles...)
+
+	h := sha256.New()
+	for _, rel := range files {
+		b, err := os.ReadFile(filepath.Join(tacticianDir, rel))
+		if err != nil {
+			if os.IsNotExist(err) {
+				_, _ = h.Write([]byte("absent:" + rel + "\n"))
+				continue
+			}
+			return "", errors.Wrapf(err, "read %s", rel)
+		}
+		sum := sha256.Sum256(b)
+		_, _ = h.Write([]byte(rel + ":" + hex.EncodeToString(sum[:]) + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// WarningOutput receives the integrity warnings printed by Load in warn mode.
+var WarningOutput io.Writer = os.Stderr
+
+// checkIntegrity validates the graph read from project.yaml according to Config.Integrity. It returns
+// the dangling edges, which can't be imported (their endpoints don't exist) and are dropped in warn mode.
+func (s *State) checkIntegrity(nodes []*db.Node, edges []db.Edge) (map[db.Edge]bool, error) {
+	issues := dag.CheckIntegrity(nodes, edges)
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	if s.Config.Integrity == IntegrityStrict {
+		msgs := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			msgs = append(msgs, issue.Message)
+		}
+		return nil, errors.Errorf("project.yaml failed integrity checks (integrity: strict in %s):\n  %s",
+			configFileName, strings.Join(msgs, "\n  "))
+	}
+
+	s.Issues = issues
+	dangling := map[db.Edge]bool{}
+	for _, issue := range issues {
+		if issue.Kind == dag.IssueDanglingEdge {
+			dangling[*issue.Edge] = true
+			_, _ = fmt.Fprintf(WarningOutput, "warning: %s (dropped)\n", issue.Message)
+			continue
+		}
+		_, _ = fmt.Fprintf(WarningOutput, "warning: %s\n", issue.Message)
+	}
+	return dangling, nil
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cyclicProjectYAML = `version: 1
+project:
+  name: cyclic
+nodes:
+  - {id: a, type: document, output: a}
+  - {id: b, type: document, output: b}
+edges:
+  - {source: a, target: b}
+  - {source: b, target: a}
+  - {source: a, target: ghost}
+`
+
+func writeCyclicDir(t *testing.T, config string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(projectFilePath(dir), []byte(cyclicProjectYAML), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if config != "" {
+		if err := os.WriteFile(configFilePath(dir), []byte(config), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_IntegrityWarnLoadsAndDropsDanglingEdges(t *testing.T) {
+	var warnings bytes.Buffer
+	WarningOutput = &warnings
+	defer func() { WarningOutput = os.Stderr }()
+
+	ctx := context.Background()
+	st, err := Load(ctx, writeCyclicDir(t, ""))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		t.Fatalf("GetEdges: %v", err)
+	}
+	if len(edges) != 2 {
+		t.Fatalf("expected the two cyclic edges to load and the dangling one to be dropped, got %v", edges)
+	}
+	for _, want := range []string{"cycle: a -> b -> a", "edge a -> ghost references missing node(s): ghost"} {
+		if !strings.Contains(warnings.String(), want) {
+			t.Fatalf("expected warning %q, got:\n%s", want, warnings.String())
+		}
+	}
+}
+
+func TestLoad_IntegrityStrictRefuses(t *testing.T) {
+	_, err := Load(context.Background(), writeCyclicDir(t, "integrity: strict\n"))
+	if err == nil || !strings.Contains(err.Error(), "cycle: a -> b -> a") {
+		t.Fatalf("expected strict load to fail with the cycle, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const lockFileName = ".lock"
+
+var (
+	// lockTimeout bounds how long we wait for another tactician process to release the lock.
+	lockTimeout = 30 * time.Second
+	// lockPollInterval is how often we retry a contended lock.
+	lockPollInterval = 25 * time.Millisecond
+)
+
+// fileLock is an advisory, cross-process lock on the tactician dir.
+type fileLock struct {
+	f *os.File
+}
+
+func acquireLock(ctx context.Context, tacticianDir string) (*fileLock, error) {
+	p := filepath.Join(tacticianDir, lockFileName)
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, errors.Wrap(err, "open lock file")
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, errors.Wrap(err, "lock tactician dir")
+		}
+		if ok {
+			return &fileLock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, errors.Errorf("timed out after %s waiting for lock %s (is another tactician command running?)", lockTimeout, p)
+		}
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, errors.Wrap(ctx.Err(), "wait for tactician lock")
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (l *fileLock) release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return errors.Wrap(err, "unlock tactician dir")
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package store
+
+import "os"
+
+// No advisory locking on this platform; the fingerprint check in State.Save still refuses to
+// overwrite state that changed since it was loaded.
+
+func tryLockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(_ *os.File) error {
+	return nil
+}
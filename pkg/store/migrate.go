@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// MigrationResult describes what Migrate did (or would do) to one file.
+type MigrationResult struct {
+	// File is relative to the tactician dir.
+	File        string
+	FromVersion int
+	ToVersion   int
+	// Steps lists the migrations that were applied, oldest first. Empty when the file is current.
+	Steps []string
+	// Content is the rewritten file. Empty when the file is current.
+	Content string
+}
+
+// Migrate upgrades project.yaml, action-log.yaml and every tactic file to the current schema
+// version. With dryRun, nothing is written and the results carry the files as they would be.
+//
+// Load migrates in memory on every run, so this is only needed to rewrite the files eagerly
+// (mutating commands rewrite them on save anyway).
+func Migrate(ctx context.Context, tacticianDir string, dryRun bool) ([]MigrationResult, error) {
+	if err := checkTacticianDir(tacticianDir); err != nil {
+		return nil, err
+	}
+	lock, err := acquireLock(ctx, tacticianDir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.release() }()
+
+	if err := recoverDisk(tacticianDir); err != nil {
+		return nil, err
+	}
+
+	txn, err := newDiskTxn(tacticianDir)
+	if err != nil {
+		return nil, err
+	}
+	var results []MigrationResult
+	staged := func(rel string, from int, to int, steps []string) {
+		r := MigrationResult{File: rel, FromVersion: from, ToVersion: to, Steps: steps}
+		if len(steps) > 0 {
+			r.Content = string(txn.writes[len(txn.writes)-1].data)
+		}
+		results = append(results, r)
+	}
+
+	// project.yaml
+	b, err := os.ReadFile(projectFilePath(tacticianDir))
+	if err != nil {
+		return nil, errors.Wrap(err, "read project.yaml")
+	}
+	project, from, steps, err := decodeProjectFile(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) > 0 {
+		if err := stageProjectFile(txn, project); err != nil {
+			return nil, err
+		}
+	}
+	staged(projectFileName, from, projectFileVersion, steps)
+
+	// action-log.yaml
+	b, err = os.ReadFile(actionLogFilePath(tacticianDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "read action-log.yaml")
+	}
+	if err == nil {
+		logFile, from, steps, err := decodeActionLogFile(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(steps) > 0 {
+			if err := stageActionLogFile(txn, logFile); err != nil {
+				return nil, err
+			}
+		}
+		staged(actionLogFileName, from, actionLogFileVersion, steps)
+	}
+
+	// tactics/*.yaml
+	var files []string
+	if _, err := os.Stat(tacticsDirPath(tacticianDir)); err == nil {
+		files, err = tacticFiles(tacticsDirPath(tacticianDir))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range files {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "read tactic file")
+		}
+		t, from, steps, err := decodeTacticFile(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tactic file %s", p)
+		}
+		rel := filepath.Join(tacticsDirName, filepath.Base(p))
+		if len(steps) > 0 {
+			content, err := marshalTacticFile(t)
+			if err != nil {
+				return nil, err
+			}
+			txn.write(rel, content)
+		}
+		staged(rel, from, tacticFileVersion, steps)
+	}
+
+	if dryRun || len(txn.writes) == 0 {
+		return results, nil
+	}
+	if err := txn.commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
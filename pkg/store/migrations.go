@@ -0,0 +1,253 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema migrations for the files under `.tactician/`.
+//
+// Files are decoded generically (maps/slices), upgraded one version at a time by the registered
+// migrations, and only then decoded into the typed disk structs. Files without a `version:` key are
+// version 0.
+
+type fileKind string
+
+const (
+	fileKindProject   fileKind = "project"
+	fileKindActionLog fileKind = "action-log"
+	fileKindTactic    fileKind = "tactic"
+)
+
+var currentFileVersions = map[fileKind]int{
+	fileKindProject:   projectFileVersion,
+	fileKindActionLog: actionLogFileVersion,
+	fileKindTactic:    tacticFileVersion,
+}
+
+// migration upgrades a decoded document from version From to From+1.
+type migration struct {
+	Kind        fileKind
+	From        int
+	Description string
+	Apply       func(doc interface{}) (interface{}, error)
+}
+
+var migrations = map[fileKind]map[int]migration{}
+
+func registerMigration(m migration) {
+	if migrations[m.Kind] == nil {
+		migrations[m.Kind] = map[int]migration{}
+	}
+	if _, ok := migrations[m.Kind][m.From]; ok {
+		panic(fmt.Sprintf("duplicate %s migration from version %d", m.Kind, m.From))
+	}
+	migrations[m.Kind][m.From] = m
+}
+
+func init() {
+	registerMigration(migration{
+		Kind:        fileKindProject,
+		From:        0,
+		Description: "add version; convert JS-era nodes map (dependencies.match/blocks) to nodes+edges lists",
+		Apply:       migrateProjectV0,
+	})
+	registerMigration(migration{
+		Kind:        fileKindActionLog,
+		From:        0,
+		Description: "wrap top-level entry list into {version, entries}",
+		Apply:       migrateActionLogV0,
+	})
+	registerMigration(migration{
+		Kind:        fileKindTactic,
+		From:        0,
+		Description: "add version; hoist data.subtasks to top-level subtasks",
+		Apply:       migrateTacticV0,
+	})
+}
+
+// migrateDocument decodes a YAML file and runs all migrations needed to bring it to the current
+// version. It returns the migrated document (re-encoded as YAML), the version it started at and the
+// descriptions of the migrations that ran.
+func migrateDocument(kind fileKind, b []byte) ([]byte, int, []string, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, 0, nil, errors.Wrapf(err, "unmarshal %s file", kind)
+	}
+
+	from := documentVersion(doc)
+	current := currentFileVersions[kind]
+	if from > current {
+		return nil, from, nil, errors.Errorf("%s file has version %d, but this tactician only supports up to version %d (upgrade tactician)", kind, from, current)
+	}
+	if from == current {
+		return b, from, nil, nil
+	}
+
+	var applied []string
+	for v := from; v < current; v++ {
+		m, ok := migrations[kind][v]
+		if !ok {
+			return nil, from, nil, errors.Errorf("no %s migration registered from version %d", kind, v)
+		}
+		next, err := m.Apply(doc)
+		if err != nil {
+			return nil, from, nil, errors.Wrapf(err, "migrate %s file from version %d", kind, v)
+		}
+		doc = next
+		applied = append(applied, fmt.Sprintf("v%d→v%d: %s", v, v+1, m.Description))
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, from, nil, errors.Wrapf(err, "marshal migrated %s file", kind)
+	}
+	return out, from, applied, nil
+}
+
+func documentVersion(doc interface{}) int {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	v, ok := m["version"].(int)
+	if !ok {
+		return 0
+	}
+	return v
+}
+
+func documentMap(doc interface{}) (map[string]interface{}, error) {
+	if doc == nil {
+		return map[string]interface{}{}, nil
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected a mapping at the top level, got %T", doc)
+	}
+	return m, nil
+}
+
+func toStringList(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func migrateProjectV0(doc interface{}) (interface{}, error) {
+	m, err := documentMap(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// The JS version (and db.ProjectDB.ExportToYAML) keys nodes by id and stores edges on the nodes.
+	if byID, ok := m["nodes"].(map[string]interface{}); ok {
+		type edge struct{ source, target string }
+		seen := map[edge]bool{}
+		var edges []interface{}
+		addEdge := func(source, target string) {
+			e := edge{source, target}
+			if seen[e] {
+				return
+			}
+			seen[e] = true
+			edges = append(edges, map[string]interface{}{"source": source, "target": target})
+		}
+		for _, e := range asSlice(m["edges"]) {
+			em, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			source, _ := em["source"].(string)
+			target, _ := em["target"].(string)
+			addEdge(source, target)
+		}
+
+		ids := make([]string, 0, len(byID))
+		for id := range byID {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		nodes := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			n := map[string]interface{}{}
+			if src, ok := byID[id].(map[string]interface{}); ok {
+				for k, v := range src {
+					n[k] = v
+				}
+			}
+			if deps, ok := n["dependencies"].(map[string]interface{}); ok {
+				for _, dep := range toStringList(deps["match"]) {
+					addEdge(dep, id)
+				}
+			}
+			for _, blocked := range toStringList(n["blocks"]) {
+				addEdge(id, blocked)
+			}
+			delete(n, "dependencies")
+			delete(n, "blocks")
+			n["id"] = id
+			nodes = append(nodes, n)
+		}
+
+		m["nodes"] = nodes
+		if edges == nil {
+			edges = []interface{}{}
+		}
+		m["edges"] = edges
+	}
+
+	m["version"] = 1
+	return m, nil
+}
+
+func migrateActionLogV0(doc interface{}) (interface{}, error) {
+	if doc == nil {
+		return map[string]interface{}{"version": 1, "entries": []interface{}{}}, nil
+	}
+	entries, ok := doc.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected a list of entries, got %T", doc)
+	}
+	return map[string]interface{}{"version": 1, "entries": entries}, nil
+}
+
+func migrateTacticV0(doc interface{}) (interface{}, error) {
+	m, err := documentMap(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// JS tactics could carry their subtasks under data.subtasks.
+	if data, ok := m["data"].(map[string]interface{}); ok {
+		if subtasks, ok := data["subtasks"]; ok {
+			if _, exists := m["subtasks"]; !exists {
+				m["subtasks"] = subtasks
+			}
+			delete(data, "subtasks")
+			if len(data) == 0 {
+				delete(m, "data")
+			}
+		}
+	}
+
+	m["version"] = 1
+	return m, nil
+}
+
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
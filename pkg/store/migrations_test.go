@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const jsProjectYAML = `project:
+  name: legacy
+  root_goal: app
+nodes:
+  spec:
+    type: document
+    output: spec.md
+    status: complete
+    blocks: [app]
+  app:
+    type: project_artifact
+    output: app
+    dependencies:
+      match: [spec, design]
+  design:
+    type: document
+    output: design.md
+`
+
+const v0ActionLogYAML = `- timestamp: 2025-01-02T03:04:05Z
+  action: node_created
+  node_id: spec
+`
+
+const v0TacticYAML = `id: legacy_tactic
+type: document
+output: legacy_doc
+data:
+  subtasks:
+    - id: part
+      output: part.md
+      type: document
+`
+
+func writeLegacyDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := os.MkdirAll(tacticsDirPath(dir), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	files := map[string]string{
+		projectFileName:   jsProjectYAML,
+		actionLogFileName: v0ActionLogYAML,
+		filepath.Join(tacticsDirName, "legacy_tactic.yaml"): v0TacticYAML,
+	}
+	for rel, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, rel), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_MigratesLegacyFilesInMemory(t *testing.T) {
+	ctx := context.Background()
+	dir := writeLegacyDir(t)
+
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNodes: %v", err)
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(nodes))
+	}
+	deps, err := st.Project.GetDependencies(ctx, "app")
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("expected app to depend on spec and design, got %d deps", len(deps))
+	}
+
+	logs, err := st.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetActionLog: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log entry, got %d", len(logs))
+	}
+
+	tactic, err := st.Tactics.GetTactic(ctx, "legacy_tactic")
+	if err != nil {
+		t.Fatalf("GetTactic: %v", err)
+	}
+	if tactic == nil || len(tactic.Subtasks) != 1 {
+		t.Fatalf("expected legacy_tactic with 1 hoisted subtask, got %+v", tactic)
+	}
+
+	// Load never rewrites files.
+	b, err := os.ReadFile(projectFilePath(dir))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(b) != jsProjectYAML {
+		t.Fatalf("Load rewrote project.yaml")
+	}
+}
+
+func TestMigrate_DryRunThenWrite(t *testing.T) {
+	ctx := context.Background()
+	dir := writeLegacyDir(t)
+
+	results, err := Migrate(ctx, dir, true)
+	if err != nil {
+		t.Fatalf("Migrate dry-run: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.FromVersion != 0 || len(r.Steps) == 0 || r.Content == "" {
+			t.Fatalf("expected %s to need migration, got %+v", r.File, r)
+		}
+	}
+	b, err := os.ReadFile(projectFilePath(dir))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(b) != jsProjectYAML {
+		t.Fatalf("dry-run rewrote project.yaml")
+	}
+
+	if _, err := Migrate(ctx, dir, false); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	b, err = os.ReadFile(projectFilePath(dir))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "version: 1\n") {
+		t.Fatalf("expected migrated project.yaml to start with version, got:\n%s", b)
+	}
+
+	results, err = Migrate(ctx, dir, false)
+	if err != nil {
+		t.Fatalf("Migrate again: %v", err)
+	}
+	for _, r := range results {
+		if len(r.Steps) != 0 {
+			t.Fatalf("expected %s to be up to date, got %+v", r.File, r)
+		}
+	}
+}
+
+func TestMigrateDocument_RejectsNewerVersion(t *testing.T) {
+	_, _, _, err := migrateDocument(fileKindProject, []byte("version: 99\nnodes: []\n"))
+	if err == nil || !strings.Contains(err.Error(), "upgrade tactician") {
+		t.Fatalf("expected newer-version error, got %v", err)
+	}
+}
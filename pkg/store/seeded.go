@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	seededFileName    = "seeded-tactics.yaml"
+	seededFileVersion = 1
+)
+
+// diskSeededFile is `.tactician/seeded-tactics.yaml`: the hash of every builtin tactic as it was
+// copied into the project, so SyncTactics can tell local edits from upstream changes.
+type diskSeededFile struct {
+	Version int               `yaml:"version"`
+	Tactics map[string]string `yaml:"tactics"`
+}
+
+func seededFilePath(tacticianDir string) string {
+	return filepath.Join(tacticianDir, seededFileName)
+}
+
+// readSeededFile returns nil when the project never seeded tactics.
+func readSeededFile(tacticianDir string) (map[string]string, error) {
+	b, err := os.ReadFile(seededFilePath(tacticianDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read "+seededFileName)
+	}
+	var f diskSeededFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrap(err, "unmarshal "+seededFileName)
+	}
+	if f.Version > seededFileVersion {
+		return nil, errors.Errorf("%s has version %d, but this tactician only supports up to version %d (upgrade tactician)",
+			seededFileName, f.Version, seededFileVersion)
+	}
+	if f.Tactics == nil {
+		f.Tactics = map[string]string{}
+	}
+	return f.Tactics, nil
+}
+
+func stageSeededFile(txn *diskTxn, seeded map[string]string) error {
+	b, err := yaml.Marshal(&diskSeededFile{Version: seededFileVersion, Tactics: seeded})
+	if err != nil {
+		return errors.Wrap(err, "marshal "+seededFileName)
+	}
+	txn.write(seededFileName, b)
+	return nil
+}
+
+// Tactic sync statuses, comparing the project's copy of a builtin tactic with the version it was
+// seeded from and the current builtin version.
+const (
+	SyncUpToDate = "up_to_date"
+	// SyncUpdated: the copy was untouched and the builtin changed; it is replaced.
+	SyncUpdated = "updated"
+	// SyncModified: the copy was edited and the builtin didn't change; it is kept unless forced.
+	SyncModified = "modified"
+	// SyncConflict: both changed (or the copy differs and was never recorded as seeded); it is kept
+	// unless forced.
+	SyncConflict = "conflict"
+	// SyncAdded: a builtin tactic the project hasn't seeded yet; it is copied in.
+	SyncAdded = "added"
+	// SyncDeleted: the copy was removed from the project; it stays removed.
+	SyncDeleted = "deleted"
+	// SyncRemovedUpstream: the builtin tactic no longer exists; the copy is kept.
+	SyncRemovedUpstream = "removed_upstream"
+)
+
+// SyncOptions tunes SyncTactics.
+type SyncOptions struct {
+	// IDs restricts the sync to these tactics (all when empty).
+	IDs []string
+	// Force replaces edited and conflicting copies with the builtin version.
+	Force bool
+	// Diff fills TacticSync.Diff.
+	Diff bool
+}
+
+// TacticSync is what SyncTactics found (and did) for one tactic.
+type TacticSync struct {
+	ID     string
+	Status string
+	// Changed is set when the project's copy was written.
+	Changed bool
+	// Diff is a unified diff from the project's copy to the builtin version.
+	Diff string
+}
+
+// SeedTactics copies the given (builtin) tactics into the project's tactics, skipping ids the
+// project already has, and records what was seeded for SyncTactics. It returns the ids it copied.
+func (s *State) SeedTactics(ctx context.Context, tactics []*db.Tactic) ([]string, error) {
+	canonical, err := canonicalTactics(ctx, tactics)
+	if err != nil {
+		return nil, err
+	}
+	if s.seeded == nil {
+		s.seeded = map[string]string{}
+	}
+
+	var seeded []string
+	for _, t := range tactics {
+		existing, err := s.Tactics.GetTactic(ctx, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.IsProjectLocal() {
+			continue
+		}
+		if err := s.copyTactic(ctx, canonical[t.ID]); err != nil {
+			return nil, err
+		}
+		seeded = append(seeded, t.ID)
+	}
+	if len(seeded) > 0 {
+		s.Dirty = true
+	}
+	return seeded, nil
+}
+
+// SyncTactics brings the project's seeded copies of the builtin tactics up to date: untouched copies
+// follow the builtin version, edited ones are kept, and copies where both changed are reported as
+// conflicts. Tactics the project never seeded are left to the builtin layer. It sets Dirty when
+// something was written.
+func (s *State) SyncTactics(ctx context.Context, builtin []*db.Tactic, opts SyncOptions) ([]TacticSync, error) {
+	canonical, err := canonicalTactics(ctx, builtin)
+	if err != nil {
+		return nil, err
+	}
+	vendored := s.seeded != nil
+	if s.seeded == nil {
+		s.seeded = map[string]string{}
+	}
+	selected := map[string]bool{}
+	for _, id := range opts.IDs {
+		selected[id] = true
+	}
+
+	ids := map[string]bool{}
+	for id := range canonical {
+		ids[id] = true
+	}
+	for id := range s.seeded {
+		ids[id] = true
+	}
+	var sorted []string
+	for id := range ids {
+		if len(selected) == 0 || selected[id] {
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Strings(sorted)
+
+	var ret []TacticSync
+	for _, id := range sorted {
+		upstream := canonical[id]
+		seededHash, wasSeeded := s.seeded[id]
+
+		var local *db.Tactic
+		t, err := s.Tactics.GetTacticDefinition(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil && t.IsProjectLocal() {
+			local = t
+		}
+
+		var localFile, upstreamFile []byte
+		if local != nil {
+			if localFile, err = marshalTacticFile(local); err != nil {
+				return nil, err
+			}
+		}
+		if upstream != nil {
+			if upstreamFile, err = marshalTacticFile(upstream); err != nil {
+				return nil, err
+			}
+		}
+		localHash, upstreamHash := contentHash(localFile), contentHash(upstreamFile)
+
+		r := TacticSync{ID: id}
+		switch {
+		case upstream == nil:
+			if local == nil {
+				// Seeded, then removed both here and upstream.
+				delete(s.seeded, id)
+				s.Dirty = true
+				continue
+			}
+			r.Status = SyncRemovedUpstream
+		case local == nil && wasSeeded:
+			r.Status = SyncDeleted
+		case local == nil:
+			if !vendored {
+				// The builtin layer provides it.
+				continue
+			}
+			r.Status = SyncAdded
+		case localHash == upstreamHash:
+			r.Status = SyncUpToDate
+			if seededHash != upstreamHash {
+				s.seeded[id] = upstreamHash
+				s.Dirty = true
+			}
+		case wasSeeded && localHash == seededHash:
+			r.Status = SyncUpdated
+		case wasSeeded && upstreamHash == seededHash:
+			r.Status = SyncModified
+		default:
+			r.Status = SyncConflict
+		}
+
+		if opts.Diff && local != nil && upstream != nil && localHash != upstreamHash {
+			r.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(localFile)),
+				B:        difflib.SplitLines(string(upstreamFile)),
+				FromFile: "project/" + id + ".yaml",
+				ToFile:   "builtin/" + id + ".yaml",
+				Context:  3,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "diff tactic")
+			}
+		}
+
+		forced := opts.Force && (r.Status == SyncModified || r.Status == SyncConflict)
+		if r.Status == SyncUpdated || r.Status == SyncAdded || forced {
+			if err := s.copyTactic(ctx, upstream); err != nil {
+				return nil, err
+			}
+			r.Changed = true
+		}
+		ret = append(ret, r)
+	}
+	if !vendored && len(s.seeded) == 0 {
+		s.seeded = nil
+	}
+	return ret, nil
+}
+
+// copyTactic stores t as a project tactic and records it as seeded.
+func (s *State) copyTactic(ctx context.Context, t *db.Tactic) error {
+	b, err := marshalTacticFile(t)
+	if err != nil {
+		return err
+	}
+	t.Source = db.TacticSourceProject
+	if err := s.Tactics.AddTactic(ctx, t); err != nil {
+		return err
+	}
+	s.seeded[t.ID] = contentHash(b)
+	s.Dirty = true
+	return nil
+}
+
+// canonicalTactics runs tactics through a scratch tactics database, so that they are compared in the
+// form a save writes (e.g. data.subtasks hoisted to subtasks).
+func canonicalTactics(ctx context.Context, tactics []*db.Tactic) (map[string]*db.Tactic, error) {
+	sqlDB, err := db.OpenSQLiteMemory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = sqlDB.Close() }()
+	scratch := db.NewTacticsDBFromDB(sqlDB)
+	if err := scratch.InitSchema(ctx); err != nil {
+		return nil, err
+	}
+	if err := scratch.AddTactics(ctx, tactics); err != nil {
+		return nil, err
+	}
+
+	ret := map[string]*db.Tactic{}
+	for _, t := range tactics {
+		c, err := scratch.GetTacticDefinition(ctx, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		c.Source = ""
+		ret[t.ID] = c
+	}
+	return ret, nil
+}
+
+func contentHash(b []byte) string {
+	if b == nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestSyncTactics_ThreeWay(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	if err := os.WriteFile(configFilePath(dir), []byte("tactics:\n  builtin: false\n  user: false\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tactic := func(id, output string) *db.Tactic {
+		return &db.Tactic{ID: id, Type: "document", Output: output}
+	}
+	v1 := []*db.Tactic{tactic("same", "a"), tactic("upstream", "b"), tactic("local", "c"), tactic("both", "d"), tactic("gone", "e"), tactic("dropped", "f")}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		seeded, err := st.SeedTactics(ctx, v1)
+		if len(seeded) != len(v1) {
+			t.Fatalf("expected every tactic to be seeded, got %v", seeded)
+		}
+		return err
+	})
+
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		for _, edit := range []*db.Tactic{tactic("local", "c_edited"), tactic("both", "d_local")} {
+			edit.Source = db.TacticSourceProject
+			if err := st.Tactics.AddTactic(ctx, edit); err != nil {
+				return err
+			}
+		}
+		if err := st.Tactics.DeleteTactic(ctx, "gone"); err != nil {
+			return err
+		}
+		st.Dirty = true
+		return nil
+	})
+
+	v2 := []*db.Tactic{tactic("same", "a"), tactic("upstream", "b2"), tactic("local", "c"), tactic("both", "d2"), tactic("gone", "e2"), tactic("new", "g")}
+	sync := func(opts SyncOptions) map[string]TacticSync {
+		results := map[string]TacticSync{}
+		mutate(t, dir, func(ctx context.Context, st *State) error {
+			rs, err := st.SyncTactics(ctx, v2, opts)
+			for _, r := range rs {
+				results[r.ID] = r
+			}
+			return err
+		})
+		return results
+	}
+
+	got := sync(SyncOptions{Diff: true})
+	want := map[string]string{
+		"same":     SyncUpToDate,
+		"upstream": SyncUpdated,
+		"local":    SyncModified,
+		"both":     SyncConflict,
+		"gone":     SyncDeleted,
+		"dropped":  SyncRemovedUpstream,
+		"new":      SyncAdded,
+	}
+	for id, status := range want {
+		if got[id].Status != status {
+			t.Fatalf("%s: status %q, want %q (all: %+v)", id, got[id].Status, status, got)
+		}
+	}
+	if !strings.Contains(got["both"].Diff, "-output: d_local") || !strings.Contains(got["both"].Diff, "+output: d2") {
+		t.Fatalf("expected a diff from the local copy to the builtin one, got %q", got["both"].Diff)
+	}
+
+	// A second sync only reports what still needs attention.
+	got = sync(SyncOptions{})
+	if got["upstream"].Status != SyncUpToDate || got["new"].Status != SyncUpToDate || got["both"].Status != SyncConflict {
+		t.Fatalf("expected updated tactics to stay up to date, got %+v", got)
+	}
+
+	got = sync(SyncOptions{IDs: []string{"both"}, Force: true})
+	if len(got) != 1 || !got["both"].Changed {
+		t.Fatalf("expected --force to overwrite only the conflict, got %+v", got)
+	}
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	if both, _ := st.Tactics.GetTactic(ctx, "both"); both == nil || both.Output != "d2" {
+		t.Fatalf("expected the builtin version after --force, got %+v", both)
+	}
+	if local, _ := st.Tactics.GetTactic(ctx, "local"); local == nil || local.Output != "c_edited" {
+		t.Fatalf("expected the local edit to be kept, got %+v", local)
+	}
+}
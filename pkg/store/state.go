@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/go-go-golems/tactician/pkg/dag"
 	"github.com/go-go-golems/tactician/pkg/db"
 	"github.com/pkg/errors"
 )
@@ -21,20 +22,125 @@ type State struct {
 
 	// TODO(manuel): Add TacticsDB once implemented.
 	Dirty bool
+
+	// Config is `.tactician/config.yaml` (defaults when missing).
+	Config *Config
+	// Issues are the integrity problems found in project.yaml at load time (warn mode only; strict
+	// mode refuses to load). Dangling edges among them were not imported.
+	Issues []dag.Issue
+
+	// fingerprint is the hash of the on-disk state this State was loaded from (or last saved).
+	fingerprint string
+	// lock is set while the State is owned by Update, which holds the dir lock throughout.
+	lock *fileLock
+	// baseline is the project as loaded (or last saved); Save diffs against it to record the undo
+	// change set. baselineLogID is the newest action log id at that point.
+	baseline      *diskProjectFile
+	baselineLogID int64
+	// baselineLogNodeIDs are the node IDs of the action log entries at that point.
+	baselineLogNodeIDs map[int64]string
+	// baselineTactics are the project-local tactics as loaded (or last saved); Save only rewrites
+	// the ones that changed.
+	baselineTactics map[string]projectTactic
+	// noChangeSet disables change set recording (undo/redo must not record themselves).
+	noChangeSet bool
+	// statusPolicy caches Config.StatusPolicy().
+	statusPolicy *db.StatusPolicy
+	// seeded is seeded-tactics.yaml (nil if the project never seeded builtin tactics).
+	seeded map[string]string
 }
 
+// ErrConflict is returned by State.Save when `.tactician/` changed on disk after the state was loaded.
+var ErrConflict = errors.New("tactician state changed on disk since it was loaded")
+
+// maxConflictRetries bounds how often Update re-runs a mutation after a conflict.
+const maxConflictRetries = 3
+
+// Load reads `.tactician/` into a fresh in-memory database. It takes the dir lock only while reading,
+// so the returned State is a snapshot; State.Save refuses to write if the disk changed in between.
+// Commands that mutate state should use Update instead.
 func Load(ctx context.Context, tacticianDir string) (*State, error) {
+	if err := checkTacticianDir(tacticianDir); err != nil {
+		return nil, err
+	}
+
+	lock, err := acquireLock(ctx, tacticianDir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.release() }()
+
+	return load(ctx, tacticianDir)
+}
+
+// Update runs a load/mutate/save cycle while holding the dir lock, so concurrent tactician processes
+// can't interleave. fn mutates st and sets st.Dirty; Update saves afterwards. If the save hits a
+// conflict (someone bypassed the lock), the whole cycle is retried against the fresh disk state.
+func Update(ctx context.Context, tacticianDir string, fn func(ctx context.Context, st *State) error) error {
+	if err := checkTacticianDir(tacticianDir); err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := update(ctx, tacticianDir, fn)
+		if errors.Is(err, ErrConflict) && attempt < maxConflictRetries {
+			continue
+		}
+		return err
+	}
+}
+
+func update(ctx context.Context, tacticianDir string, fn func(ctx context.Context, st *State) error) error {
+	lock, err := acquireLock(ctx, tacticianDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.release() }()
+
+	st, err := load(ctx, tacticianDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+	st.lock = lock
+	defer func() { st.lock = nil }()
+
+	if err := fn(ctx, st); err != nil {
+		return err
+	}
+	return st.Save(ctx)
+}
+
+func checkTacticianDir(tacticianDir string) error {
 	// Treat tacticianDir as a filesystem directory path.
 	// Commands should pass settings.Dir (default: ".tactician").
 	if tacticianDir == "" {
-		return nil, errors.New("tactician dir is empty")
+		return errors.New("tactician dir is empty")
 	}
 
 	if _, err := os.Stat(tacticianDir); err != nil {
-		return nil, errors.Wrap(err, "stat tactician dir")
+		return errors.Wrap(err, "stat tactician dir")
 	}
 	if _, err := os.Stat(filepath.Join(tacticianDir, projectFileName)); err != nil {
-		return nil, errors.Wrap(err, "stat project.yaml (project not initialized?)")
+		return errors.Wrap(err, "stat project.yaml (project not initialized?)")
+	}
+	return nil
+}
+
+// load must be called with the dir lock held.
+func load(ctx context.Context, tacticianDir string) (*State, error) {
+	// Finish or discard a save that was interrupted mid-way.
+	if err := recoverDisk(tacticianDir); err != nil {
+		return nil, err
+	}
+	fingerprint, err := diskFingerprint(tacticianDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := readConfig(tacticianDir)
+	if err != nil {
+		return nil, err
 	}
 
 	sqlDB, err := db.OpenSQLiteMemory(ctx)
@@ -55,21 +161,35 @@ func Load(ctx context.Context, tacticianDir string) (*State, error) {
 	}
 
 	s := &State{
-		Dir:     tacticianDir,
-		SQL:     sqlDB,
-		Project: projectDB,
-		Tactics: tacticsDB,
-		Dirty:   false,
+		Dir:         tacticianDir,
+		SQL:         sqlDB,
+		Project:     projectDB,
+		Tactics:     tacticsDB,
+		Dirty:       false,
+		Config:      cfg,
+		fingerprint: fingerprint,
 	}
 
 	if err := s.importFromDisk(ctx); err != nil {
 		_ = s.Close()
 		return nil, err
 	}
+	if err := s.resetBaseline(ctx); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
 
 	return s, nil
 }
 
+// StatusPolicy returns the node status policy configured for this project.
+func (s *State) StatusPolicy() *db.StatusPolicy {
+	if s.statusPolicy == nil {
+		s.statusPolicy = s.Config.StatusPolicy()
+	}
+	return s.statusPolicy
+}
+
 func (s *State) Close() error {
 	if s == nil || s.SQL == nil {
 		return nil
@@ -79,6 +199,8 @@ func (s *State) Close() error {
 	return err
 }
 
+// Save writes the state back to disk if it is dirty. It fails with ErrConflict (instead of silently
+// overwriting) when `.tactician/` changed after this state was loaded.
 func (s *State) Save(ctx context.Context) error {
 	if s == nil {
 		return errors.New("nil state")
@@ -87,9 +209,68 @@ func (s *State) Save(ctx context.Context) error {
 		return nil
 	}
 
+	if s.lock == nil {
+		lock, err := acquireLock(ctx, s.Dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = lock.release() }()
+	}
+
+	current, err := diskFingerprint(s.Dir)
+	if err != nil {
+		return err
+	}
+	if current != s.fingerprint {
+		return errors.Wrap(ErrConflict, "refusing to overwrite changes made by another tactician process; re-run the command")
+	}
+
+	if !s.noChangeSet {
+		if err := s.recordChangeSet(ctx); err != nil {
+			return err
+		}
+	}
 	if err := s.exportToDisk(ctx); err != nil {
 		return err
 	}
+	if err := s.resetBaseline(ctx); err != nil {
+		return err
+	}
+
+	fingerprint, err := diskFingerprint(s.Dir)
+	if err != nil {
+		return err
+	}
+	s.fingerprint = fingerprint
+	s.Dirty = false
+	return nil
+}
+
+func (s *State) resetBaseline(ctx context.Context) error {
+	baseline, err := s.projectFile(ctx)
+	if err != nil {
+		return err
+	}
+	logID, err := s.Project.MaxActionLogID(ctx)
+	if err != nil {
+		return err
+	}
+	logNodeIDs, err := s.logNodeIDs(ctx)
+	if err != nil {
+		return err
+	}
+	tactics, err := s.projectLocalTactics(ctx)
+	if err != nil {
+		return err
+	}
+	baselineTactics, err := projectTactics(s.Dir, tactics)
+	if err != nil {
+		return err
+	}
+	s.baseline = baseline
+	s.baselineLogID = logID
+	s.baselineLogNodeIDs = logNodeIDs
+	s.baselineTactics = baselineTactics
 	return nil
 }
 
@@ -104,92 +285,165 @@ func (s *State) importFromDisk(ctx context.Context) error {
 		return err
 	}
 
+	nodes := make([]*db.Node, 0, len(project.Nodes))
 	for _, n := range project.Nodes {
-		var data json.RawMessage
-		if len(n.Data) > 0 {
-			b, err := json.Marshal(n.Data)
-			if err != nil {
-				return errors.Wrap(err, "marshal node data")
-			}
-			data = b
-		}
-
-		node := &db.Node{
-			ID:           n.ID,
-			Type:         n.Type,
-			Output:       n.Output,
-			Status:       n.Status,
-			CreatedBy:    n.CreatedBy,
-			ParentTactic: n.ParentTactic,
-			IntroducedAs: n.IntroducedAs,
-			Data:         data,
-		}
-		if n.CreatedAt != nil {
-			node.CreatedAt = n.CreatedAt.UTC()
-		}
-		if n.CompletedAt != nil {
-			t := n.CompletedAt.UTC()
-			node.CompletedAt = &t
-		}
-
-		if err := s.Project.AddNode(ctx, node); err != nil {
+		node, err := nodeFromDisk(n)
+		if err != nil {
 			return err
 		}
+		nodes = append(nodes, node)
 	}
 
+	edges := make([]db.Edge, 0, len(project.Edges))
 	for _, e := range project.Edges {
-		if err := s.Project.AddEdge(ctx, e.Source, e.Target); err != nil {
-			return err
+		edges = append(edges, db.Edge{SourceNodeID: e.Source, TargetNodeID: e.Target})
+	}
+	dangling, err := s.checkIntegrity(nodes, edges)
+	if err != nil {
+		return err
+	}
+	kept := make([]db.Edge, 0, len(edges))
+	for _, e := range edges {
+		if !dangling[e] {
+			kept = append(kept, e)
 		}
 	}
+	if err := s.Project.ImportGraph(ctx, nodes, kept); err != nil {
+		return err
+	}
 
 	// Action log
 	logEntries, err := readActionLogFile(s.Dir)
 	if err != nil {
 		return err
 	}
-	for _, e := range logEntries {
+	// Oldest first, so that ids grow with time like they do for entries logged at runtime.
+	for i := len(logEntries.Entries) - 1; i >= 0; i-- {
+		e := logEntries.Entries[i]
+		var changeSet *string
+		if e.ChangeSet != nil {
+			b, err := json.Marshal(e.ChangeSet)
+			if err != nil {
+				return errors.Wrap(err, "marshal change set")
+			}
+			str := string(b)
+			changeSet = &str
+		}
 		// Preserve timestamp from disk.
 		_, err := s.SQL.ExecContext(ctx, `
-INSERT INTO action_log (timestamp, action, details, node_id, tactic_id)
-VALUES (?, ?, ?, ?, ?)
-`, e.Timestamp.UTC().Format(time.RFC3339Nano), e.Action, e.Details, e.NodeID, e.TacticID)
+INSERT INTO action_log (timestamp, action, details, node_id, tactic_id, change_set, undone)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, e.Timestamp.UTC().Format(time.RFC3339Nano), e.Action, e.Details, e.NodeID, e.TacticID, changeSet, e.Undone)
 		if err != nil {
 			return errors.Wrap(err, "import action log")
 		}
 	}
 
-	// Tactics (one file per tactic)
-	if _, err := os.Stat(tacticsDirPath(s.Dir)); err == nil {
-		tactics, err := readTacticsDir(s.Dir)
-		if err != nil {
-			return err
-		}
-		for _, t := range tactics {
-			if err := s.Tactics.AddTactic(ctx, t); err != nil {
-				return err
-			}
-		}
-	} else if !os.IsNotExist(err) {
-		return errors.Wrap(err, "stat tactics dir")
+	s.seeded, err = readSeededFile(s.Dir)
+	if err != nil {
+		return err
+	}
+
+	// Tactics: the builtin library, user and extra dirs, then the project's own (one file per tactic)
+	tactics, err := loadTacticSources(TacticSources(s.Dir, s.Config))
+	if err != nil {
+		return err
+	}
+	if err := s.Tactics.AddTactics(ctx, tactics); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// exportToDisk writes project.yaml, action-log.yaml and the tactics dir as a single atomic commit.
 func (s *State) exportToDisk(ctx context.Context) error {
-	meta, err := s.Project.GetProjectMeta(ctx)
+	txn, err := newDiskTxn(s.Dir)
 	if err != nil {
 		return err
 	}
-	nodes, err := s.Project.GetAllNodes(ctx)
+
+	project, err := s.projectFile(ctx)
 	if err != nil {
 		return err
 	}
-	edges, err := s.Project.GetEdges(ctx)
+	if err := stageProjectFile(txn, project); err != nil {
+		return err
+	}
+
+	logs, err := s.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	outLog := &diskActionLogFile{}
+	for _, l := range logs {
+		var cs *changeSet
+		if l.ChangeSet != nil {
+			cs = &changeSet{}
+			if err := json.Unmarshal([]byte(*l.ChangeSet), cs); err != nil {
+				return errors.Wrap(err, "unmarshal change set")
+			}
+		}
+		outLog.Entries = append(outLog.Entries, diskActionLogEntry{
+			Timestamp: l.Timestamp.UTC(),
+			Action:    l.Action,
+			Details:   l.Details,
+			NodeID:    l.NodeID,
+			TacticID:  l.TacticID,
+			ChangeSet: cs,
+			Undone:    l.Undone,
+		})
+	}
+	if err := stageActionLogFile(txn, outLog); err != nil {
+		return err
+	}
+
+	tactics, err := s.projectLocalTactics(ctx)
 	if err != nil {
 		return err
 	}
+	if err := stageTacticsDir(txn, s.baselineTactics, tactics); err != nil {
+		return err
+	}
+	if s.seeded != nil {
+		if err := stageSeededFile(txn, s.seeded); err != nil {
+			return err
+		}
+	}
+
+	return txn.commit()
+}
+
+// projectLocalTactics returns the project's own tactics, composed ones as defined (with
+// extends/include): those are the ones written to the tactics dir.
+func (s *State) projectLocalTactics(ctx context.Context) ([]*db.Tactic, error) {
+	definitions, err := s.Tactics.GetAllTacticDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*db.Tactic
+	for _, t := range definitions {
+		if t.IsProjectLocal() {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+// projectFile renders the current nodes, edges and meta in their on-disk form.
+func (s *State) projectFile(ctx context.Context) (*diskProjectFile, error) {
+	meta, err := s.Project.GetProjectMeta(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.Project.GetAllNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.Project.GetEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	project := &diskProjectFile{
 		Project: diskProjectMeta{
@@ -199,71 +453,101 @@ func (s *State) exportToDisk(ctx context.Context) error {
 		Nodes: []diskNode{},
 		Edges: []diskEdge{},
 	}
-
 	for _, n := range nodes {
-		var data map[string]interface{}
-		if len(n.Data) > 0 {
-			if err := json.Unmarshal(n.Data, &data); err != nil {
-				return errors.Wrap(err, "unmarshal node data")
-			}
-		}
-
-		createdAt := n.CreatedAt
-		node := diskNode{
-			ID:           n.ID,
-			Type:         n.Type,
-			Output:       n.Output,
-			Status:       n.Status,
-			CreatedBy:    n.CreatedBy,
-			ParentTactic: n.ParentTactic,
-			IntroducedAs: n.IntroducedAs,
-			Data:         data,
-		}
-		if !createdAt.IsZero() {
-			t := createdAt.UTC()
-			node.CreatedAt = &t
-		}
-		if n.CompletedAt != nil && !n.CompletedAt.IsZero() {
-			t := n.CompletedAt.UTC()
-			node.CompletedAt = &t
+		node, err := nodeToDisk(n)
+		if err != nil {
+			return nil, err
 		}
-
 		project.Nodes = append(project.Nodes, node)
 	}
-
 	for _, e := range edges {
 		project.Edges = append(project.Edges, diskEdge{Source: e.SourceNodeID, Target: e.TargetNodeID})
 	}
+	return project, nil
+}
 
-	if err := writeProjectFile(s.Dir, project); err != nil {
-		return err
+func nodeFromDisk(n diskNode) (*db.Node, error) {
+	var data json.RawMessage
+	if len(n.Data) > 0 {
+		b, err := json.Marshal(n.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal node data")
+		}
+		data = b
 	}
 
-	logs, err := s.Project.GetActionLog(ctx, nil, nil)
-	if err != nil {
-		return err
+	node := &db.Node{
+		ID:           n.ID,
+		Type:         n.Type,
+		Output:       n.Output,
+		Status:       n.Status,
+		CreatedBy:    n.CreatedBy,
+		ParentTactic: n.ParentTactic,
+		IntroducedAs: n.IntroducedAs,
+		Data:         data,
+		Assignee:     n.Assignee,
+		Priority:     n.Priority,
+		Estimate:     n.Estimate,
+		Labels:       db.NormalizeLabels(n.Labels),
+
+		TacticInstance: n.TacticInstance,
+		TacticParams:   n.TacticParams,
+		ProducerTactic: n.ProducerTactic,
 	}
-	outLog := diskActionLogFile{}
-	for _, l := range logs {
-		outLog = append(outLog, diskActionLogEntry{
-			Timestamp: l.Timestamp.UTC(),
-			Action:    l.Action,
-			Details:   l.Details,
-			NodeID:    l.NodeID,
-			TacticID:  l.TacticID,
-		})
+	if n.DueDate != nil {
+		t, err := db.ParseDueDate(*n.DueDate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "node %s", n.ID)
+		}
+		node.DueDate = &t
 	}
-	if err := writeActionLogFile(s.Dir, outLog); err != nil {
-		return err
+	if n.CreatedAt != nil {
+		node.CreatedAt = n.CreatedAt.UTC()
 	}
-
-	tactics, err := s.Tactics.GetAllTactics(ctx)
-	if err != nil {
-		return err
+	if n.CompletedAt != nil {
+		t := n.CompletedAt.UTC()
+		node.CompletedAt = &t
 	}
-	if err := writeTacticsDir(s.Dir, tactics); err != nil {
-		return err
+	return node, nil
+}
+
+func nodeToDisk(n *db.Node) (diskNode, error) {
+	var data map[string]interface{}
+	if len(n.Data) > 0 {
+		if err := json.Unmarshal(n.Data, &data); err != nil {
+			return diskNode{}, errors.Wrap(err, "unmarshal node data")
+		}
 	}
 
-	return nil
+	node := diskNode{
+		ID:           n.ID,
+		Type:         n.Type,
+		Output:       n.Output,
+		Status:       n.Status,
+		CreatedBy:    n.CreatedBy,
+		ParentTactic: n.ParentTactic,
+		IntroducedAs: n.IntroducedAs,
+		Data:         data,
+		Assignee:     n.Assignee,
+		Priority:     n.Priority,
+		Estimate:     n.Estimate,
+		Labels:       n.Labels,
+
+		TacticInstance: n.TacticInstance,
+		TacticParams:   n.TacticParams,
+		ProducerTactic: n.ProducerTactic,
+	}
+	if n.DueDate != nil {
+		s := n.DueDate.UTC().Format(db.DueDateLayout)
+		node.DueDate = &s
+	}
+	if !n.CreatedAt.IsZero() {
+		t := n.CreatedAt.UTC()
+		node.CreatedAt = &t
+	}
+	if n.CompletedAt != nil && !n.CompletedAt.IsZero() {
+		t := n.CompletedAt.UTC()
+		node.CompletedAt = &t
+	}
+	return node, nil
 }
@@ -3,9 +3,11 @@ package store
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/db/dbtest"
 )
 
 func TestRoundTrip_ProjectActionLogAndTactics(t *testing.T) {
@@ -95,3 +97,61 @@ func TestRoundTrip_ProjectActionLogAndTactics(t *testing.T) {
 		t.Fatalf("expected action log entries after reload")
 	}
 }
+
+func TestNodeFromDisk_NormalizesLabels(t *testing.T) {
+	n, err := nodeFromDisk(diskNode{ID: "a", Type: "document", Output: "a", Status: db.StatusPending,
+		Labels: []string{"backend", " team: web, mobile ", "", "backend"}})
+	if err != nil {
+		t.Fatalf("nodeFromDisk: %v", err)
+	}
+	if got := strings.Join(n.Labels, "|"); got != "backend|team: web, mobile" {
+		t.Fatalf("expected trimmed, deduplicated labels, got %q", got)
+	}
+}
+
+// writeLargeProject writes dbtest.SyntheticGraph(n) as project.yaml and returns its dir.
+func writeLargeProject(b *testing.B, n int) string {
+	b.Helper()
+	b.Setenv("XDG_CONFIG_HOME", b.TempDir())
+	dir := filepath.Join(b.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		b.Fatalf("InitDir: %v", err)
+	}
+
+	nodes, edges := dbtest.SyntheticGraph(n)
+	project := &diskProjectFile{Project: diskProjectMeta{Name: "large"}}
+	for _, n := range nodes {
+		node, err := nodeToDisk(n)
+		if err != nil {
+			b.Fatalf("nodeToDisk: %v", err)
+		}
+		project.Nodes = append(project.Nodes, node)
+	}
+	for _, e := range edges {
+		project.Edges = append(project.Edges, diskEdge{Source: e.SourceNodeID, Target: e.TargetNodeID})
+	}
+	txn, err := newDiskTxn(dir)
+	if err != nil {
+		b.Fatalf("newDiskTxn: %v", err)
+	}
+	if err := stageProjectFile(txn, project); err != nil {
+		b.Fatalf("stageProjectFile: %v", err)
+	}
+	if err := txn.commit(); err != nil {
+		b.Fatalf("commit: %v", err)
+	}
+	return dir
+}
+
+func BenchmarkLoad10k(b *testing.B) {
+	ctx := context.Background()
+	dir := writeLargeProject(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		st, err := Load(ctx, dir)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = st.Close()
+	}
+}
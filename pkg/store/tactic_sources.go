@@ -0,0 +1,97 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/go-go-golems/tactician/pkg/defaults"
+	"github.com/pkg/errors"
+)
+
+// TacticsPathEnv lists extra tactics dirs (separated like PATH), loaded after the ones from config.yaml.
+const TacticsPathEnv = "TACTICIAN_TACTICS_PATH"
+
+// TacticSource is one layer of the tactics library.
+type TacticSource struct {
+	// Name is what Tactic.Source is set to: builtin, user, project or the dir of an extra source.
+	Name string
+	// Dir is the tactics dir (empty for the builtin tactics).
+	Dir string
+}
+
+// TacticSources lists the layers of a project's tactics library, from lowest to highest precedence:
+// the builtin tactics, the user's tactics dir, the extra dirs from config.yaml and TACTICIAN_TACTICS_PATH,
+// and the project's own tactics dir. A tactic overrides the ones with the same id in lower layers.
+func TacticSources(tacticianDir string, cfg *Config) []TacticSource {
+	var ret []TacticSource
+	if cfg.Tactics.Builtin == nil || *cfg.Tactics.Builtin {
+		ret = append(ret, TacticSource{Name: db.TacticSourceBuiltin})
+	}
+	if cfg.Tactics.User == nil || *cfg.Tactics.User {
+		if dir, err := os.UserConfigDir(); err == nil {
+			ret = append(ret, TacticSource{Name: db.TacticSourceUser, Dir: filepath.Join(dir, "tactician", "tactics")})
+		}
+	}
+
+	root := filepath.Dir(filepath.Clean(tacticianDir))
+	var paths []string
+	for _, p := range cfg.Tactics.Paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		paths = append(paths, p)
+	}
+	for _, p := range filepath.SplitList(os.Getenv(TacticsPathEnv)) {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	for _, p := range paths {
+		ret = append(ret, TacticSource{Name: filepath.Clean(p), Dir: filepath.Clean(p)})
+	}
+
+	return append(ret, TacticSource{Name: db.TacticSourceProject, Dir: tacticsDirPath(tacticianDir)})
+}
+
+// loadTacticSources reads every layer and keeps, for each id, the tactic of the highest layer.
+func loadTacticSources(sources []TacticSource) ([]*db.Tactic, error) {
+	var order []string
+	byID := map[string]*db.Tactic{}
+	for _, src := range sources {
+		tactics, err := readTacticSource(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tactics {
+			t.Source = src.Name
+			if _, ok := byID[t.ID]; !ok {
+				order = append(order, t.ID)
+			}
+			byID[t.ID] = t
+		}
+	}
+
+	ret := make([]*db.Tactic, 0, len(order))
+	for _, id := range order {
+		ret = append(ret, byID[id])
+	}
+	return ret, nil
+}
+
+func readTacticSource(src TacticSource) ([]*db.Tactic, error) {
+	if src.Dir == "" {
+		return defaults.Tactics()
+	}
+	if _, err := os.Stat(src.Dir); os.IsNotExist(err) {
+		// The user and project dirs are optional; extra dirs were asked for explicitly.
+		if src.Name == db.TacticSourceUser || src.Name == db.TacticSourceProject {
+			return nil, nil
+		}
+		return nil, errors.Errorf("tactics dir %s does not exist", src.Dir)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "stat tactics dir")
+	}
+	tactics, err := readTacticsDir(src.Dir)
+	return tactics, errors.Wrapf(err, "%s tactics", src.Name)
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func writeTactic(t *testing.T, dir, id, output string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	b := "version: 1\nid: " + id + "\ntype: document\noutput: " + output + "\n"
+	if err := os.WriteFile(filepath.Join(dir, id+".yaml"), []byte(b), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestLoad_LayersTacticSources(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	dir := filepath.Join(base, ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+
+	configHome := filepath.Join(base, "config")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(TacticsPathEnv, filepath.Join(base, "env-tactics"))
+
+	writeTactic(t, filepath.Join(configHome, "tactician", "tactics"), "gather_requirements", "user_requirements")
+	writeTactic(t, filepath.Join(configHome, "tactician", "tactics"), "user_only", "user_doc")
+	writeTactic(t, filepath.Join(base, "shared"), "user_only", "shared_doc")
+	writeTactic(t, filepath.Join(base, "env-tactics"), "env_only", "env_doc")
+	writeTactic(t, tacticsDirPath(dir), "env_only", "project_doc")
+	if err := os.WriteFile(configFilePath(dir), []byte("tactics:\n  paths: [shared]\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]string{
+		"write_technical_spec": db.TacticSourceBuiltin + " technical_specification",
+		"gather_requirements":  db.TacticSourceUser + " user_requirements",
+		"user_only":            filepath.Join(base, "shared") + " shared_doc",
+		"env_only":             db.TacticSourceProject + " project_doc",
+	}
+	for id, w := range want {
+		tactic, err := st.Tactics.GetTactic(ctx, id)
+		if err != nil || tactic == nil {
+			t.Fatalf("GetTactic %s: %v", id, err)
+		}
+		if got := tactic.Source + " " + tactic.Output; got != w {
+			t.Fatalf("tactic %s: got %q, want %q", id, got, w)
+		}
+	}
+
+	// Saving writes the project's own tactics only.
+	if err := st.Tactics.AddTactic(ctx, &db.Tactic{ID: "added", Type: "document", Output: "added_doc", Source: db.TacticSourceProject}); err != nil {
+		t.Fatalf("AddTactic: %v", err)
+	}
+	st.Dirty = true
+	if err := st.Save(ctx); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	_ = st.Close()
+
+	entries, err := os.ReadDir(tacticsDirPath(dir))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	sort.Strings(files)
+	if got := strings.Join(files, " "); got != "added.yaml env_only.yaml" {
+		t.Fatalf("expected only project tactics on disk, got %q", got)
+	}
+
+	if err := os.WriteFile(configFilePath(dir), []byte("tactics:\n  builtin: false\n  paths: [missing]\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := Load(ctx, dir); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected a missing tactics path to fail, got %v", err)
+	}
+}
+
+func TestSave_LeavesUnchangedTacticFilesAlone(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	dir := filepath.Join(base, ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(base, "config"))
+
+	// A hand-written file whose name doesn't match its id, and one that is removed below.
+	handWritten := "# Kept as written.\nversion: 1\nid: my_tactic\ntype: document   # aligned\noutput: my_doc\n"
+	handPath := filepath.Join(tacticsDirPath(dir), "my-tactic.yml")
+	if err := os.WriteFile(handPath, []byte(handWritten), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	gonePath := filepath.Join(tacticsDirPath(dir), "gone-file.yaml")
+	if err := os.WriteFile(gonePath, []byte("version: 1\nid: gone\ntype: document\noutput: gone_doc\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	err := Update(ctx, dir, func(ctx context.Context, st *State) error {
+		if err := st.Project.AddNode(ctx, &db.Node{ID: "n1", Type: "document", Output: "n1", Status: db.StatusPending}); err != nil {
+			return err
+		}
+		if err := st.Tactics.DeleteTactic(ctx, "gone"); err != nil {
+			return err
+		}
+		st.Dirty = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	b, err := os.ReadFile(handPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(b) != handWritten {
+		t.Fatalf("expected the hand-written tactic file to be left alone, got:\n%s", b)
+	}
+	if _, err := os.Stat(gonePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the removed tactic's file to be deleted, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tacticsDirPath(dir), "my_tactic.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("expected no copy of my_tactic under its id, got %v", err)
+	}
+
+	// Editing it rewrites the file it came from.
+	err = Update(ctx, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		return st.Tactics.AddTactic(ctx, &db.Tactic{ID: "my_tactic", Type: "document", Output: "my_new_doc", Source: db.TacticSourceProject})
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	tactic, err := ReadTacticFile(handPath)
+	if err != nil {
+		t.Fatalf("ReadTacticFile: %v", err)
+	}
+	if tactic.Output != "my_new_doc" {
+		t.Fatalf("expected the edit in %s, got output %q", handPath, tactic.Output)
+	}
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// UndoResult describes one change reverted by Undo or re-applied by Redo.
+type UndoResult struct {
+	// Entry is the action log entry that carries the change set.
+	Entry        db.ActionLogEntry
+	NodesChanged int
+	EdgesChanged int
+	// Skipped are the entries between Entry and the next newer change that undo can't revert
+	// (see untrackedActions), newest first.
+	Skipped []db.ActionLogEntry
+}
+
+// untrackedActions change the tactics library, which change sets don't cover: Undo passes over
+// them and reports them as skipped.
+var untrackedActions = map[string]bool{
+	"tactic_added":   true,
+	"tactic_updated": true,
+	"tactic_removed": true,
+	"tactics_synced": true,
+}
+
+// Undo reverts the most recent change that has not been undone yet and logs an `undo` entry.
+// Unless force is set, it refuses if the affected nodes/edges were changed again since. Changes to
+// the tactics library are not recorded and can't be undone; the result lists the ones passed over.
+func (s *State) Undo(ctx context.Context, force bool) (*UndoResult, error) {
+	entries, err := s.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Newest first: the first entry with a live change set is the one to undo. Untracked entries
+	// newer than it, back to the last change undone, are passed over for the first time.
+	var skipped []db.ActionLogEntry
+	for _, e := range entries {
+		if e.ChangeSet == nil {
+			if untrackedActions[e.Action] {
+				skipped = append(skipped, e)
+			}
+			continue
+		}
+		if e.Undone {
+			skipped = nil
+			continue
+		}
+		res, err := s.undoRedo(ctx, e, true, force)
+		if err != nil {
+			return nil, err
+		}
+		res.Skipped = skipped
+		return res, nil
+	}
+	return nil, ErrNothingToUndo
+}
+
+// Redo re-applies the change reverted by the most recent Undo and logs a `redo` entry.
+func (s *State) Redo(ctx context.Context, force bool) (*UndoResult, error) {
+	entries, err := s.Project.GetActionLog(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Undone entries form the tail of the change history (a new change drops them), so the oldest
+	// undone entry is the one undone last.
+	var target *db.ActionLogEntry
+	for i := range entries {
+		e := entries[i]
+		if e.ChangeSet == nil {
+			continue
+		}
+		if !e.Undone {
+			break
+		}
+		target = &entries[i]
+	}
+	if target == nil {
+		return nil, ErrNothingToRedo
+	}
+	return s.undoRedo(ctx, *target, false, force)
+}
+
+func (s *State) undoRedo(ctx context.Context, e db.ActionLogEntry, undo bool, force bool) (*UndoResult, error) {
+	cs := &changeSet{}
+	if err := json.Unmarshal([]byte(*e.ChangeSet), cs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal change set")
+	}
+
+	action := "redo"
+	apply := cs
+	if undo {
+		action = "undo"
+		apply = cs.inverse()
+	}
+	if err := s.applyChangeSet(ctx, apply, force); err != nil {
+		return nil, errors.Wrapf(err, "%s %s from %s", action, e.Action, e.Timestamp.Format("2006-01-02 15:04:05"))
+	}
+	if err := s.Project.SetActionUndone(ctx, e.ID, undo); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("%s %s from %s", action, e.Action, e.Timestamp.UTC().Format("2006-01-02T15:04:05Z"))
+	if err := s.Project.LogAction(ctx, action, &details, e.NodeID, e.TacticID); err != nil {
+		return nil, err
+	}
+
+	s.noChangeSet = true
+	s.Dirty = true
+	return &UndoResult{
+		Entry:        e,
+		NodesChanged: len(cs.Nodes),
+		EdgesChanged: len(cs.EdgesAdded) + len(cs.EdgesRemoved),
+	}, nil
+}
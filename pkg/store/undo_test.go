@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+func mutate(t *testing.T, dir string, fn func(ctx context.Context, st *State) error) {
+	t.Helper()
+	if err := Update(context.Background(), dir, fn); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+func edgeCount(t *testing.T, dir string) (int, int) {
+	t.Helper()
+	ctx := context.Background()
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+	nodes, err := st.Project.GetAllNodes(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNodes: %v", err)
+	}
+	edges, err := st.Project.GetEdges(ctx)
+	if err != nil {
+		t.Fatalf("GetEdges: %v", err)
+	}
+	return len(nodes), len(edges)
+}
+
+func TestUndoRedo_RestoresForceDeletedNodeWithEdges(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		for _, id := range []string{"a", "b", "c"} {
+			if err := st.Project.AddNode(ctx, &db.Node{ID: id, Type: "document", Output: id}); err != nil {
+				return err
+			}
+		}
+		if err := st.Project.AddEdge(ctx, "a", "b"); err != nil {
+			return err
+		}
+		if err := st.Project.AddEdge(ctx, "b", "c"); err != nil {
+			return err
+		}
+		st.Dirty = true
+		return st.Project.LogAction(ctx, "nodes_created", nil, nil, nil)
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		if err := st.Project.DeleteNode(ctx, "b"); err != nil {
+			return err
+		}
+		nodeID := "b"
+		return st.Project.LogAction(ctx, "node_deleted", nil, &nodeID, nil)
+	})
+	if n, e := edgeCount(t, dir); n != 2 || e != 0 {
+		t.Fatalf("after delete: expected 2 nodes/0 edges, got %d/%d", n, e)
+	}
+
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		r, err := st.Undo(ctx, false)
+		if err != nil {
+			return err
+		}
+		if r.Entry.Action != "node_deleted" {
+			t.Errorf("expected to undo node_deleted, got %s", r.Entry.Action)
+		}
+		return nil
+	})
+	if n, e := edgeCount(t, dir); n != 3 || e != 2 {
+		t.Fatalf("after undo: expected 3 nodes/2 edges, got %d/%d", n, e)
+	}
+
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		_, err := st.Redo(ctx, false)
+		return err
+	})
+	if n, e := edgeCount(t, dir); n != 2 || e != 0 {
+		t.Fatalf("after redo: expected 2 nodes/0 edges, got %d/%d", n, e)
+	}
+
+	// Undo twice goes back to the empty project; a new change then discards redo.
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		for i := 0; i < 2; i++ {
+			if _, err := st.Undo(ctx, false); err != nil {
+				return err
+			}
+		}
+		_, err := st.Undo(ctx, false)
+		if !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected ErrNothingToUndo, got %v", err)
+		}
+		return nil
+	})
+	if n, e := edgeCount(t, dir); n != 0 || e != 0 {
+		t.Fatalf("after undoing everything: expected empty project, got %d/%d", n, e)
+	}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		if err := st.Project.AddNode(ctx, &db.Node{ID: "z", Type: "document", Output: "z"}); err != nil {
+			return err
+		}
+		return st.Project.LogAction(ctx, "node_created", nil, nil, nil)
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		_, err := st.Redo(ctx, false)
+		if !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("expected ErrNothingToRedo after a new change, got %v", err)
+		}
+		return nil
+	})
+}
+
+func TestUndo_RefusesWhenNodeChangedSince(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		if err := st.Project.AddNode(ctx, &db.Node{ID: "a", Type: "document", Output: "a"}); err != nil {
+			return err
+		}
+		return st.Project.LogAction(ctx, "node_created", nil, nil, nil)
+	})
+
+	ctx := context.Background()
+	st, err := Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	// The node no longer looks like it did right after the recorded change.
+	if err := st.Project.UpdateNodeStatus(ctx, "a", "complete", nil); err != nil {
+		t.Fatalf("UpdateNodeStatus: %v", err)
+	}
+	if _, err := st.Undo(ctx, false); err == nil {
+		t.Fatalf("expected undo to refuse after the node changed")
+	}
+	if _, err := st.Undo(ctx, true); err != nil {
+		t.Fatalf("Undo --force: %v", err)
+	}
+	n, err := st.Project.GetNode(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetNode: %v", err)
+	}
+	if n != nil {
+		t.Fatalf("expected forced undo to remove node a")
+	}
+}
+
+func TestSave_RecordsUnloggedChanges(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		return st.Project.SetProjectMeta(ctx, "renamed", "")
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		r, err := st.Undo(ctx, false)
+		if err != nil {
+			return err
+		}
+		if r.Entry.Action != "project_changed" {
+			t.Errorf("expected project_changed entry, got %s", r.Entry.Action)
+		}
+		meta, err := st.Project.GetProjectMeta(ctx)
+		if err != nil {
+			return err
+		}
+		if meta["name"] != "untitled" {
+			t.Errorf("expected name to be restored, got %q", meta["name"])
+		}
+		return nil
+	})
+}
+
+func TestUndoRedo_RevertsRenamedLogEntries(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		if err := st.Project.AddNode(ctx, &db.Node{ID: "a", Type: "document", Output: "a"}); err != nil {
+			return err
+		}
+		id := "a"
+		return st.Project.LogAction(ctx, "node_created", nil, &id, nil)
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		st.Dirty = true
+		if err := st.Project.RenameNode(ctx, "a", "alpha"); err != nil {
+			return err
+		}
+		id := "alpha"
+		return st.Project.LogAction(ctx, "node_renamed", nil, &id, nil)
+	})
+
+	// createdBy returns the node ID of the node_created entry.
+	createdBy := func(st *State) string {
+		entries, err := st.Project.GetActionLog(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("GetActionLog: %v", err)
+		}
+		for _, e := range entries {
+			if e.Action == "node_created" && e.NodeID != nil {
+				return *e.NodeID
+			}
+		}
+		return ""
+	}
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		if got := createdBy(st); got != "alpha" {
+			t.Errorf("expected the rename to rewrite the log, got %q", got)
+		}
+		_, err := st.Undo(ctx, false)
+		return err
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		if got := createdBy(st); got != "a" {
+			t.Errorf("expected undo to point the log back at a, got %q", got)
+		}
+		_, err := st.Redo(ctx, false)
+		return err
+	})
+	mutate(t, dir, func(ctx context.Context, st *State) error {
+		if got := createdBy(st); got != "alpha" {
+			t.Errorf("expected redo to rewrite the log again, got %q", got)
+		}
+		return nil
+	})
+}
+
+func TestUndo_ReportsSkippedTacticChanges(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tactician")
+	if err := InitDir(dir); err != nil {
+		t.Fatalf("InitDir: %v", err)
+	}
+
+	addNode := func(id string) {
+		mutate(t, dir, func(ctx context.Context, st *State) error {
+			st.Dirty = true
+			if err := st.Project.AddNode(ctx, &db.Node{ID: id, Type: "document", Output: id}); err != nil {
+				return err
+			}
+			return st.Project.LogAction(ctx, "node_created", nil, &id, nil)
+		})
+	}
+	changeTactic := func(action, id string) {
+		mutate(t, dir, func(ctx context.Context, st *State) error {
+			st.Dirty = true
+			return st.Project.LogAction(ctx, action, nil, nil, &id)
+		})
+	}
+	addNode("a")
+	changeTactic("tactic_added", "t1")
+	addNode("b")
+	changeTactic("tactic_removed", "t2")
+
+	// Each undo names the tactic changes it passes over, once.
+	for _, want := range []struct{ node, skipped string }{{"b", "t2"}, {"a", "t1"}} {
+		mutate(t, dir, func(ctx context.Context, st *State) error {
+			r, err := st.Undo(ctx, false)
+			if err != nil {
+				return err
+			}
+			if r.Entry.NodeID == nil || *r.Entry.NodeID != want.node {
+				t.Errorf("expected to undo the creation of %s, got %+v", want.node, r.Entry)
+			}
+			if len(r.Skipped) != 1 || *r.Skipped[0].TacticID != want.skipped {
+				t.Errorf("expected %s to be skipped, got %+v", want.skipped, r.Skipped)
+			}
+			return nil
+		})
+	}
+}
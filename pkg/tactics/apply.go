@@ -0,0 +1,382 @@
+package tactics
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Node roles in a Plan.
+const (
+	RolePremise = "premise"
+	RoleSubtask = "subtask"
+	RoleOutput  = "output"
+)
+
+// Edge reasons in a Plan.
+const (
+	ReasonSubtask = "subtask"
+	ReasonMatch   = "match"
+	ReasonPremise = "premise"
+)
+
+type PlannedNode struct {
+	Node *db.Node
+	Role string
+}
+
+type PlannedEdge struct {
+	Source string
+	Target string
+	Reason string
+	// Existing is set when Source is a node already in the project (wired in by a match or premise).
+	Existing bool
+}
+
+// DepCheck classifies a tactic's dependencies against the project.
+type DepCheck struct {
+	// Ready is set when every match dependency is satisfied.
+	Ready        bool
+	Satisfied    []string
+	Missing      []string
+	CanIntroduce []string
+}
+
+// Plan is everything applying a tactic instance would add to the project.
+type Plan struct {
+	Instance *Instance
+	Deps     DepCheck
+	Nodes    []PlannedNode
+	Edges    []PlannedEdge
+	// Skipped lists the optional and conditional subtasks that aren't created.
+	Skipped []SkippedSubtask
+}
+
+// PlanApply computes the nodes and edges applying inst would create. It fails
+// if required dependencies are missing (unless force) or a node ID is taken.
+// Optional subtasks not listed in inst.With and subtasks whose when condition
+// doesn't hold are skipped; their dependents wait for their dependencies.
+func PlanApply(inst *Instance, allNodes []*db.Node, policy *db.StatusPolicy, force bool, now time.Time) (*Plan, error) {
+	tactic := inst.Tactic
+	deps := CheckDependencies(tactic, allNodes, policy)
+	if len(deps.Missing) > 0 && !force {
+		return nil, errors.Errorf("cannot apply tactic: missing required dependencies (%s); use --force", strings.Join(deps.Missing, ","))
+	}
+
+	subtasks, skipped, err := selectSubtasks(inst, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(tactic.Subtasks) > 0 && len(subtasks) == 0 {
+		return nil, errors.Errorf("cannot apply tactic: every subtask of %s is skipped", tactic.ID)
+	}
+
+	plan := &Plan{Instance: inst, Deps: deps, Skipped: skipped}
+	createdBy := "tactic:" + tacticID(inst)
+
+	// Introduce premise nodes if needed
+	for _, output := range deps.CanIntroduce {
+		introducedAs := RolePremise
+		plan.Nodes = append(plan.Nodes, PlannedNode{Role: RolePremise, Node: &db.Node{
+			ID:           output,
+			Type:         "document",
+			Output:       output,
+			Status:       db.StatusPending,
+			CreatedBy:    &createdBy,
+			IntroducedAs: &introducedAs,
+			CreatedAt:    now,
+		}})
+	}
+
+	// Subtask nodes or single output node
+	var created []*db.Node
+	if len(subtasks) > 0 {
+		for _, stt := range subtasks {
+			data, err := marshalData(stt.Data)
+			if err != nil {
+				return nil, errors.Wrap(err, "marshal subtask data")
+			}
+			parent := tacticID(inst)
+			n := &db.Node{
+				ID:           stt.ID,
+				Type:         stt.Type,
+				Output:       stt.Output,
+				Status:       db.StatusPending,
+				CreatedBy:    &createdBy,
+				ParentTactic: &parent,
+				Data:         data,
+				CreatedAt:    now,
+			}
+			created = append(created, n)
+			plan.Nodes = append(plan.Nodes, PlannedNode{Role: RoleSubtask, Node: n})
+		}
+	} else {
+		data, err := marshalData(tactic.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal tactic data")
+		}
+		n := &db.Node{
+			ID:        tactic.Output,
+			Type:      tactic.Type,
+			Output:    tactic.Output,
+			Status:    db.StatusPending,
+			CreatedBy: &createdBy,
+			Data:      data,
+			CreatedAt: now,
+		}
+		created = append(created, n)
+		plan.Nodes = append(plan.Nodes, PlannedNode{Role: RoleOutput, Node: n})
+	}
+
+	// Record the instantiation so parameterized nodes (premise placeholders
+	// included) can be traced back and unapplied.
+	if len(inst.Params) > 0 {
+		for _, pn := range plan.Nodes {
+			instanceID := inst.ID
+			pn.Node.TacticInstance = &instanceID
+			pn.Node.TacticParams = inst.Params
+		}
+	}
+
+	existing := map[string]bool{}
+	for _, n := range allNodes {
+		existing[n.ID] = true
+	}
+	for _, pn := range plan.Nodes {
+		if existing[pn.Node.ID] {
+			if len(inst.Params) > 0 {
+				return nil, errors.Errorf("node already exists: %s (already applied as %s?)", pn.Node.ID, inst.ID)
+			}
+			return nil, errors.Errorf("node already exists: %s", pn.Node.ID)
+		}
+	}
+
+	// Subtask dependency edges
+	for _, stt := range subtasks {
+		for _, depID := range stt.DependsOn {
+			plan.Edges = append(plan.Edges, PlannedEdge{Source: depID, Target: stt.ID, Reason: ReasonSubtask})
+		}
+	}
+
+	// Edges from match dependencies to created nodes.
+	//
+	// NOTE: "Satisfied" vs "missing" is about completion; the dependency edge should still exist
+	// when the dependency node exists but is not complete (so downstream nodes show as blocked).
+	for _, dep := range tactic.Match {
+		for _, source := range DependencyNodes(dep, allNodes, policy) {
+			plan.addFanIn(source.ID, created, ReasonMatch)
+		}
+	}
+
+	// Edges from premise dependencies to created nodes.
+	//
+	// Premises that exist (whether complete or not) should create edges, just like match.
+	// Premises that are being introduced (canIntroduce) are new nodes themselves, so skip those.
+	canIntroduceSet := make(map[string]bool)
+	for _, output := range deps.CanIntroduce {
+		canIntroduceSet[output] = true
+	}
+	for _, dep := range tactic.Premises {
+		// Skip if already handled as match dependency
+		if contains(tactic.Match, dep) {
+			continue
+		}
+		// Skip if being introduced (it's a new node itself)
+		if canIntroduceSet[dep] {
+			continue
+		}
+		// Create edges from existing premise nodes to new nodes
+		for _, source := range DependencyNodes(dep, allNodes, policy) {
+			plan.addFanIn(source.ID, created, ReasonPremise)
+		}
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) addFanIn(source string, targets []*db.Node, reason string) {
+	for _, t := range targets {
+		p.Edges = append(p.Edges, PlannedEdge{Source: source, Target: t.ID, Reason: reason, Existing: true})
+	}
+}
+
+// Execute writes the plan's nodes and edges and logs the application.
+func (p *Plan) Execute(ctx context.Context, project *db.ProjectDB) error {
+	for _, pn := range p.Nodes {
+		if err := project.AddNode(ctx, pn.Node); err != nil {
+			return err
+		}
+	}
+	for _, e := range p.Edges {
+		if err := project.AddEdge(ctx, e.Source, e.Target); err != nil {
+			return err
+		}
+	}
+
+	details := "Applied tactic: " + p.Instance.ID
+	tid := tacticID(p.Instance)
+	return project.LogAction(ctx, "tactic_applied", &details, nil, &tid)
+}
+
+// Fingerprint identifies the plan's nodes and edges, so a plan that was
+// confirmed interactively can be checked against a fresh one before saving.
+func (p *Plan) Fingerprint() string {
+	var parts []string
+	for _, pn := range p.Nodes {
+		parts = append(parts, "node:"+pn.Node.ID)
+	}
+	for _, e := range p.Edges {
+		parts = append(parts, "edge:"+e.Source+"->"+e.Target)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n")
+}
+
+// CheckDependencies sorts the tactic's match and premise predicates into satisfied,
+// missing and (for plain-output premises nobody produces yet) can-introduce.
+func CheckDependencies(tactic *db.Tactic, allNodes []*db.Node, policy *db.StatusPolicy) DepCheck {
+	return NewDepChecker(allNodes, policy).Check(tactic)
+}
+
+// DepChecker runs CheckDependencies for many tactics against the same nodes, indexing the
+// nodes' outputs once.
+type DepChecker struct {
+	nodes           []*db.Node
+	policy          *db.StatusPolicy
+	completeOutputs map[string]bool
+	existingOutputs map[string]bool
+}
+
+func NewDepChecker(allNodes []*db.Node, policy *db.StatusPolicy) *DepChecker {
+	c := &DepChecker{
+		nodes:           allNodes,
+		policy:          policy,
+		completeOutputs: make(map[string]bool, len(allNodes)),
+		existingOutputs: make(map[string]bool, len(allNodes)),
+	}
+	for _, n := range allNodes {
+		c.existingOutputs[n.Output] = true
+		if policy.Satisfies(n.Status) {
+			c.completeOutputs[n.Output] = true
+		}
+	}
+	return c
+}
+
+// Check is CheckDependencies for one tactic.
+func (c *DepChecker) Check(tactic *db.Tactic) DepCheck {
+	satisfied := func(dep string) (bool, *db.Predicate) {
+		p, err := db.ParsePredicate(dep)
+		if err != nil {
+			// Lint reports it; it can't be satisfied.
+			return false, nil
+		}
+		if p.Kind == db.PredicateOutput {
+			return c.completeOutputs[p.Value], p
+		}
+		return p.Satisfied(c.nodes, c.policy), p
+	}
+
+	satisfiedSet := map[string]bool{}
+	missingSet := map[string]bool{}
+	canIntroduceSet := map[string]bool{}
+
+	ready := true
+	for _, dep := range tactic.Match {
+		if ok, _ := satisfied(dep); ok {
+			satisfiedSet[dep] = true
+		} else {
+			missingSet[dep] = true
+			ready = false
+		}
+	}
+
+	for _, dep := range tactic.Premises {
+		if contains(tactic.Match, dep) {
+			continue
+		}
+		ok, p := satisfied(dep)
+		switch {
+		case ok:
+			satisfiedSet[dep] = true
+		case p != nil && p.Kind == db.PredicateOutput && !c.existingOutputs[p.Value]:
+			// Only a plain output name says what placeholder to introduce.
+			canIntroduceSet[dep] = true
+		default:
+			missingSet[dep] = true
+		}
+	}
+
+	return DepCheck{
+		Ready:        ready,
+		Satisfied:    keys(satisfiedSet),
+		Missing:      keys(missingSet),
+		CanIntroduce: keys(canIntroduceSet),
+	}
+}
+
+// DependencyNodes returns the existing nodes a match or premise entry wires to the nodes a
+// tactic creates: for a plain output the one FindNodeByOutput picks, otherwise every node the
+// predicate selects.
+func DependencyNodes(dep string, nodes []*db.Node, policy *db.StatusPolicy) []*db.Node {
+	p, err := db.ParsePredicate(dep)
+	if err != nil {
+		return nil
+	}
+	if p.Kind == db.PredicateOutput {
+		if n := FindNodeByOutput(nodes, p.Value, policy); n != nil {
+			return []*db.Node{n}
+		}
+		return nil
+	}
+	return p.Select(nodes)
+}
+
+// FindNodeByOutput prefers a node whose status satisfies dependents, then any node with that output.
+func FindNodeByOutput(nodes []*db.Node, output string, policy *db.StatusPolicy) *db.Node {
+	for _, n := range nodes {
+		if n.Output == output && policy.Satisfies(n.Status) {
+			return n
+		}
+	}
+	for _, n := range nodes {
+		if n.Output == output {
+			return n
+		}
+	}
+	return nil
+}
+
+func tacticID(inst *Instance) string {
+	return inst.Tactic.ID
+}
+
+func marshalData(data map[string]interface{}) (json.RawMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+	return json.Marshal(data)
+}
+
+func keys(m map[string]bool) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
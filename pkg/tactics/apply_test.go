@@ -0,0 +1,146 @@
+package tactics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestPlanApply_NodesEdgesAndPremises(t *testing.T) {
+	policy := db.DefaultStatusPolicy()
+	tactic := &db.Tactic{
+		ID:       "implement",
+		Output:   "api_code",
+		Match:    []string{"spec"},
+		Premises: []string{"style_guide", "data_model"},
+		Subtasks: []db.TacticSubtask{
+			{ID: "analysis", Type: "analysis", Output: "analysis"},
+			{ID: "api_code", Type: "code", Output: "api_code", DependsOn: []string{"analysis"}},
+		},
+	}
+	nodes := []*db.Node{
+		{ID: "spec_node", Output: "spec", Status: db.StatusComplete},
+		{ID: "model_node", Output: "data_model", Status: db.StatusPending},
+	}
+
+	plan, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID}, nodes, policy, false, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "data_model") {
+		t.Fatalf("expected the pending premise to count as missing, got %v", err)
+	}
+
+	plan, err = PlanApply(&Instance{Tactic: tactic, ID: tactic.ID}, nodes, policy, true, time.Now())
+	if err != nil {
+		t.Fatalf("PlanApply: %v", err)
+	}
+
+	var gotNodes []string
+	for _, pn := range plan.Nodes {
+		gotNodes = append(gotNodes, pn.Role+":"+pn.Node.ID)
+	}
+	if want := "premise:style_guide subtask:analysis subtask:api_code"; strings.Join(gotNodes, " ") != want {
+		t.Fatalf("expected nodes %q, got %q", want, strings.Join(gotNodes, " "))
+	}
+
+	var gotEdges []string
+	for _, e := range plan.Edges {
+		gotEdges = append(gotEdges, e.Reason+":"+e.Source+"->"+e.Target)
+	}
+	want := "subtask:analysis->api_code match:spec_node->analysis match:spec_node->api_code premise:model_node->analysis premise:model_node->api_code"
+	if strings.Join(gotEdges, " ") != want {
+		t.Fatalf("expected edges %q, got %q", want, strings.Join(gotEdges, " "))
+	}
+
+	nodes = append(nodes, &db.Node{ID: "analysis", Output: "analysis"})
+	if _, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID}, nodes, policy, true, time.Now()); err == nil || !strings.Contains(err.Error(), "node already exists: analysis") {
+		t.Fatalf("expected a collision error, got %v", err)
+	}
+}
+
+func TestPlanApply_MatchPredicates(t *testing.T) {
+	policy := db.DefaultStatusPolicy()
+	tactic := &db.Tactic{
+		ID:       "review_designs",
+		Type:     "review",
+		Output:   "design_review",
+		Match:    []string{">=2 type:design_document", "*_tests"},
+		Premises: []string{"label:backend", "style_guide"},
+	}
+	nodes := []*db.Node{
+		{ID: "db_design", Type: "design_document", Output: "db_design", Status: db.StatusComplete},
+		{ID: "api_design", Type: "design_document", Output: "api_design", Status: db.StatusPending},
+		{ID: "unit_tests", Type: "code", Output: "unit_tests", Status: db.StatusComplete},
+	}
+
+	deps := CheckDependencies(tactic, nodes, policy)
+	if deps.Ready || strings.Join(deps.Missing, ",") != ">=2 type:design_document,label:backend" {
+		t.Fatalf("expected one design document and no backend node to be missing, got %+v", deps)
+	}
+	if strings.Join(deps.CanIntroduce, ",") != "style_guide" {
+		t.Fatalf("expected only the plain-output premise to be introducible, got %+v", deps)
+	}
+
+	nodes[1].Status = db.StatusComplete
+	nodes = append(nodes, &db.Node{ID: "server", Type: "code", Output: "server", Status: db.StatusComplete, Labels: []string{"backend"}})
+	plan, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID}, nodes, policy, false, time.Now())
+	if err != nil {
+		t.Fatalf("PlanApply: %v", err)
+	}
+	if !plan.Deps.Ready {
+		t.Fatalf("expected the tactic to be ready, got %+v", plan.Deps)
+	}
+	var gotEdges []string
+	for _, e := range plan.Edges {
+		gotEdges = append(gotEdges, e.Reason+":"+e.Source+"->"+e.Target)
+	}
+	want := "match:db_design->design_review match:api_design->design_review match:unit_tests->design_review premise:server->design_review"
+	if strings.Join(gotEdges, " ") != want {
+		t.Fatalf("expected edges %q, got %q", want, strings.Join(gotEdges, " "))
+	}
+}
+
+func TestPlanApply_OptionalAndConditionalSubtasks(t *testing.T) {
+	policy := db.DefaultStatusPolicy()
+	tactic := &db.Tactic{
+		ID:     "build_service",
+		Output: "service",
+		Subtasks: []db.TacticSubtask{
+			{ID: "design", Type: "document", Output: "design"},
+			{ID: "write_migration", Type: "code", Output: "migration", DependsOn: []string{"design"}, When: "data_model"},
+			{ID: "benchmarks", Type: "code", Output: "benchmarks", DependsOn: []string{"write_migration"}, Optional: true},
+			{ID: "implement", Type: "code", Output: "service", DependsOn: []string{"write_migration", "benchmarks"}},
+		},
+	}
+	plan := func(nodes []*db.Node, with ...string) (string, string) {
+		t.Helper()
+		p, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID, With: with}, nodes, policy, false, time.Now())
+		if err != nil {
+			t.Fatalf("PlanApply: %v", err)
+		}
+		var edges, skipped []string
+		for _, e := range p.Edges {
+			edges = append(edges, e.Source+"->"+e.Target)
+		}
+		for _, s := range p.Skipped {
+			skipped = append(skipped, s.ID)
+		}
+		return strings.Join(edges, " "), strings.Join(skipped, " ")
+	}
+
+	// Both skipped: implement waits for what write_migration waited for.
+	edges, skipped := plan(nil)
+	if edges != "design->implement" || skipped != "write_migration benchmarks" {
+		t.Fatalf("expected implement to be rewired to design, got edges %q, skipped %q", edges, skipped)
+	}
+
+	edges, skipped = plan([]*db.Node{{ID: "model", Output: "data_model", Status: db.StatusPending}}, "benchmarks")
+	want := "design->write_migration write_migration->benchmarks write_migration->implement benchmarks->implement"
+	if edges != want || skipped != "" {
+		t.Fatalf("expected every subtask, got edges %q, skipped %q", edges, skipped)
+	}
+
+	if _, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID, With: []string{"design"}}, nil, policy, false, time.Now()); err == nil || !strings.Contains(err.Error(), "not optional") {
+		t.Fatalf("expected --with of a required subtask to fail, got %v", err)
+	}
+}
@@ -0,0 +1,423 @@
+package tactics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// ChainOptions tunes PlanChain.
+type ChainOptions struct {
+	// Premises also chains tactics for premises that aren't in the project yet
+	// (by default they are left to apply, which introduces placeholders).
+	Premises bool
+}
+
+// ChainStep is one tactic of a chain.
+type ChainStep struct {
+	Instance *Instance
+	// Produces lists the outputs of this tactic the chain needs.
+	Produces []string
+	// Alternatives are the other tactics producing the same outputs.
+	Alternatives []Alternative
+}
+
+// Alternative is another tactic producing a step's output.
+type Alternative struct {
+	TacticID string
+	// Steps is the length of the chain through this tactic (0 when unreachable).
+	Steps int
+	// Missing lists the match outputs nothing can produce (set when unreachable).
+	Missing []string
+}
+
+// Chain is the sequence of tactics that produces a target output, in the order
+// they have to be applied.
+type Chain struct {
+	Target string
+	// Existing is the complete project node already producing the target, if any.
+	Existing *db.Node
+	// Open are the open project nodes producing outputs the chain needs (the target
+	// included): the chain waits for them instead of producing them again.
+	Open  []*db.Node
+	Steps []ChainStep
+
+	// library and premiseTactics type the premise placeholders Execute introduces: premises the
+	// search can reach follow the tactic it would use.
+	library        []*db.Tactic
+	premiseTactics map[string]string
+}
+
+// PlanChain chains backwards from target over the tactics' outputs and match
+// dependencies and returns the smallest set of tactics that produces it from
+// the outputs already complete in the project. Open nodes are planned work: the
+// chain waits for them rather than producing their output again. Parameterized
+// tactics take part only if their defaults cover every parameter.
+func PlanChain(target string, library []*db.Tactic, nodes []*db.Node, policy *db.StatusPolicy, opts ChainOptions) (*Chain, error) {
+	if policy == nil {
+		policy = db.DefaultStatusPolicy()
+	}
+	chain := &Chain{Target: target, library: library, premiseTactics: map[string]string{}}
+	// available holds the outputs the chain doesn't have to produce: complete ones and open ones.
+	available := map[string]*db.Node{}
+	open := map[string]*db.Node{}
+	var live []*db.Node
+	for _, n := range nodes {
+		// Cancelled and won't do nodes may satisfy their dependents, but they don't produce
+		// their output: as far as the chain is concerned they aren't there.
+		done := n.Status == db.StatusComplete
+		if !done && !policy.IsOpen(n.Status) {
+			continue
+		}
+		live = append(live, n)
+		// A complete node wins over open ones, then the smallest ID.
+		if prev, ok := available[n.Output]; ok {
+			if prevDone := prev.Status == db.StatusComplete; done != prevDone {
+				if prevDone {
+					continue
+				}
+			} else if prev.ID < n.ID {
+				continue
+			}
+		}
+		available[n.Output] = n
+		if done {
+			delete(open, n.Output)
+		} else {
+			open[n.Output] = n
+		}
+	}
+	if n, ok := available[target]; ok {
+		if open[target] != nil {
+			chain.Open = []*db.Node{n}
+		} else {
+			chain.Existing = n
+		}
+		return chain, nil
+	}
+	// Match predicates other than plain outputs (type:, globs, ...) can only be satisfied by
+	// nodes already in the project; the planner doesn't chain tactics for them.
+	for _, t := range library {
+		for _, m := range t.Match {
+			p, err := db.ParsePredicate(m)
+			if err != nil || p.Kind == db.PredicateOutput {
+				continue
+			}
+			if selected := p.Select(live); len(selected) >= p.MinCount() {
+				available[m] = selected[0]
+			}
+		}
+	}
+
+	s := newChainSearch(library, nodes, available)
+	s.run()
+	if _, ok := s.final[target]; !ok {
+		return nil, s.unreachable(target)
+	}
+
+	visiting := map[string]bool{}
+	done := map[string]int{}
+	var visit func(output string)
+	visit = func(output string) {
+		if _, ok := available[output]; ok {
+			if n, ok := open[output]; ok && !containsNode(chain.Open, n) {
+				chain.Open = append(chain.Open, n)
+			}
+			return
+		}
+		inst := s.via[output]
+		if idx, ok := done[inst.Tactic.ID]; ok {
+			chain.Steps[idx].Produces = appendUnique(chain.Steps[idx].Produces, output)
+			return
+		}
+		if visiting[inst.Tactic.ID] {
+			return
+		}
+		visiting[inst.Tactic.ID] = true
+		for _, m := range inst.Tactic.Match {
+			visit(m)
+		}
+		if opts.Premises {
+			for _, p := range inst.Tactic.Premises {
+				if _, ok := s.final[p]; ok {
+					visit(p)
+				}
+			}
+		}
+		done[inst.Tactic.ID] = len(chain.Steps)
+		chain.Steps = append(chain.Steps, ChainStep{Instance: inst, Produces: []string{output}})
+	}
+	visit(target)
+
+	for i := range chain.Steps {
+		step := &chain.Steps[i]
+		step.Alternatives = s.alternatives(step.Instance.Tactic.ID, step.Produces)
+		for _, p := range step.Instance.Tactic.Premises {
+			inst, ok := s.via[p]
+			if !ok {
+				continue
+			}
+			for _, t := range PremiseProducers(p, library, step.Instance.Tactic.ID) {
+				if t.ID == inst.Tactic.ID {
+					chain.premiseTactics[p] = t.ID
+				}
+			}
+		}
+	}
+	return chain, nil
+}
+
+// chainSearch is a Knuth-style generalization of Dijkstra over outputs: an
+// output's cost is the number of distinct tactics needed to produce it, and a
+// tactic becomes usable once all of its match outputs are settled.
+type chainSearch struct {
+	tactics   []*Instance
+	outputs   map[string][]string
+	producers map[string][]*Instance
+
+	final     map[string]map[string]bool
+	tentative map[string]map[string]bool
+	via       map[string]*Instance
+	expanded  map[string]bool
+}
+
+func newChainSearch(library []*db.Tactic, nodes []*db.Node, existing map[string]*db.Node) *chainSearch {
+	s := &chainSearch{
+		outputs:   map[string][]string{},
+		producers: map[string][]*Instance{},
+		final:     map[string]map[string]bool{},
+		tentative: map[string]map[string]bool{},
+		via:       map[string]*Instance{},
+		expanded:  map[string]bool{},
+	}
+	sorted := append([]*db.Tactic{}, library...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for _, t := range sorted {
+		inst, err := Instantiate(t, nil)
+		if err != nil {
+			// Needs parameters the planner can't guess.
+			continue
+		}
+		s.tactics = append(s.tactics, inst)
+		s.outputs[t.ID] = outputsOf(inst.Tactic, nodes)
+		for _, o := range s.outputs[t.ID] {
+			s.producers[o] = append(s.producers[o], inst)
+		}
+	}
+	for o := range existing {
+		s.tentative[o] = map[string]bool{}
+	}
+	return s
+}
+
+func (s *chainSearch) run() {
+	for {
+		s.expand()
+		next, best := "", -1
+		for o, set := range s.tentative {
+			if best < 0 || len(set) < best || (len(set) == best && o < next) {
+				next, best = o, len(set)
+			}
+		}
+		if best < 0 {
+			return
+		}
+		s.final[next] = s.tentative[next]
+		delete(s.tentative, next)
+	}
+}
+
+// expand offers the outputs of every tactic whose match outputs are all settled.
+func (s *chainSearch) expand() {
+	for _, inst := range s.tactics {
+		if s.expanded[inst.Tactic.ID] {
+			continue
+		}
+		set, ok := s.cost(inst)
+		if !ok {
+			continue
+		}
+		s.expanded[inst.Tactic.ID] = true
+		for _, o := range s.outputs[inst.Tactic.ID] {
+			if _, done := s.final[o]; done {
+				continue
+			}
+			if cur, ok := s.tentative[o]; !ok || len(set) < len(cur) {
+				s.tentative[o] = set
+				s.via[o] = inst
+			}
+		}
+	}
+}
+
+// cost returns the tactics needed to apply inst, if all its match outputs are settled.
+func (s *chainSearch) cost(inst *Instance) (map[string]bool, bool) {
+	set := map[string]bool{inst.Tactic.ID: true}
+	for _, m := range inst.Tactic.Match {
+		dep, ok := s.final[m]
+		if !ok {
+			return nil, false
+		}
+		for id := range dep {
+			set[id] = true
+		}
+	}
+	return set, true
+}
+
+func (s *chainSearch) alternatives(chosen string, outputs []string) []Alternative {
+	var ret []Alternative
+	seen := map[string]bool{chosen: true}
+	for _, o := range outputs {
+		for _, inst := range s.producers[o] {
+			if seen[inst.Tactic.ID] {
+				continue
+			}
+			seen[inst.Tactic.ID] = true
+			alt := Alternative{TacticID: inst.Tactic.ID}
+			if set, ok := s.cost(inst); ok {
+				alt.Steps = len(set)
+			} else {
+				for _, m := range inst.Tactic.Match {
+					if _, ok := s.final[m]; !ok {
+						alt.Missing = append(alt.Missing, m)
+					}
+				}
+			}
+			ret = append(ret, alt)
+		}
+	}
+	return ret
+}
+
+// unreachable explains why target can't be produced: the tactics that would
+// produce it and the outputs at the bottom of the chain that nothing produces.
+func (s *chainSearch) unreachable(target string) error {
+	if len(s.producers[target]) == 0 {
+		return errors.Errorf("cannot reach %s: no tactic produces it", target)
+	}
+	var via []string
+	for _, inst := range s.producers[target] {
+		via = append(via, inst.Tactic.ID)
+	}
+	roots := map[string]bool{}
+	visited := map[string]bool{}
+	var walk func(o string)
+	walk = func(o string) {
+		if visited[o] {
+			return
+		}
+		visited[o] = true
+		if len(s.producers[o]) == 0 {
+			roots[o] = true
+			return
+		}
+		for _, inst := range s.producers[o] {
+			for _, m := range inst.Tactic.Match {
+				if _, ok := s.final[m]; !ok {
+					walk(m)
+				}
+			}
+		}
+	}
+	walk(target)
+	if len(roots) == 0 {
+		return errors.Errorf("cannot reach %s: the tactics producing it (%s) depend on each other in a cycle", target, strings.Join(via, ", "))
+	}
+	return errors.Errorf("cannot reach %s (produced by %s): nothing in the project or the tactics library produces %s",
+		target, strings.Join(via, ", "), strings.Join(keys(roots), ", "))
+}
+
+// Execute applies the chain's tactics in order. Match dependencies produced by
+// earlier steps are still pending, so each step is applied as with --force.
+func (c *Chain) Execute(ctx context.Context, project *db.ProjectDB, policy *db.StatusPolicy, now time.Time) ([]*Plan, error) {
+	var plans []*Plan
+	for _, step := range c.Steps {
+		nodes, err := project.GetAllNodes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := PlanApply(step.Instance, nodes, policy, true, now)
+		if err != nil {
+			return nil, errors.Wrapf(err, "apply %s", step.Instance.ID)
+		}
+		// Earlier steps can change a when condition the chain was planned with.
+		for _, o := range step.Produces {
+			if !plan.creates(o) {
+				return nil, errors.Errorf("apply %s: it no longer creates %s, which the chain needs (skipped subtasks: %s)",
+					step.Instance.ID, o, plan.skippedIDs())
+			}
+		}
+		choices := map[string]string{}
+		for _, pn := range plan.Nodes {
+			if id, ok := c.premiseTactics[pn.Node.Output]; ok && pn.Role == RolePremise {
+				choices[pn.Node.Output] = id
+			}
+		}
+		if err := plan.InferPremises(c.library, choices); err != nil {
+			return nil, errors.Wrapf(err, "apply %s", step.Instance.ID)
+		}
+		if err := plan.Execute(ctx, project); err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// outputsOf lists the outputs applying t to the project creates nodes for.
+// Optional subtasks aren't created by default and conditional ones only when
+// their condition holds, so the planner doesn't count on the others.
+func outputsOf(t *db.Tactic, nodes []*db.Node) []string {
+	if len(t.Subtasks) == 0 {
+		return []string{t.Output}
+	}
+	var ret []string
+	for _, st := range t.Subtasks {
+		if st.Optional {
+			continue
+		}
+		if ok, err := EvalCondition(st.When, nodes); err != nil || !ok {
+			continue
+		}
+		ret = appendUnique(ret, st.Output)
+	}
+	return ret
+}
+
+func (p *Plan) creates(output string) bool {
+	for _, pn := range p.Nodes {
+		if pn.Node.Output == output && pn.Role != RolePremise {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) skippedIDs() string {
+	var ids []string
+	for _, s := range p.Skipped {
+		ids = append(ids, s.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+func containsNode(list []*db.Node, n *db.Node) bool {
+	for _, m := range list {
+		if m.ID == n.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, s string) []string {
+	if contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,196 @@
+package tactics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func chainLibrary() []*db.Tactic {
+	return []*db.Tactic{
+		{ID: "gather", Type: "document", Output: "requirements"},
+		{ID: "spec", Type: "document", Output: "spec", Match: []string{"requirements"}},
+		{ID: "model", Type: "document", Output: "model", Match: []string{"requirements"}},
+		// Two ways to get code: the short one needs only the spec.
+		{ID: "implement", Type: "code", Output: "code", Match: []string{"spec", "model"}},
+		{ID: "prototype", Type: "code", Output: "code", Match: []string{"spec"}, Premises: []string{"style"}},
+		{ID: "hack", Type: "code", Output: "code", Match: []string{"magic"}},
+		{ID: "style_guide", Type: "document", Output: "style"},
+		{ID: "crud", Type: "code", Output: "{{.resource}}_api", Params: []db.TacticParam{{Name: "resource", Required: true}}},
+	}
+}
+
+func stepIDs(c *Chain) string {
+	var ids []string
+	for _, s := range c.Steps {
+		ids = append(ids, s.Instance.ID)
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestPlanChain(t *testing.T) {
+	library := chainLibrary()
+
+	c, err := PlanChain("code", library, nil, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain: %v", err)
+	}
+	if got := stepIDs(c); got != "gather spec prototype" {
+		t.Fatalf("expected the shortest chain gather spec prototype, got %q", got)
+	}
+	var alts []string
+	for _, a := range c.Steps[2].Alternatives {
+		alts = append(alts, a.TacticID)
+		if a.TacticID == "implement" && a.Steps != 4 {
+			t.Fatalf("expected implement to need 4 tactics, got %d", a.Steps)
+		}
+		if a.TacticID == "hack" && strings.Join(a.Missing, ",") != "magic" {
+			t.Fatalf("expected hack to be missing magic, got %v", a.Missing)
+		}
+	}
+	if strings.Join(alts, " ") != "hack implement" {
+		t.Fatalf("expected alternatives hack implement, got %v", alts)
+	}
+
+	c, err = PlanChain("code", library, nil, nil, ChainOptions{Premises: true})
+	if err != nil {
+		t.Fatalf("PlanChain with premises: %v", err)
+	}
+	if got := stepIDs(c); got != "gather spec style_guide prototype" {
+		t.Fatalf("expected the premise producer before prototype, got %q", got)
+	}
+
+	nodes := []*db.Node{{ID: "spec_doc", Output: "spec", Status: db.StatusComplete}}
+	c, err = PlanChain("code", library, nodes, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain with existing spec: %v", err)
+	}
+	if got := stepIDs(c); got != "prototype" || len(c.Open) != 0 {
+		t.Fatalf("expected complete outputs to count as available, got %q (open %v)", got, c.Open)
+	}
+
+	c, err = PlanChain("spec", library, nodes, nil, ChainOptions{})
+	if err != nil || c.Existing == nil || c.Existing.ID != "spec_doc" || len(c.Steps) != 0 {
+		t.Fatalf("expected spec to be reported as existing, got %+v, %v", c, err)
+	}
+
+	// An open node is waited for, not produced again, and not reported as done.
+	nodes = []*db.Node{{ID: "spec_doc", Output: "spec", Status: db.StatusInProgress}}
+	c, err = PlanChain("code", library, nodes, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain with open spec: %v", err)
+	}
+	if got := stepIDs(c); got != "prototype" || len(c.Open) != 1 || c.Open[0].ID != "spec_doc" {
+		t.Fatalf("expected prototype waiting on spec_doc, got %q (open %v)", got, c.Open)
+	}
+	c, err = PlanChain("spec", library, nodes, nil, ChainOptions{})
+	if err != nil || c.Existing != nil || len(c.Open) != 1 || len(c.Steps) != 0 {
+		t.Fatalf("expected spec to be reported as open, got %+v, %v", c, err)
+	}
+
+	// Cancelled and won't do nodes don't count.
+	nodes = []*db.Node{{ID: "spec_doc", Output: "spec", Status: db.StatusCancelled}, {ID: "code_doc", Output: "code", Status: db.StatusWontDo}}
+	c, err = PlanChain("code", library, nodes, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain with cancelled nodes: %v", err)
+	}
+	if got := stepIDs(c); got != "gather spec prototype" || c.Existing != nil {
+		t.Fatalf("expected cancelled outputs to be ignored, got %q (existing %v)", got, c.Existing)
+	}
+
+	if _, err := PlanChain("magic", library, nil, nil, ChainOptions{}); err == nil || !strings.Contains(err.Error(), "no tactic produces it") {
+		t.Fatalf("expected magic to be unreachable, got %v", err)
+	}
+	library = append(library, &db.Tactic{ID: "wizard", Type: "code", Output: "wand", Match: []string{"magic"}})
+	if _, err := PlanChain("wand", library, nil, nil, ChainOptions{}); err == nil || !strings.Contains(err.Error(), "produces magic") {
+		t.Fatalf("expected the root cause magic, got %v", err)
+	}
+	if _, err := PlanChain("users_api", library, nil, nil, ChainOptions{}); err == nil {
+		t.Fatalf("expected tactics needing parameters to be skipped")
+	}
+}
+
+func TestChainExecute(t *testing.T) {
+	ctx := context.Background()
+	project := openTestProject(t)
+
+	c, err := PlanChain("code", chainLibrary(), nil, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain: %v", err)
+	}
+	if _, err := c.Execute(ctx, project, db.DefaultStatusPolicy(), time.Now()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	edges, err := project.GetEdges(ctx)
+	if err != nil {
+		t.Fatalf("GetEdges: %v", err)
+	}
+	var got []string
+	for _, e := range edges {
+		got = append(got, e.SourceNodeID+"->"+e.TargetNodeID)
+	}
+	if want := "requirements->spec spec->code"; strings.Join(got, " ") != want {
+		t.Fatalf("expected edges %q, got %q", want, strings.Join(got, " "))
+	}
+	style, err := project.GetNode(ctx, "style")
+	if err != nil || style == nil || style.IntroducedAs == nil || *style.IntroducedAs != RolePremise {
+		t.Fatalf("expected prototype's premise to be introduced as a placeholder, got %+v, %v", style, err)
+	}
+}
+
+func TestPlanChain_ConditionalSubtasks(t *testing.T) {
+	ctx := context.Background()
+	library := []*db.Tactic{
+		// migrations only produces a migration when there is a data model.
+		{ID: "migrations", Type: "code", Output: "migration", Subtasks: []db.TacticSubtask{
+			{ID: "review", Type: "review", Output: "schema_review"},
+			{ID: "write", Type: "code", Output: "migration", DependsOn: []string{"review"}, When: "data_model"},
+		}},
+		{ID: "manual", Type: "code", Output: "migration", Match: []string{"spec"}},
+		{ID: "spec", Type: "document", Output: "spec"},
+		{ID: "deploy", Type: "code", Output: "deployed", Match: []string{"migration"}},
+	}
+
+	c, err := PlanChain("deployed", library, nil, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain: %v", err)
+	}
+	if got := stepIDs(c); got != "spec manual deploy" {
+		t.Fatalf("expected the conditional subtask not to count without a data model, got %q", got)
+	}
+	project := openTestProject(t)
+	if _, err := c.Execute(ctx, project, db.DefaultStatusPolicy(), time.Now()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if n, err := project.GetNode(ctx, "migration"); err != nil || n == nil {
+		t.Fatalf("expected the chain to create migration, got %+v, %v", n, err)
+	}
+
+	nodes := []*db.Node{{ID: "data_model", Output: "data_model", Status: db.StatusComplete}}
+	c, err = PlanChain("deployed", library, nodes, nil, ChainOptions{})
+	if err != nil {
+		t.Fatalf("PlanChain with a data model: %v", err)
+	}
+	if got := stepIDs(c); got != "migrations deploy" {
+		t.Fatalf("expected the conditional subtask to count once its condition holds, got %q", got)
+	}
+}
+
+func openTestProject(t *testing.T) *db.ProjectDB {
+	t.Helper()
+	ctx := context.Background()
+	sqlDB, err := db.OpenSQLiteMemory(ctx)
+	if err != nil {
+		t.Fatalf("OpenSQLiteMemory: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	project := db.NewProjectDBFromDB(sqlDB)
+	if err := project.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+	return project
+}
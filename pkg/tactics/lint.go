@@ -0,0 +1,187 @@
+package tactics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/dag"
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+// Lint severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Lint checks.
+const (
+	CheckMissingField             = "missing_field"
+	CheckDuplicateSubtask         = "duplicate_subtask_id"
+	CheckUnknownSubtaskDependency = "unknown_subtask_dependency"
+	CheckSubtaskCycle             = "subtask_cycle"
+	CheckInvalidParam             = "invalid_param"
+	CheckUnconsumedOutput         = "unconsumed_output"
+	CheckInvalidPredicate         = "invalid_predicate"
+	CheckInvalidCondition         = "invalid_condition"
+)
+
+// LintIssue is one problem found in a tactic definition.
+type LintIssue struct {
+	Tactic   string
+	Severity string
+	Check    string
+	Message  string
+}
+
+// Lint checks one tactic's definition. all is the full tactic set, used to find
+// outputs that no other tactic consumes; pass nil to skip that check.
+func Lint(t *db.Tactic, all []*db.Tactic) []LintIssue {
+	var issues []LintIssue
+	add := func(severity, check, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Tactic: t.ID, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, f := range []struct{ name, value string }{{"id", t.ID}, {"type", t.Type}, {"output", t.Output}} {
+		if strings.TrimSpace(f.value) == "" {
+			add(SeverityError, CheckMissingField, "tactic has no %s", f.name)
+		}
+	}
+
+	subtasks := map[string]*db.TacticSubtask{}
+	for i := range t.Subtasks {
+		st := &t.Subtasks[i]
+		if st.ID == "" {
+			add(SeverityError, CheckMissingField, "subtask %d has no id", i)
+			continue
+		}
+		if st.Output == "" {
+			add(SeverityError, CheckMissingField, "subtask %s has no output", st.ID)
+		}
+		if st.Type == "" {
+			add(SeverityError, CheckMissingField, "subtask %s has no type", st.ID)
+		}
+		if _, dup := subtasks[st.ID]; dup {
+			add(SeverityError, CheckDuplicateSubtask, "subtask id %s is used twice", st.ID)
+		}
+		// Parameterized conditions are only known once the tactic is applied.
+		if !strings.Contains(st.When, "{{") {
+			if _, err := EvalCondition(st.When, nil); err != nil {
+				add(SeverityError, CheckInvalidCondition, "subtask %s: %v", st.ID, err)
+			}
+		}
+		subtasks[st.ID] = st
+	}
+	for _, st := range t.Subtasks {
+		for _, dep := range st.DependsOn {
+			if _, ok := subtasks[dep]; !ok {
+				add(SeverityError, CheckUnknownSubtaskDependency, "subtask %s depends on unknown subtask %q", st.ID, dep)
+			}
+		}
+	}
+	for _, cycle := range subtaskCycles(t.Subtasks) {
+		add(SeverityError, CheckSubtaskCycle, "subtasks depend on each other in a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	for _, dep := range append(append([]string{}, t.Match...), t.Premises...) {
+		if _, err := db.ParsePredicate(dep); err != nil {
+			add(SeverityError, CheckInvalidPredicate, "%v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, p := range t.Params {
+		if p.Name == "" {
+			add(SeverityError, CheckInvalidParam, "parameter has no name")
+			continue
+		}
+		if seen[p.Name] {
+			add(SeverityError, CheckInvalidParam, "parameter %s is declared twice", p.Name)
+		}
+		seen[p.Name] = true
+		raw := ""
+		if p.Default != nil {
+			raw = fmt.Sprint(p.Default)
+		}
+		// Converting the default (or the zero value) also rejects unknown types.
+		if _, err := convertParam(p, raw); err != nil {
+			add(SeverityError, CheckInvalidParam, "%v", err)
+		}
+	}
+
+	if all != nil {
+		var consumers []*db.Predicate
+		for _, other := range all {
+			if other.ID == t.ID {
+				continue
+			}
+			for _, dep := range append(append([]string{}, other.Match...), other.Premises...) {
+				if p, err := db.ParsePredicate(dep); err == nil {
+					consumers = append(consumers, p)
+				}
+			}
+		}
+		consumed := func(output string) bool {
+			n := &db.Node{Output: output}
+			for _, p := range consumers {
+				if p.Matches(n) {
+					return true
+				}
+			}
+			return false
+		}
+		for _, o := range terminalOutputs(t) {
+			// Parameterized outputs are only known once the tactic is applied.
+			if consumed(o) || strings.Contains(o, "{{") {
+				continue
+			}
+			add(SeverityInfo, CheckUnconsumedOutput, "output %q is not matched or required by any other tactic (fine for a final deliverable)", o)
+		}
+	}
+
+	return issues
+}
+
+// terminalOutputs are the tactic's output and the outputs of subtasks nothing
+// else in the tactic depends on: what the tactic hands to the rest of the plan.
+func terminalOutputs(t *db.Tactic) []string {
+	out := map[string]bool{}
+	if t.Output != "" {
+		out[t.Output] = true
+	}
+	dependedOn := map[string]bool{}
+	for _, st := range t.Subtasks {
+		for _, dep := range st.DependsOn {
+			dependedOn[dep] = true
+		}
+	}
+	for _, st := range t.Subtasks {
+		if !dependedOn[st.ID] && st.Output != "" {
+			out[st.Output] = true
+		}
+	}
+	return keys(out)
+}
+
+// subtaskCycles returns the dependency cycles among subtasks (first id repeated
+// at the end), as dag.FindCycles finds them. Unknown depends_on entries are ignored.
+func subtaskCycles(subtasks []db.TacticSubtask) [][]string {
+	known := map[string]bool{}
+	var ids []string
+	for _, st := range subtasks {
+		if !known[st.ID] {
+			known[st.ID] = true
+			ids = append(ids, st.ID)
+		}
+	}
+	var edges []db.Edge
+	for _, st := range subtasks {
+		for _, dep := range st.DependsOn {
+			if known[dep] {
+				edges = append(edges, db.Edge{SourceNodeID: dep, TargetNodeID: st.ID})
+			}
+		}
+	}
+	return dag.FindCycles(ids, edges)
+}
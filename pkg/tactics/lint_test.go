@@ -0,0 +1,58 @@
+package tactics
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func lintChecks(issues []LintIssue) string {
+	var ret []string
+	for _, issue := range issues {
+		ret = append(ret, issue.Severity+":"+issue.Check)
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, " ")
+}
+
+func TestLint(t *testing.T) {
+	spec := &db.Tactic{ID: "write_spec", Type: "document", Output: "spec"}
+	impl := &db.Tactic{
+		ID:     "implement",
+		Type:   "code",
+		Output: "code",
+		Match:  []string{"spec"},
+		Subtasks: []db.TacticSubtask{
+			{ID: "draft", Type: "code", Output: "draft", DependsOn: []string{"review"}},
+			{ID: "review", Type: "review", Output: "code", DependsOn: []string{"draft", "tset"}},
+			{ID: "review", Type: "review", Output: "code"},
+			{ID: "docs", Output: "docs"},
+		},
+		Params: []db.TacticParam{{Name: "count", Type: db.ParamTypeInt, Default: "many"}, {Name: "x", Type: "float"}},
+	}
+	all := []*db.Tactic{spec, impl}
+
+	if got := lintChecks(Lint(spec, all)); got != "" {
+		t.Fatalf("expected write_spec to be clean (its output is matched), got %q", got)
+	}
+
+	want := "error:duplicate_subtask_id error:invalid_param error:invalid_param error:missing_field error:subtask_cycle error:unknown_subtask_dependency info:unconsumed_output info:unconsumed_output"
+	if got := lintChecks(Lint(impl, all)); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	var cycle string
+	for _, issue := range Lint(impl, nil) {
+		if issue.Check == CheckSubtaskCycle {
+			cycle = issue.Message
+		}
+		if issue.Check == CheckUnconsumedOutput {
+			t.Fatalf("unconsumed_output needs the tactic set, got %q", issue.Message)
+		}
+	}
+	if !strings.HasSuffix(cycle, "draft -> review -> draft") {
+		t.Fatalf("expected the cycle draft -> review -> draft, got %q", cycle)
+	}
+}
@@ -0,0 +1,241 @@
+package tactics
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Instance is a tactic with its parameters bound and every placeholder rendered.
+type Instance struct {
+	// Tactic is a rendered copy; the original is left untouched.
+	Tactic *db.Tactic
+	// ID identifies the instantiation, e.g. "implement_crud_endpoints[resource=users]".
+	// It equals the tactic ID for tactics without parameters.
+	ID string
+	// Params holds the bound parameters (defaults included) in string form.
+	Params map[string]string
+	// With lists the optional subtasks to create.
+	With []string
+}
+
+// ParseParamArgs turns `--param name=value` flags into a map.
+func ParseParamArgs(args []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, a := range args {
+		name, value, ok := strings.Cut(a, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, errors.Errorf("invalid --param %q (want name=value)", a)
+		}
+		if _, dup := out[name]; dup {
+			return nil, errors.Errorf("--param %s given twice", name)
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// Instantiate binds args to the tactic's declared parameters and renders the
+// `{{.name}}` placeholders in output, match, premises, subtasks and data.
+func Instantiate(t *db.Tactic, args map[string]string) (*Instance, error) {
+	values, params, err := bindParams(t, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Params) == 0 {
+		return &Instance{Tactic: t, ID: t.ID}, nil
+	}
+
+	r := &renderer{tacticID: t.ID, values: values}
+	out := *t
+	out.Output = r.string("output", t.Output)
+	out.Match = r.strings("match", t.Match)
+	out.Premises = r.strings("premises", t.Premises)
+	out.Data = r.data("data", t.Data)
+	out.Subtasks = make([]db.TacticSubtask, len(t.Subtasks))
+	for i, st := range t.Subtasks {
+		where := fmt.Sprintf("subtasks[%d]", i)
+		out.Subtasks[i] = db.TacticSubtask{
+			ID:        r.string(where+".id", st.ID),
+			Output:    r.string(where+".output", st.Output),
+			Type:      st.Type,
+			DependsOn: r.strings(where+".depends_on", st.DependsOn),
+			Data:      r.data(where+".data", st.Data),
+			When:      r.string(where+".when", st.When),
+			Optional:  st.Optional,
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &Instance{Tactic: &out, ID: InstanceID(t.ID, params), Params: params}, nil
+}
+
+// InstanceID formats "tactic[a=1,b=2]" with parameters sorted by name.
+func InstanceID(tacticID string, params map[string]string) string {
+	if len(params) == 0 {
+		return tacticID
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+params[name])
+	}
+	return tacticID + "[" + strings.Join(parts, ",") + "]"
+}
+
+// bindParams checks args against the declarations and returns typed values for
+// the templates alongside their string form.
+func bindParams(t *db.Tactic, args map[string]string) (map[string]interface{}, map[string]string, error) {
+	declared := map[string]bool{}
+	for _, p := range t.Params {
+		declared[p.Name] = true
+	}
+	var unknown []string
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		if len(t.Params) == 0 {
+			return nil, nil, errors.Errorf("tactic %s takes no parameters (got %s)", t.ID, strings.Join(unknown, ", "))
+		}
+		return nil, nil, errors.Errorf("tactic %s has no parameter %s", t.ID, strings.Join(unknown, ", "))
+	}
+
+	values := map[string]interface{}{}
+	params := map[string]string{}
+	for _, p := range t.Params {
+		raw, ok := args[p.Name]
+		if !ok {
+			if p.Required {
+				return nil, nil, errors.Errorf("tactic %s requires --param %s=<%s>", t.ID, p.Name, paramType(p))
+			}
+			if p.Default == nil {
+				// Optional without default: render as the zero value.
+				raw = ""
+			} else {
+				raw = fmt.Sprint(p.Default)
+			}
+		}
+		v, err := convertParam(p, raw)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "tactic %s", t.ID)
+		}
+		values[p.Name] = v
+		if ok || p.Default != nil {
+			params[p.Name] = raw
+		}
+	}
+	return values, params, nil
+}
+
+func paramType(p db.TacticParam) string {
+	if p.Type == "" {
+		return db.ParamTypeString
+	}
+	return p.Type
+}
+
+func convertParam(p db.TacticParam, raw string) (interface{}, error) {
+	switch paramType(p) {
+	case db.ParamTypeString:
+		return raw, nil
+	case db.ParamTypeInt:
+		if raw == "" {
+			return 0, nil
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.Errorf("parameter %s must be an int, got %q", p.Name, raw)
+		}
+		return v, nil
+	case db.ParamTypeBool:
+		if raw == "" {
+			return false, nil
+		}
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.Errorf("parameter %s must be a bool, got %q", p.Name, raw)
+		}
+		return v, nil
+	}
+	return nil, errors.Errorf("parameter %s has unknown type %q (want string, int or bool)", p.Name, p.Type)
+}
+
+// renderer executes templates and keeps the first error so callers can render
+// a whole tactic and check once.
+type renderer struct {
+	tacticID string
+	values   map[string]interface{}
+	err      error
+}
+
+func (r *renderer) string(where, s string) string {
+	if r.err != nil || !strings.Contains(s, "{{") {
+		return s
+	}
+	tmpl, err := template.New(where).Option("missingkey=error").Parse(s)
+	if err != nil {
+		r.err = errors.Wrapf(err, "tactic %s: parse %s", r.tacticID, where)
+		return s
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.values); err != nil {
+		r.err = errors.Wrapf(err, "tactic %s: render %s", r.tacticID, where)
+		return s
+	}
+	return buf.String()
+}
+
+func (r *renderer) strings(where string, in []string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = r.string(fmt.Sprintf("%s[%d]", where, i), s)
+	}
+	return out
+}
+
+func (r *renderer) data(where string, in map[string]interface{}) map[string]interface{} {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = r.value(where+"."+k, v)
+	}
+	return out
+}
+
+func (r *renderer) value(where string, v interface{}) interface{} {
+	switch vv := v.(type) {
+	case string:
+		return r.string(where, vv)
+	case map[string]interface{}:
+		return r.data(where, vv)
+	case []interface{}:
+		out := make([]interface{}, len(vv))
+		for i, x := range vv {
+			out[i] = r.value(fmt.Sprintf("%s[%d]", where, i), x)
+		}
+		return out
+	}
+	return v
+}
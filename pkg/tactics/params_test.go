@@ -0,0 +1,83 @@
+package tactics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func crudTactic() *db.Tactic {
+	return &db.Tactic{
+		ID:     "crud",
+		Type:   "feature",
+		Output: "{{.resource}}_endpoints",
+		Match:  []string{"{{.resource}}_spec"},
+		Params: []db.TacticParam{
+			{Name: "resource", Required: true},
+			{Name: "count", Type: db.ParamTypeInt, Default: 3},
+			{Name: "note"},
+		},
+		Subtasks: []db.TacticSubtask{
+			{ID: "{{.resource}}_handlers", Output: "{{.resource}}_handlers.go", Type: "code",
+				Data: map[string]interface{}{"prompt": "Write {{.count}} handlers", "steps": []interface{}{"{{.resource}}"}}},
+			{ID: "{{.resource}}_tests", Output: "{{.resource}}_tests.go", Type: "code",
+				DependsOn: []string{"{{.resource}}_handlers"}},
+		},
+	}
+}
+
+func TestInstantiate_RendersPlaceholders(t *testing.T) {
+	tmpl := crudTactic()
+	inst, err := Instantiate(tmpl, map[string]string{"resource": "users"})
+	if err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+
+	if inst.ID != "crud[count=3,resource=users]" {
+		t.Fatalf("unexpected instance id %q", inst.ID)
+	}
+	if want := map[string]string{"resource": "users", "count": "3"}; !reflect.DeepEqual(inst.Params, want) {
+		t.Fatalf("expected params %v, got %v", want, inst.Params)
+	}
+	got := inst.Tactic
+	if got.Output != "users_endpoints" || got.Match[0] != "users_spec" {
+		t.Fatalf("output/match not rendered: %+v", got)
+	}
+	if got.Subtasks[0].ID != "users_handlers" || got.Subtasks[1].DependsOn[0] != "users_handlers" {
+		t.Fatalf("subtasks not rendered: %+v", got.Subtasks)
+	}
+	if got.Subtasks[0].Data["prompt"] != "Write 3 handlers" || got.Subtasks[0].Data["steps"].([]interface{})[0] != "users" {
+		t.Fatalf("data not rendered: %+v", got.Subtasks[0].Data)
+	}
+	if tmpl.Subtasks[0].ID != "{{.resource}}_handlers" {
+		t.Fatalf("the template tactic must not be modified")
+	}
+}
+
+func TestInstantiate_ValidatesParams(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args map[string]string
+		want string
+	}{
+		{"missing required", map[string]string{}, "requires --param resource"},
+		{"unknown", map[string]string{"resource": "a", "bogus": "1"}, "has no parameter bogus"},
+		{"bad int", map[string]string{"resource": "a", "count": "many"}, "must be an int"},
+	} {
+		_, err := Instantiate(crudTactic(), tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	plain := &db.Tactic{ID: "plain", Output: "out"}
+	if _, err := Instantiate(plain, map[string]string{"x": "1"}); err == nil || !strings.Contains(err.Error(), "takes no parameters") {
+		t.Fatalf("expected parameterless tactic to reject --param, got %v", err)
+	}
+	inst, err := Instantiate(plain, nil)
+	if err != nil || inst.ID != "plain" || inst.Tactic != plain {
+		t.Fatalf("expected parameterless tactic to pass through, got %+v, %v", inst, err)
+	}
+}
@@ -0,0 +1,135 @@
+package tactics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// AmbiguousPremiseError is returned by InferPremises when several tactics produce a premise and
+// none was chosen.
+type AmbiguousPremiseError struct {
+	Output  string
+	Tactics []string
+}
+
+func (e *AmbiguousPremiseError) Error() string {
+	return fmt.Sprintf("premise %s is produced by several tactics (%s); choose one with --premise-tactic %s=<tactic>",
+		e.Output, strings.Join(e.Tactics, ", "), e.Output)
+}
+
+// ParsePremiseTacticArgs turns `--premise-tactic output=tactic` flags into a map.
+func ParsePremiseTacticArgs(args []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, a := range args {
+		output, tactic, ok := strings.Cut(a, "=")
+		output, tactic = strings.TrimSpace(output), strings.TrimSpace(tactic)
+		if !ok || output == "" || tactic == "" {
+			return nil, errors.Errorf("invalid --premise-tactic %q (want output=tactic_id)", a)
+		}
+		if _, dup := out[output]; dup {
+			return nil, errors.Errorf("--premise-tactic %s given twice", output)
+		}
+		out[output] = tactic
+	}
+	return out, nil
+}
+
+// PremiseProducers returns the tactics of library, other than exclude, that create a node for
+// output, sorted by ID. Parameterized outputs are skipped: they are only known once applied.
+func PremiseProducers(output string, library []*db.Tactic, exclude string) []*db.Tactic {
+	var ret []*db.Tactic
+	for _, t := range library {
+		if t.ID == exclude {
+			continue
+		}
+		if _, ok := producedType(t, output); ok {
+			ret = append(ret, t)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+// producedType is the type of the node t creates for output.
+func producedType(t *db.Tactic, output string) (string, bool) {
+	if len(t.Subtasks) == 0 {
+		return t.Type, t.Output == output && !strings.Contains(output, "{{")
+	}
+	for _, st := range t.Subtasks {
+		if st.Output == output && !strings.Contains(output, "{{") {
+			return st.Type, true
+		}
+	}
+	return "", false
+}
+
+// InferPremises gives the premise placeholders of the plan the type of the node the tactic
+// producing their output would create, that tactic's description and tags (as labels), and links
+// them back to it. choices maps outputs to the producing tactic to use when there are several.
+func (p *Plan) InferPremises(library []*db.Tactic, choices map[string]string) error {
+	premises := map[string]bool{}
+	for _, pn := range p.Nodes {
+		if pn.Role == RolePremise {
+			premises[pn.Node.Output] = true
+		}
+	}
+	for output := range choices {
+		if !premises[output] {
+			return errors.Errorf("--premise-tactic %s: %s is not a premise %s introduces", output, output, p.Instance.ID)
+		}
+	}
+
+	for _, pn := range p.Nodes {
+		if pn.Role != RolePremise {
+			continue
+		}
+		output := pn.Node.Output
+		candidates := PremiseProducers(output, library, p.Instance.Tactic.ID)
+
+		var producer *db.Tactic
+		if choice, ok := choices[output]; ok {
+			for _, t := range candidates {
+				if t.ID == choice {
+					producer = t
+				}
+			}
+			if producer == nil {
+				return errors.Errorf("--premise-tactic %s=%s: tactic %s does not produce %s", output, choice, choice, output)
+			}
+		} else {
+			switch len(candidates) {
+			case 0:
+				continue
+			case 1:
+				producer = candidates[0]
+			default:
+				var ids []string
+				for _, t := range candidates {
+					ids = append(ids, t.ID)
+				}
+				return &AmbiguousPremiseError{Output: output, Tactics: ids}
+			}
+		}
+
+		n := pn.Node
+		n.Type, _ = producedType(producer, output)
+		if len(producer.Tags) > 0 {
+			n.Labels = db.NormalizeLabels(producer.Tags)
+		}
+		if producer.Description != "" {
+			b, err := json.Marshal(map[string]string{"description": producer.Description})
+			if err != nil {
+				return errors.Wrap(err, "marshal premise data")
+			}
+			n.Data = b
+		}
+		id := producer.ID
+		n.ProducerTactic = &id
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package tactics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+func TestPlan_InferPremises(t *testing.T) {
+	policy := db.DefaultStatusPolicy()
+	tactic := &db.Tactic{ID: "implement", Type: "code", Output: "api_code", Premises: []string{"data_model", "style_guide", "api_examples"}}
+	library := []*db.Tactic{
+		tactic,
+		{ID: "design_data_model", Type: "team_activity", Output: "data_model", Description: "Design the data model", Tags: []string{"design", "backend"}},
+		{ID: "write_guide", Type: "document", Output: "guide", Subtasks: []db.TacticSubtask{{ID: "draft", Type: "draft", Output: "style_guide"}}},
+		{ID: "copy_guide", Type: "review", Output: "style_guide"},
+	}
+	plan := func(choices map[string]string) (*Plan, error) {
+		p, err := PlanApply(&Instance{Tactic: tactic, ID: tactic.ID}, nil, policy, false, time.Now())
+		if err != nil {
+			t.Fatalf("PlanApply: %v", err)
+		}
+		return p, p.InferPremises(library, choices)
+	}
+
+	_, err := plan(nil)
+	var ambiguous *AmbiguousPremiseError
+	if !errors.As(err, &ambiguous) || ambiguous.Output != "style_guide" || strings.Join(ambiguous.Tactics, ",") != "copy_guide,write_guide" {
+		t.Fatalf("expected style_guide to be ambiguous, got %v", err)
+	}
+
+	p, err := plan(map[string]string{"style_guide": "write_guide"})
+	if err != nil {
+		t.Fatalf("InferPremises: %v", err)
+	}
+	var got []string
+	for _, pn := range p.Nodes {
+		if pn.Role != RolePremise {
+			continue
+		}
+		got = append(got, pn.Node.ID+":"+pn.Node.Type+":"+db.StringValue(pn.Node.ProducerTactic)+":"+strings.Join(pn.Node.Labels, ","))
+	}
+	want := "api_examples:document::|data_model:team_activity:design_data_model:backend,design|style_guide:draft:write_guide:"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("expected premises %q, got %q", want, strings.Join(got, "|"))
+	}
+
+	if _, err := plan(map[string]string{"style_guide": "design_data_model"}); err == nil || !strings.Contains(err.Error(), "does not produce") {
+		t.Fatalf("expected a choice that doesn't produce the premise to fail, got %v", err)
+	}
+}
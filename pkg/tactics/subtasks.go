@@ -0,0 +1,115 @@
+package tactics
+
+import (
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// SkippedSubtask is a subtask PlanApply leaves out.
+type SkippedSubtask struct {
+	ID string
+	// Reason says why, e.g. "optional" or "when data_model is false".
+	Reason string
+}
+
+// EvalCondition evaluates a subtask `when` condition against the project: true/false (as
+// rendered from parameters), a match predicate that holds when enough nodes are selected
+// (whatever their status), or either negated with a leading `!`. An empty condition holds.
+func EvalCondition(cond string, nodes []*db.Node) (bool, error) {
+	cond = strings.TrimSpace(cond)
+	if strings.HasPrefix(cond, "!") {
+		ok, err := EvalCondition(strings.TrimPrefix(cond, "!"), nodes)
+		return !ok, err
+	}
+	switch strings.ToLower(cond) {
+	case "", "true", "yes":
+		return true, nil
+	case "false", "no":
+		return false, nil
+	}
+	p, err := db.ParsePredicate(cond)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid when condition")
+	}
+	return len(p.Select(nodes)) >= p.MinCount(), nil
+}
+
+// selectSubtasks returns the subtasks applying inst creates: optional ones only when listed in
+// inst.With, and only those whose when condition holds. Dependents of a skipped subtask depend on
+// its own dependencies instead.
+func selectSubtasks(inst *Instance, nodes []*db.Node) ([]db.TacticSubtask, []SkippedSubtask, error) {
+	tactic := inst.Tactic
+	byID := map[string]db.TacticSubtask{}
+	for _, st := range tactic.Subtasks {
+		byID[st.ID] = st
+	}
+	with := map[string]bool{}
+	for _, id := range inst.With {
+		st, ok := byID[id]
+		switch {
+		case !ok:
+			return nil, nil, errors.Errorf("tactic %s has no subtask %s", tactic.ID, id)
+		case !st.Optional:
+			return nil, nil, errors.Errorf("subtask %s of tactic %s is not optional", id, tactic.ID)
+		}
+		with[id] = true
+	}
+
+	skipped := map[string]bool{}
+	var skips []SkippedSubtask
+	for _, st := range tactic.Subtasks {
+		if st.Optional && !with[st.ID] {
+			skipped[st.ID] = true
+			skips = append(skips, SkippedSubtask{ID: st.ID, Reason: "optional"})
+			continue
+		}
+		ok, err := EvalCondition(st.When, nodes)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "subtask %s of tactic %s", st.ID, tactic.ID)
+		}
+		if !ok {
+			skipped[st.ID] = true
+			skips = append(skips, SkippedSubtask{ID: st.ID, Reason: "when " + strings.TrimSpace(st.When) + " is false"})
+		}
+	}
+	if len(skipped) == 0 {
+		return tactic.Subtasks, nil, nil
+	}
+
+	// Dependencies of a skipped subtask stand in for it, transitively.
+	var resolve func(id string, seen map[string]bool) []string
+	resolve = func(id string, seen map[string]bool) []string {
+		if !skipped[id] {
+			return []string{id}
+		}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		var ret []string
+		for _, dep := range byID[id].DependsOn {
+			for _, d := range resolve(dep, seen) {
+				ret = appendUnique(ret, d)
+			}
+		}
+		return ret
+	}
+
+	var kept []db.TacticSubtask
+	for _, st := range tactic.Subtasks {
+		if skipped[st.ID] {
+			continue
+		}
+		var deps []string
+		for _, dep := range st.DependsOn {
+			for _, d := range resolve(dep, map[string]bool{}) {
+				deps = appendUnique(deps, d)
+			}
+		}
+		st.DependsOn = deps
+		kept = append(kept, st)
+	}
+	return kept, skips, nil
+}
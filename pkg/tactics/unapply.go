@@ -0,0 +1,105 @@
+package tactics
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Removal is everything one tactic application created that unapply would remove.
+type Removal struct {
+	// Instance is the instance ID (the tactic ID for parameterless tactics).
+	Instance string
+	TacticID string
+	Nodes    []*db.Node
+	// Edges touching the removed nodes, including ones added after the application.
+	Edges []db.Edge
+	// Problems explain why the removal is unsafe without --force.
+	Problems []string
+}
+
+// PlanUnapply finds the nodes created by applying ref, which is either an
+// instance ID ("crud[resource=users]") or a tactic ID that was applied once.
+func PlanUnapply(ref string, nodes []*db.Node, edges []db.Edge) (*Removal, error) {
+	groups := map[string][]*db.Node{}
+	tacticOf := map[string]string{}
+	for _, n := range nodes {
+		if n.CreatedBy == nil || !strings.HasPrefix(*n.CreatedBy, "tactic:") {
+			continue
+		}
+		tid := strings.TrimPrefix(*n.CreatedBy, "tactic:")
+		instance := tid
+		if n.TacticInstance != nil {
+			instance = *n.TacticInstance
+		}
+		groups[instance] = append(groups[instance], n)
+		tacticOf[instance] = tid
+	}
+
+	instance := ref
+	if _, ok := groups[ref]; !ok {
+		var candidates []string
+		for inst, tid := range tacticOf {
+			if tid == ref {
+				candidates = append(candidates, inst)
+			}
+		}
+		sort.Strings(candidates)
+		switch len(candidates) {
+		case 0:
+			return nil, errors.Errorf("no nodes were created by applying %s", ref)
+		case 1:
+			instance = candidates[0]
+		default:
+			return nil, errors.Errorf("tactic %s was applied %d times; unapply one instance: %s", ref, len(candidates), strings.Join(candidates, ", "))
+		}
+	}
+
+	r := &Removal{Instance: instance, TacticID: tacticOf[instance], Nodes: groups[instance]}
+	sort.Slice(r.Nodes, func(i, j int) bool { return r.Nodes[i].ID < r.Nodes[j].ID })
+
+	removed := map[string]bool{}
+	for _, n := range r.Nodes {
+		removed[n.ID] = true
+		// Anything but pending means someone acted on the node.
+		if n.Status != db.StatusPending {
+			r.Problems = append(r.Problems, "node "+n.ID+" is "+n.Status)
+		}
+	}
+	for _, e := range edges {
+		if !removed[e.SourceNodeID] && !removed[e.TargetNodeID] {
+			continue
+		}
+		r.Edges = append(r.Edges, e)
+		if removed[e.SourceNodeID] && !removed[e.TargetNodeID] {
+			r.Problems = append(r.Problems, "node "+e.TargetNodeID+" now depends on "+e.SourceNodeID)
+		}
+	}
+	return r, nil
+}
+
+// Execute deletes the nodes (their edges go with them) and logs tactic_unapplied.
+func (r *Removal) Execute(ctx context.Context, project *db.ProjectDB) error {
+	for _, n := range r.Nodes {
+		if err := project.DeleteNode(ctx, n.ID); err != nil {
+			return err
+		}
+	}
+
+	var nodeIDs, edges []string
+	for _, n := range r.Nodes {
+		nodeIDs = append(nodeIDs, n.ID)
+	}
+	for _, e := range r.Edges {
+		edges = append(edges, e.SourceNodeID+"->"+e.TargetNodeID)
+	}
+	details := "Unapplied tactic: " + r.Instance + "; removed nodes: " + strings.Join(nodeIDs, ", ")
+	if len(edges) > 0 {
+		details += "; removed edges: " + strings.Join(edges, ", ")
+	}
+	tid := r.TacticID
+	return project.LogAction(ctx, "tactic_unapplied", &details, nil, &tid)
+}
@@ -0,0 +1,72 @@
+package tactics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/tactician/pkg/db"
+)
+
+func TestPlanUnapply(t *testing.T) {
+	byTactic := func(id string) *string { s := "tactic:" + id; return &s }
+	instance := func(id string) *string { return &id }
+	nodes := []*db.Node{
+		{ID: "spec", Status: db.StatusComplete},
+		{ID: "design", Status: db.StatusPending, CreatedBy: byTactic("design")},
+		{ID: "users_api", Status: db.StatusPending, CreatedBy: byTactic("crud"), TacticInstance: instance("crud[resource=users]")},
+		{ID: "users_tests", Status: db.StatusComplete, CreatedBy: byTactic("crud"), TacticInstance: instance("crud[resource=users]")},
+		{ID: "posts_api", Status: db.StatusPending, CreatedBy: byTactic("crud"), TacticInstance: instance("crud[resource=posts]")},
+		{ID: "posts_tests", Status: db.StatusInProgress, CreatedBy: byTactic("crud"), TacticInstance: instance("crud[resource=posts]")},
+		{ID: "tags_api", Status: db.StatusWontDo, CreatedBy: byTactic("crud"), TacticInstance: instance("crud[resource=tags]")},
+		{ID: "release", Status: db.StatusPending},
+	}
+	edges := []db.Edge{
+		{SourceNodeID: "spec", TargetNodeID: "design"},
+		{SourceNodeID: "users_api", TargetNodeID: "users_tests"},
+		{SourceNodeID: "users_tests", TargetNodeID: "release"},
+	}
+
+	r, err := PlanUnapply("design", nodes, edges)
+	if err != nil {
+		t.Fatalf("PlanUnapply(design): %v", err)
+	}
+	if r.Instance != "design" || r.TacticID != "design" || len(r.Nodes) != 1 || len(r.Edges) != 1 || len(r.Problems) != 0 {
+		t.Fatalf("unexpected removal for design: %+v", r)
+	}
+
+	if _, err := PlanUnapply("crud", nodes, edges); err == nil || !strings.Contains(err.Error(), "crud[resource=posts], crud[resource=tags], crud[resource=users]") {
+		t.Fatalf("expected an ambiguity error listing instances, got %v", err)
+	}
+
+	r, err = PlanUnapply("crud[resource=users]", nodes, edges)
+	if err != nil {
+		t.Fatalf("PlanUnapply(crud[resource=users]): %v", err)
+	}
+	if r.TacticID != "crud" || len(r.Nodes) != 2 || len(r.Edges) != 2 {
+		t.Fatalf("unexpected removal for users: %+v", r)
+	}
+	want := "node users_tests is complete; node release now depends on users_tests"
+	if got := strings.Join(r.Problems, "; "); got != want {
+		t.Fatalf("expected problems %q, got %q", want, got)
+	}
+
+	// Nodes someone started (or closed some other way) are flagged too.
+	r, err = PlanUnapply("crud[resource=posts]", nodes, edges)
+	if err != nil {
+		t.Fatalf("PlanUnapply(crud[resource=posts]): %v", err)
+	}
+	if got := strings.Join(r.Problems, "; "); got != "node posts_tests is in_progress" {
+		t.Fatalf("expected the in_progress node to be flagged, got %q", got)
+	}
+	r, err = PlanUnapply("crud[resource=tags]", nodes, edges)
+	if err != nil {
+		t.Fatalf("PlanUnapply(crud[resource=tags]): %v", err)
+	}
+	if got := strings.Join(r.Problems, "; "); got != "node tags_api is wont_do" {
+		t.Fatalf("expected the wont_do node to be flagged, got %q", got)
+	}
+
+	if _, err := PlanUnapply("missing", nodes, edges); err == nil {
+		t.Fatalf("expected an error for a tactic that was never applied")
+	}
+}